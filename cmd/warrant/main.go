@@ -18,16 +18,24 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
 	"time"
 
 	"github.com/pkg/errors"
 	"github.com/rs/zerolog/log"
+	"github.com/warrant-dev/warrant/pkg/apikey"
+	"github.com/warrant-dev/warrant/pkg/auditlog"
 	check "github.com/warrant-dev/warrant/pkg/authz/check"
 	objecttype "github.com/warrant-dev/warrant/pkg/authz/objecttype"
 	query "github.com/warrant-dev/warrant/pkg/authz/query"
 	warrant "github.com/warrant-dev/warrant/pkg/authz/warrant"
+	"github.com/warrant-dev/warrant/pkg/changelog"
 	"github.com/warrant-dev/warrant/pkg/config"
 	"github.com/warrant-dev/warrant/pkg/database"
+	"github.com/warrant-dev/warrant/pkg/decisionlog"
+	"github.com/warrant-dev/warrant/pkg/health"
 	object "github.com/warrant-dev/warrant/pkg/object"
 	feature "github.com/warrant-dev/warrant/pkg/object/feature"
 	permission "github.com/warrant-dev/warrant/pkg/object/permission"
@@ -36,12 +44,15 @@ import (
 	tenant "github.com/warrant-dev/warrant/pkg/object/tenant"
 	user "github.com/warrant-dev/warrant/pkg/object/user"
 	"github.com/warrant-dev/warrant/pkg/service"
+	"github.com/warrant-dev/warrant/pkg/session"
+	"github.com/warrant-dev/warrant/pkg/tracing"
+	"github.com/warrant-dev/warrant/pkg/webhook"
 )
 
 const (
-	MySQLDatastoreMigrationVersion    = 000006
-	PostgresDatastoreMigrationVersion = 000007
-	SQLiteDatastoreMigrationVersion   = 000006
+	MySQLDatastoreMigrationVersion    = 13
+	PostgresDatastoreMigrationVersion = 14
+	SQLiteDatastoreMigrationVersion   = 13
 )
 
 type ServiceEnv struct {
@@ -121,12 +132,53 @@ func NewServiceEnv() *ServiceEnv {
 
 func main() {
 	cfg := config.NewConfig()
+	shutdownCtx, stopListeningForShutdown := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
 	svcEnv := NewServiceEnv()
-	err := svcEnv.InitDB(cfg)
+
+	// Init health service and serve health checks while starting up. The
+	// server isn't ready until all other routes are set up below.
+	healthSvc := health.NewService(svcEnv, map[string]uint{
+		database.TypeMySQL:    MySQLDatastoreMigrationVersion,
+		database.TypePostgres: PostgresDatastoreMigrationVersion,
+		database.TypeSQLite:   SQLiteDatastoreMigrationVersion,
+	})
+	healthRoutes, err := healthSvc.Routes()
+	if err != nil {
+		log.Fatal().Err(err).Msg("init: could not setup routes for service")
+	}
+	startupRouter, err := service.NewRouter(cfg, "", healthRoutes, service.ApiKeyAuthMiddleware, nil, nil)
+	if err != nil {
+		log.Fatal().Err(err).Msg("init: could not initialize service router")
+	}
+	handler := newSwappableHandler(startupRouter)
+	server := &http.Server{
+		Addr:              fmt.Sprintf(":%d", cfg.GetPort()),
+		Handler:           handler,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	go func() {
+		log.Info().Msgf("init: listening on port %d", cfg.GetPort())
+		err := server.ListenAndServe()
+		if !errors.Is(err, http.ErrServerClosed) {
+			log.Fatal().Err(err).Msg("shutdown")
+		}
+	}()
+
+	err = svcEnv.InitDB(cfg)
 	if err != nil {
 		log.Fatal().Err(err).Msg("init: could not initialize and connect to the configured datastore. Shutting down.")
 	}
 
+	// Init tracing
+	stopTracing := func(ctx context.Context) error { return nil }
+	if cfg.GetTracing() != nil && cfg.GetTracing().Enabled {
+		stopTracing, err = tracing.Start(context.Background(), cfg.GetTracing())
+		if err != nil {
+			log.Fatal().Err(err).Msg("init: could not initialize tracing")
+		}
+		log.Info().Msgf("init: exporting traces to %s", cfg.GetTracing().Endpoint)
+	}
+
 	// Init object type repo and service
 	objectTypeRepository, err := objecttype.NewRepository(svcEnv.DB())
 	if err != nil {
@@ -146,14 +198,75 @@ func main() {
 	if err != nil {
 		log.Fatal().Err(err).Msg("init: could not initialize WarrantRepository")
 	}
-	warrantSvc := warrant.NewService(svcEnv, warrantRepository, objectTypeSvc, objectSvc)
+	warrantSvc := warrant.NewService(svcEnv, warrantRepository, objectTypeSvc, objectSvc, cfg.GetWarrantBatch())
+
+	// Init changelog repo and service
+	changeRepository, err := changelog.NewRepository(svcEnv.DB())
+	if err != nil {
+		log.Fatal().Err(err).Msg("init: could not initialize ChangeRepository")
+	}
+	changeSvc := changelog.NewService(svcEnv, changeRepository)
+	objectTypeSvc.AddChangeRecorder(changeSvc)
+	objectSvc.AddChangeRecorder(changeSvc)
+	warrantSvc.AddChangeRecorder(changeSvc)
+	objectTypeSvc.AddWriteListener(changeSvc.OnWrite)
+	objectSvc.AddWriteListener(changeSvc.OnWrite)
+	warrantSvc.AddWriteListener(changeSvc.OnWrite)
+
+	// Init audit log repo and service
+	auditLogRepository, err := auditlog.NewRepository(svcEnv.DB())
+	if err != nil {
+		log.Fatal().Err(err).Msg("init: could not initialize AuditLogRepository")
+	}
+	auditLogSvc := auditlog.NewService(svcEnv, auditLogRepository)
+	objectTypeSvc.AddChangeRecorder(auditLogSvc)
+	objectSvc.AddChangeRecorder(auditLogSvc)
+	warrantSvc.AddChangeRecorder(auditLogSvc)
+
+	// Init api key repo and service
+	apiKeyRepository, err := apikey.NewRepository(svcEnv.DB())
+	if err != nil {
+		log.Fatal().Err(err).Msg("init: could not initialize ApiKeyRepository")
+	}
+	apiKeySvc := apikey.NewService(svcEnv, apiKeyRepository)
+	apiKeySvc.AddChangeRecorder(auditLogSvc)
+
+	// Init webhook repo and service
+	webhookRepository, err := webhook.NewRepository(svcEnv.DB())
+	if err != nil {
+		log.Fatal().Err(err).Msg("init: could not initialize WebhookRepository")
+	}
+	webhookSvc := webhook.NewService(svcEnv, webhookRepository, cfg.GetWebhooks())
+	webhookSvc.AddChangeRecorder(auditLogSvc)
+	if cfg.GetWebhooks() != nil && cfg.GetWebhooks().Enabled {
+		changeSvc.AddChangeListener(webhookSvc.Enqueue)
+		go runWebhookDispatcher(shutdownCtx, webhookSvc, cfg.GetWebhooks())
+	}
 
 	// Init check service
 	checkSvc := check.NewService(svcEnv, warrantSvc, objectTypeSvc, cfg.Check, nil)
+	objectTypeSvc.AddWriteListener(checkSvc.InvalidateCache)
+	objectSvc.AddWriteListener(checkSvc.InvalidateCache)
+	warrantSvc.AddWriteListener(checkSvc.InvalidateCache)
+
+	if cfg.GetSweeper() != nil && cfg.GetSweeper().Enabled {
+		go runExpiredWarrantSweeper(shutdownCtx, warrantSvc, cfg.GetSweeper())
+	}
 
 	// Init query service
 	querySvc := query.NewService(svcEnv, objectTypeSvc, warrantSvc, objectSvc)
 
+	// Init decision logger
+	if cfg.GetDecisionLog() != nil && cfg.GetDecisionLog().Enabled {
+		decisionLogSink, err := decisionlog.NewSink(cfg.GetDecisionLog(), svcEnv.DB())
+		if err != nil {
+			log.Fatal().Err(err).Msg("init: could not initialize decision log sink")
+		}
+		decisionLogger := decisionlog.NewDecisionLogger(cfg.GetDecisionLog(), decisionLogSink)
+		checkSvc.SetDecisionLogger(decisionLogger)
+		querySvc.SetDecisionLogger(decisionLogger)
+	}
+
 	// Init feature service
 	featureSvc := feature.NewService(svcEnv, objectSvc)
 
@@ -166,6 +279,10 @@ func main() {
 	// Init role service
 	roleSvc := role.NewService(svcEnv, objectSvc)
 
+	// Init session service
+	sessionSvc := session.NewService(svcEnv, cfg.GetAuthentication().Sessions)
+	sessionSvc.AddChangeRecorder(auditLogSvc)
+
 	// Init tenant service
 	tenantSvc := tenant.NewService(svcEnv, objectSvc)
 
@@ -173,17 +290,23 @@ func main() {
 	userSvc := user.NewService(svcEnv, objectSvc)
 
 	svcs := []service.Service{
+		apiKeySvc,
+		auditLogSvc,
+		changeSvc,
 		checkSvc,
 		featureSvc,
+		healthSvc,
 		objectSvc,
 		objectTypeSvc,
 		permissionSvc,
 		pricingTierSvc,
 		querySvc,
 		roleSvc,
+		sessionSvc,
 		tenantSvc,
 		userSvc,
 		warrantSvc,
+		webhookSvc,
 	}
 
 	routes := make([]service.Route, 0)
@@ -196,12 +319,68 @@ func main() {
 		routes = append(routes, svcRoutes...)
 	}
 
-	router, err := service.NewRouter(cfg, "", routes, service.ApiKeyAuthMiddleware, []service.Middleware{}, []service.Middleware{})
+	routerMiddlewares := make([]service.Middleware, 0)
+	if cfg.GetServerTiming() != nil && (cfg.GetServerTiming().Enabled || cfg.GetServerTiming().AllowRequestHeader) {
+		routerMiddlewares = append(routerMiddlewares, service.ServerTimingMiddleware(cfg.GetServerTiming()))
+	}
+	if cfg.GetCORS() != nil && cfg.GetCORS().Enabled {
+		routerMiddlewares = append(routerMiddlewares, service.CORSMiddleware(cfg.GetCORS()))
+	}
+	if cfg.GetRateLimit() != nil && cfg.GetRateLimit().Enabled {
+		failedAuthRateLimitMiddleware, err := service.FailedAuthRateLimitMiddleware(cfg.GetRateLimit())
+		if err != nil {
+			log.Fatal().Err(err).Msg("init: could not initialize rate limiting")
+		}
+
+		routerMiddlewares = append(routerMiddlewares, failedAuthRateLimitMiddleware)
+	}
+	routerMiddlewares = append(routerMiddlewares, service.ApiKeyResolverMiddleware(apiKeySvc))
+
+	requestMiddlewares := make([]service.Middleware, 0)
+	if cfg.GetRateLimit() != nil && cfg.GetRateLimit().Enabled {
+		rateLimitMiddleware, err := service.RateLimitMiddleware(cfg.GetRateLimit())
+		if err != nil {
+			log.Fatal().Err(err).Msg("init: could not initialize rate limiting")
+		}
+
+		requestMiddlewares = append(requestMiddlewares, rateLimitMiddleware)
+	}
+
+	router, err := service.NewRouter(cfg, "", routes, service.ApiKeyAuthMiddleware, routerMiddlewares, requestMiddlewares)
 	if err != nil {
 		log.Fatal().Err(err).Msg("init: could not initialize service router")
 	}
 
-	log.Info().Msgf("init: listening on port %d", cfg.GetPort())
-	shutdownErr := http.ListenAndServe(fmt.Sprintf(":%d", cfg.GetPort()), router)
-	log.Fatal().Err(shutdownErr).Msg("shutdown")
+	if cfg.GetMetrics() != nil && cfg.GetMetrics().Enabled {
+		go runMetricsServer(cfg.GetMetrics())
+	}
+
+	handler.set(router)
+	healthSvc.SetReady()
+	log.Info().Msg("init: ready")
+
+	// Shut down gracefully on SIGINT or SIGTERM, failing readiness checks for
+	// shutdown.delay first so that traffic can be routed elsewhere. A second
+	// signal exits immediately.
+	<-shutdownCtx.Done()
+	stopListeningForShutdown()
+	healthSvc.SetShuttingDown()
+	log.Info().Msgf("shutdown: shutting down in %s", cfg.GetShutdown().Delay)
+	time.Sleep(cfg.GetShutdown().Delay)
+
+	ctx, cancelFunc := context.WithTimeout(context.Background(), cfg.GetShutdown().Timeout)
+	defer cancelFunc()
+	err = server.Shutdown(ctx)
+	if err != nil {
+		// Close long-lived connections (e.g. change streams) that didn't complete in time
+		log.Error().Err(err).Msg("shutdown: could not complete in-flight requests")
+		server.Close()
+	}
+
+	// Flush any buffered spans before exiting
+	err = stopTracing(ctx)
+	if err != nil {
+		log.Error().Err(err).Msg("shutdown: could not flush traces")
+	}
+	log.Info().Msg("shutdown: complete")
 }
@@ -0,0 +1,40 @@
+// Copyright 2024 WorkOS, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/warrant-dev/warrant/pkg/config"
+	"github.com/warrant-dev/warrant/pkg/metrics"
+)
+
+// Serves Prometheus metrics on the admin port, separately from the API.
+func runMetricsServer(cfg *config.MetricsConfig) {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", metrics.Handler())
+	server := &http.Server{
+		Addr:              fmt.Sprintf(":%d", cfg.Port),
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	log.Info().Msgf("init: serving metrics on port %d", cfg.Port)
+	err := server.ListenAndServe()
+	log.Error().Err(err).Msg("metrics: server stopped")
+}
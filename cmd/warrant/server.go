@@ -0,0 +1,40 @@
+// Copyright 2024 WorkOS, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"net/http"
+	"sync/atomic"
+)
+
+// Serves requests with the handler most recently set, so that the server can
+// start serving health checks before the rest of the routes are initialized.
+type swappableHandler struct {
+	handler atomic.Pointer[http.Handler]
+}
+
+func newSwappableHandler(handler http.Handler) *swappableHandler {
+	h := &swappableHandler{}
+	h.set(handler)
+	return h
+}
+
+func (h *swappableHandler) set(handler http.Handler) {
+	h.handler.Store(&handler)
+}
+
+func (h *swappableHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	(*h.handler.Load()).ServeHTTP(w, r)
+}
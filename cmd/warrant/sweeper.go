@@ -0,0 +1,64 @@
+// Copyright 2024 WorkOS, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	warrant "github.com/warrant-dev/warrant/pkg/authz/warrant"
+	"github.com/warrant-dev/warrant/pkg/config"
+)
+
+// Periodically soft-deletes expired warrants in batches until ctx is done.
+func runExpiredWarrantSweeper(ctx context.Context, warrantSvc *warrant.WarrantService, cfg *config.SweeperConfig) {
+	if cfg.Interval <= 0 || cfg.BatchSize <= 0 {
+		log.Warn().Msg("sweeper: interval and batchSize must be positive, expired warrants will not be swept")
+		return
+	}
+
+	ticker := time.NewTicker(cfg.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			sweepExpiredWarrants(ctx, warrantSvc, cfg.BatchSize)
+		}
+	}
+}
+
+func sweepExpiredWarrants(ctx context.Context, warrantSvc *warrant.WarrantService, batchSize int) {
+	var totalDeleted int64
+	for {
+		numDeleted, err := warrantSvc.DeleteExpired(ctx, batchSize)
+		if err != nil {
+			log.Error().Err(err).Msg("sweeper: error deleting expired warrants")
+			return
+		}
+
+		totalDeleted += numDeleted
+		if numDeleted < int64(batchSize) || ctx.Err() != nil {
+			break
+		}
+	}
+
+	if totalDeleted > 0 {
+		log.Debug().Msgf("sweeper: deleted %d expired warrants", totalDeleted)
+	}
+}
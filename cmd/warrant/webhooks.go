@@ -0,0 +1,58 @@
+// Copyright 2024 WorkOS, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/warrant-dev/warrant/pkg/config"
+	"github.com/warrant-dev/warrant/pkg/webhook"
+)
+
+// Periodically delivers queued change events to webhooks until ctx is done.
+func runWebhookDispatcher(ctx context.Context, webhookSvc *webhook.WebhookService, cfg *config.WebhooksConfig) {
+	if cfg.PollInterval <= 0 || cfg.BatchSize <= 0 || cfg.Timeout <= 0 {
+		log.Warn().Msg("webhooks: pollInterval, batchSize and timeout must be positive, webhooks will not be delivered")
+		return
+	}
+
+	ticker := time.NewTicker(cfg.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			deliverWebhooks(ctx, webhookSvc, cfg.BatchSize)
+		}
+	}
+}
+
+func deliverWebhooks(ctx context.Context, webhookSvc *webhook.WebhookService, batchSize int) {
+	for {
+		numAttempted, err := webhookSvc.DeliverDue(ctx)
+		if err != nil {
+			log.Error().Err(err).Msg("webhooks: error delivering webhooks")
+			return
+		}
+
+		if numAttempted < batchSize || ctx.Err() != nil {
+			return
+		}
+	}
+}
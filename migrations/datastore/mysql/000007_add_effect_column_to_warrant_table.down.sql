@@ -0,0 +1,13 @@
+BEGIN;
+
+# NOTE: Running this down migration will result in the loss
+# of all deny warrants.
+DELETE FROM warrant
+WHERE effect = "deny";
+
+ALTER TABLE warrant
+DROP INDEX warrant_uk_obj_rel_sub_policy_hash_effect,
+DROP COLUMN effect,
+ADD UNIQUE KEY warrant_uk_obj_rel_sub_policy_hash (objectType, objectId, relation, subjectType, subjectId, subjectRelation, policyHash);
+
+COMMIT;
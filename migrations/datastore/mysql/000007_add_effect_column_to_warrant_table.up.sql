@@ -0,0 +1,8 @@
+BEGIN;
+
+ALTER TABLE warrant
+ADD COLUMN effect varchar(16) NOT NULL DEFAULT 'allow' AFTER policyHash,
+DROP INDEX warrant_uk_obj_rel_sub_policy_hash,
+ADD UNIQUE KEY warrant_uk_obj_rel_sub_policy_hash_effect (objectType, objectId, relation, subjectType, subjectId, subjectRelation, policyHash, effect);
+
+COMMIT;
@@ -0,0 +1,7 @@
+BEGIN;
+
+ALTER TABLE warrant
+DROP INDEX warrant_idx_expires_at,
+DROP COLUMN expiresAt;
+
+COMMIT;
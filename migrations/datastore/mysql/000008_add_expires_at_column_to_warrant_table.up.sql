@@ -0,0 +1,7 @@
+BEGIN;
+
+ALTER TABLE warrant
+ADD COLUMN expiresAt timestamp(6) NULL DEFAULT NULL AFTER effect,
+ADD INDEX warrant_idx_expires_at (expiresAt);
+
+COMMIT;
@@ -0,0 +1,13 @@
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS changelog (
+  id bigint NOT NULL AUTO_INCREMENT,
+  wookieId bigint NOT NULL,
+  type varchar(64) NOT NULL,
+  data json NOT NULL,
+  createdAt timestamp(6) NULL DEFAULT CURRENT_TIMESTAMP(6),
+  PRIMARY KEY (id),
+  INDEX changelog_idx_wookie_id (wookieId)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci;
+
+COMMIT;
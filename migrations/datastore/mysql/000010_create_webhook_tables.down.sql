@@ -0,0 +1,7 @@
+BEGIN;
+
+DROP TABLE IF EXISTS webhookDeadLetter;
+DROP TABLE IF EXISTS webhookDelivery;
+DROP TABLE IF EXISTS webhook;
+
+COMMIT;
@@ -0,0 +1,44 @@
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS webhook (
+  id bigint NOT NULL AUTO_INCREMENT,
+  webhookId varchar(64) NOT NULL,
+  url varchar(2048) NOT NULL,
+  secret varchar(128) NOT NULL,
+  eventTypes json DEFAULT NULL,
+  createdAt timestamp(6) NULL DEFAULT CURRENT_TIMESTAMP(6),
+  updatedAt timestamp(6) NULL DEFAULT CURRENT_TIMESTAMP(6) ON UPDATE CURRENT_TIMESTAMP(6),
+  deletedAt timestamp(6) NULL DEFAULT NULL,
+  PRIMARY KEY (id),
+  UNIQUE KEY webhook_uk_webhook_id (webhookId)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci;
+
+CREATE TABLE IF NOT EXISTS webhookDelivery (
+  id bigint NOT NULL AUTO_INCREMENT,
+  webhookId bigint NOT NULL,
+  changeId bigint NOT NULL,
+  type varchar(64) NOT NULL,
+  payload json NOT NULL,
+  attempts int NOT NULL DEFAULT 0,
+  nextAttemptAt timestamp(6) NOT NULL,
+  lastError text DEFAULT NULL,
+  createdAt timestamp(6) NULL DEFAULT CURRENT_TIMESTAMP(6),
+  PRIMARY KEY (id),
+  INDEX webhook_delivery_idx_next_attempt_at (nextAttemptAt),
+  INDEX webhook_delivery_idx_webhook_id (webhookId)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci;
+
+CREATE TABLE IF NOT EXISTS webhookDeadLetter (
+  id bigint NOT NULL AUTO_INCREMENT,
+  webhookId bigint NOT NULL,
+  changeId bigint NOT NULL,
+  type varchar(64) NOT NULL,
+  payload json NOT NULL,
+  attempts int NOT NULL,
+  lastError text DEFAULT NULL,
+  createdAt timestamp(6) NULL DEFAULT CURRENT_TIMESTAMP(6),
+  PRIMARY KEY (id),
+  INDEX webhook_dead_letter_idx_webhook_id (webhookId)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci;
+
+COMMIT;
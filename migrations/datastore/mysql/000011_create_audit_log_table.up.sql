@@ -0,0 +1,20 @@
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS auditLog (
+  id bigint NOT NULL AUTO_INCREMENT,
+  type varchar(64) NOT NULL,
+  actorType varchar(32) NOT NULL,
+  actorId varchar(255) NOT NULL,
+  requestId varchar(64) NOT NULL,
+  clientIp varchar(255) NOT NULL,
+  method varchar(16) NOT NULL,
+  path varchar(2048) NOT NULL,
+  beforeData json NULL,
+  afterData json NULL,
+  createdAt timestamp(6) NULL DEFAULT CURRENT_TIMESTAMP(6),
+  PRIMARY KEY (id),
+  INDEX auditLog_idx_actor (actorType, actorId),
+  INDEX auditLog_idx_request_id (requestId)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci;
+
+COMMIT;
@@ -0,0 +1,21 @@
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS decisionLog (
+  id bigint NOT NULL AUTO_INCREMENT,
+  type varchar(16) NOT NULL,
+  requestId varchar(64) NOT NULL,
+  route varchar(255) NOT NULL,
+  subject varchar(512) NOT NULL,
+  request json NOT NULL,
+  result varchar(64) NOT NULL,
+  numResults bigint NULL,
+  isImplicit tinyint(1) NOT NULL DEFAULT 0,
+  latencyMs double NOT NULL,
+  warrantToken varchar(255) NOT NULL,
+  error text NOT NULL,
+  createdAt timestamp(6) NULL DEFAULT CURRENT_TIMESTAMP(6),
+  PRIMARY KEY (id),
+  INDEX decisionLog_idx_created_at (createdAt)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci;
+
+COMMIT;
@@ -0,0 +1,15 @@
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS apiKey (
+  id bigint NOT NULL AUTO_INCREMENT,
+  name varchar(64) NOT NULL,
+  keyHash char(64) NOT NULL,
+  scopes json NOT NULL,
+  createdAt timestamp(6) NULL DEFAULT CURRENT_TIMESTAMP(6),
+  updatedAt timestamp(6) NULL DEFAULT CURRENT_TIMESTAMP(6) ON UPDATE CURRENT_TIMESTAMP(6),
+  PRIMARY KEY (id),
+  UNIQUE KEY apiKey_uk_name (name),
+  UNIQUE KEY apiKey_uk_key_hash (keyHash)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci;
+
+COMMIT;
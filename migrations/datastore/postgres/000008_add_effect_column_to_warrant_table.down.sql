@@ -0,0 +1,13 @@
+BEGIN;
+
+-- NOTE: Running this down migration will result in the loss
+-- of all deny warrants.
+DELETE FROM warrant
+WHERE effect = 'deny';
+
+ALTER TABLE warrant
+DROP CONSTRAINT warrant_uk_obj_rel_sub_policy_hash_effect,
+DROP COLUMN effect,
+ADD CONSTRAINT warrant_uk_obj_rel_sub_policy_hash UNIQUE (object_type, object_id, relation, subject_type, subject_id, subject_relation, policy_hash);
+
+COMMIT;
@@ -0,0 +1,8 @@
+BEGIN;
+
+ALTER TABLE warrant
+ADD COLUMN effect varchar(16) NOT NULL DEFAULT 'allow',
+DROP CONSTRAINT warrant_uk_obj_rel_sub_policy_hash,
+ADD CONSTRAINT warrant_uk_obj_rel_sub_policy_hash_effect UNIQUE (object_type, object_id, relation, subject_type, subject_id, subject_relation, policy_hash, effect);
+
+COMMIT;
@@ -0,0 +1,8 @@
+BEGIN;
+
+DROP INDEX IF EXISTS warrant_idx_expires_at;
+
+ALTER TABLE warrant
+DROP COLUMN expires_at;
+
+COMMIT;
@@ -0,0 +1,9 @@
+BEGIN;
+
+ALTER TABLE warrant
+ADD COLUMN expires_at timestamp(6) NULL DEFAULT NULL;
+
+CREATE INDEX IF NOT EXISTS warrant_idx_expires_at
+    ON warrant (expires_at);
+
+COMMIT;
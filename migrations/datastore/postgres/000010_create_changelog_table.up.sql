@@ -0,0 +1,14 @@
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS changelog (
+  id bigserial PRIMARY KEY,
+  wookie_id bigint NOT NULL,
+  type varchar(64) NOT NULL,
+  data jsonb NOT NULL,
+  created_at timestamp(6) NULL DEFAULT CURRENT_TIMESTAMP(6)
+);
+
+CREATE INDEX IF NOT EXISTS changelog_idx_wookie_id
+    ON changelog (wookie_id);
+
+COMMIT;
@@ -0,0 +1,7 @@
+BEGIN;
+
+DROP TABLE IF EXISTS webhook_dead_letter;
+DROP TABLE IF EXISTS webhook_delivery;
+DROP TABLE IF EXISTS webhook;
+
+COMMIT;
@@ -0,0 +1,50 @@
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS webhook (
+  id bigserial PRIMARY KEY,
+  webhook_id varchar(64) NOT NULL CONSTRAINT webhook_uk_webhook_id UNIQUE,
+  url varchar(2048) NOT NULL,
+  secret varchar(128) NOT NULL,
+  event_types jsonb DEFAULT NULL,
+  created_at timestamp(6) NULL DEFAULT CURRENT_TIMESTAMP(6),
+  updated_at timestamp(6) NULL DEFAULT CURRENT_TIMESTAMP(6),
+  deleted_at timestamp(6) NULL DEFAULT NULL
+);
+
+CREATE TRIGGER update_updated_at
+BEFORE UPDATE ON webhook
+FOR EACH ROW EXECUTE PROCEDURE update_updated_at();
+
+CREATE TABLE IF NOT EXISTS webhook_delivery (
+  id bigserial PRIMARY KEY,
+  webhook_id bigint NOT NULL,
+  change_id bigint NOT NULL,
+  type varchar(64) NOT NULL,
+  payload jsonb NOT NULL,
+  attempts int NOT NULL DEFAULT 0,
+  next_attempt_at timestamp(6) NOT NULL,
+  last_error text DEFAULT NULL,
+  created_at timestamp(6) NULL DEFAULT CURRENT_TIMESTAMP(6)
+);
+
+CREATE INDEX IF NOT EXISTS webhook_delivery_idx_next_attempt_at
+    ON webhook_delivery (next_attempt_at);
+
+CREATE INDEX IF NOT EXISTS webhook_delivery_idx_webhook_id
+    ON webhook_delivery (webhook_id);
+
+CREATE TABLE IF NOT EXISTS webhook_dead_letter (
+  id bigserial PRIMARY KEY,
+  webhook_id bigint NOT NULL,
+  change_id bigint NOT NULL,
+  type varchar(64) NOT NULL,
+  payload jsonb NOT NULL,
+  attempts int NOT NULL,
+  last_error text DEFAULT NULL,
+  created_at timestamp(6) NULL DEFAULT CURRENT_TIMESTAMP(6)
+);
+
+CREATE INDEX IF NOT EXISTS webhook_dead_letter_idx_webhook_id
+    ON webhook_dead_letter (webhook_id);
+
+COMMIT;
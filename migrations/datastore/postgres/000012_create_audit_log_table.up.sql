@@ -0,0 +1,23 @@
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS audit_log (
+  id bigserial PRIMARY KEY,
+  type varchar(64) NOT NULL,
+  actor_type varchar(32) NOT NULL,
+  actor_id varchar(255) NOT NULL,
+  request_id varchar(64) NOT NULL,
+  client_ip varchar(255) NOT NULL,
+  method varchar(16) NOT NULL,
+  path varchar(2048) NOT NULL,
+  before_data jsonb NULL,
+  after_data jsonb NULL,
+  created_at timestamp(6) NULL DEFAULT CURRENT_TIMESTAMP(6)
+);
+
+CREATE INDEX IF NOT EXISTS audit_log_idx_actor
+    ON audit_log (actor_type, actor_id);
+
+CREATE INDEX IF NOT EXISTS audit_log_idx_request_id
+    ON audit_log (request_id);
+
+COMMIT;
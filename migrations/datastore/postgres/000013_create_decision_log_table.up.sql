@@ -0,0 +1,22 @@
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS decision_log (
+  id bigserial PRIMARY KEY,
+  type varchar(16) NOT NULL,
+  request_id varchar(64) NOT NULL,
+  route varchar(255) NOT NULL,
+  subject varchar(512) NOT NULL,
+  request jsonb NOT NULL,
+  result varchar(64) NOT NULL,
+  num_results bigint NULL,
+  is_implicit boolean NOT NULL DEFAULT false,
+  latency_ms double precision NOT NULL,
+  warrant_token varchar(255) NOT NULL,
+  error text NOT NULL,
+  created_at timestamp(6) NULL DEFAULT CURRENT_TIMESTAMP(6)
+);
+
+CREATE INDEX IF NOT EXISTS decision_log_idx_created_at
+    ON decision_log (created_at);
+
+COMMIT;
@@ -0,0 +1,16 @@
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS api_key (
+  id bigserial PRIMARY KEY,
+  name varchar(64) NOT NULL CONSTRAINT api_key_uk_name UNIQUE,
+  key_hash char(64) NOT NULL CONSTRAINT api_key_uk_key_hash UNIQUE,
+  scopes jsonb NOT NULL,
+  created_at timestamp(6) NULL DEFAULT CURRENT_TIMESTAMP(6),
+  updated_at timestamp(6) NULL DEFAULT CURRENT_TIMESTAMP(6)
+);
+
+CREATE TRIGGER update_updated_at
+BEFORE UPDATE ON api_key
+FOR EACH ROW EXECUTE PROCEDURE update_updated_at();
+
+COMMIT;
@@ -0,0 +1,12 @@
+-- NOTE: Running this down migration will result in the loss
+-- of all deny warrants.
+DELETE FROM warrant
+WHERE effect = "deny";
+
+DROP INDEX IF EXISTS warrant_uk_obj_rel_sub_policy_hash_effect;
+
+ALTER TABLE warrant
+DROP COLUMN effect;
+
+CREATE UNIQUE INDEX IF NOT EXISTS warrant_uk_obj_rel_sub_policy_hash
+    ON warrant (objectType, objectId, relation, subjectType, subjectId, subjectRelation, policyHash);
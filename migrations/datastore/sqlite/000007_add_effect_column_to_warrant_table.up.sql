@@ -0,0 +1,7 @@
+ALTER TABLE warrant
+ADD COLUMN effect TEXT NOT NULL DEFAULT 'allow';
+
+DROP INDEX IF EXISTS warrant_uk_obj_rel_sub_policy_hash;
+
+CREATE UNIQUE INDEX IF NOT EXISTS warrant_uk_obj_rel_sub_policy_hash_effect
+    ON warrant (objectType, objectId, relation, subjectType, subjectId, subjectRelation, policyHash, effect);
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS warrant_idx_expires_at;
+
+ALTER TABLE warrant
+DROP COLUMN expiresAt;
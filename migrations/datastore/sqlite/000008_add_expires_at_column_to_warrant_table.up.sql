@@ -0,0 +1,5 @@
+ALTER TABLE warrant
+ADD COLUMN expiresAt DATETIME DEFAULT NULL;
+
+CREATE INDEX IF NOT EXISTS warrant_idx_expires_at
+    ON warrant (expiresAt);
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS webhookDeadLetter;
+DROP TABLE IF EXISTS webhookDelivery;
+DROP TABLE IF EXISTS webhook;
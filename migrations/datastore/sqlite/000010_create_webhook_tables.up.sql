@@ -0,0 +1,45 @@
+CREATE TABLE IF NOT EXISTS webhook (
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
+  webhookId TEXT NOT NULL,
+  url TEXT NOT NULL,
+  secret TEXT NOT NULL,
+  eventTypes TEXT DEFAULT NULL,
+  createdAt DATETIME DEFAULT CURRENT_TIMESTAMP,
+  updatedAt DATETIME DEFAULT CURRENT_TIMESTAMP,
+  deletedAt DATETIME DEFAULT NULL
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS webhook_uk_webhook_id
+    ON webhook (webhookId);
+
+CREATE TABLE IF NOT EXISTS webhookDelivery (
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
+  webhookId INTEGER NOT NULL,
+  changeId INTEGER NOT NULL,
+  type TEXT NOT NULL,
+  payload TEXT NOT NULL,
+  attempts INTEGER NOT NULL DEFAULT 0,
+  nextAttemptAt DATETIME NOT NULL,
+  lastError TEXT DEFAULT NULL,
+  createdAt DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS webhook_delivery_idx_next_attempt_at
+    ON webhookDelivery (nextAttemptAt);
+
+CREATE INDEX IF NOT EXISTS webhook_delivery_idx_webhook_id
+    ON webhookDelivery (webhookId);
+
+CREATE TABLE IF NOT EXISTS webhookDeadLetter (
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
+  webhookId INTEGER NOT NULL,
+  changeId INTEGER NOT NULL,
+  type TEXT NOT NULL,
+  payload TEXT NOT NULL,
+  attempts INTEGER NOT NULL,
+  lastError TEXT DEFAULT NULL,
+  createdAt DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS webhook_dead_letter_idx_webhook_id
+    ON webhookDeadLetter (webhookId);
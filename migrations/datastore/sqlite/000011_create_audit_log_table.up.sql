@@ -0,0 +1,19 @@
+CREATE TABLE IF NOT EXISTS auditLog (
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
+  type TEXT NOT NULL,
+  actorType TEXT NOT NULL,
+  actorId TEXT NOT NULL,
+  requestId TEXT NOT NULL,
+  clientIp TEXT NOT NULL,
+  method TEXT NOT NULL,
+  path TEXT NOT NULL,
+  beforeData TEXT NULL,
+  afterData TEXT NULL,
+  createdAt DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS auditLog_idx_actor
+    ON auditLog (actorType, actorId);
+
+CREATE INDEX IF NOT EXISTS auditLog_idx_request_id
+    ON auditLog (requestId);
@@ -0,0 +1,18 @@
+CREATE TABLE IF NOT EXISTS decisionLog (
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
+  type TEXT NOT NULL,
+  requestId TEXT NOT NULL,
+  route TEXT NOT NULL,
+  subject TEXT NOT NULL,
+  request TEXT NOT NULL,
+  result TEXT NOT NULL,
+  numResults INTEGER NULL,
+  isImplicit INTEGER NOT NULL DEFAULT 0,
+  latencyMs REAL NOT NULL,
+  warrantToken TEXT NOT NULL,
+  error TEXT NOT NULL,
+  createdAt DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS decisionLog_idx_created_at
+    ON decisionLog (createdAt);
@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS apiKey (
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
+  name TEXT NOT NULL,
+  keyHash TEXT NOT NULL,
+  scopes TEXT NOT NULL,
+  createdAt DATETIME DEFAULT CURRENT_TIMESTAMP,
+  updatedAt DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS apiKey_uk_name
+    ON apiKey (name);
+
+CREATE UNIQUE INDEX IF NOT EXISTS apiKey_uk_key_hash
+    ON apiKey (keyHash);
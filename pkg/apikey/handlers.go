@@ -0,0 +1,121 @@
+// Copyright 2024 WorkOS, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package apikey
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/warrant-dev/warrant/pkg/service"
+)
+
+func (svc ApiKeyService) Routes() ([]service.Route, error) {
+	return []service.Route{
+		// create
+		service.WarrantRoute{
+			Pattern: "/v2/api-keys",
+			Method:  "POST",
+			Handler: service.NewRouteHandler(svc, createHandler),
+		},
+
+		// list
+		service.WarrantRoute{
+			Pattern: "/v2/api-keys",
+			Method:  "GET",
+			Handler: service.NewRouteHandler(svc, listHandler),
+		},
+
+		// get
+		service.WarrantRoute{
+			Pattern: "/v2/api-keys/{name}",
+			Method:  "GET",
+			Handler: service.NewRouteHandler(svc, getHandler),
+		},
+
+		// revoke
+		service.WarrantRoute{
+			Pattern: "/v2/api-keys/{name}",
+			Method:  "DELETE",
+			Handler: service.NewRouteHandler(svc, revokeHandler),
+		},
+
+		// rotate
+		service.WarrantRoute{
+			Pattern: "/v2/api-keys/{name}/rotate",
+			Method:  "POST",
+			Handler: service.NewRouteHandler(svc, rotateHandler),
+		},
+	}, nil
+}
+
+func createHandler(svc ApiKeyService, w http.ResponseWriter, r *http.Request) error {
+	var spec CreateApiKeySpec
+	err := service.ParseJSONBody(r.Context(), r.Body, &spec)
+	if err != nil {
+		return err
+	}
+
+	createdApiKey, err := svc.Create(r.Context(), spec)
+	if err != nil {
+		return err
+	}
+
+	service.SendJSONResponse(w, createdApiKey)
+	return nil
+}
+
+func listHandler(svc ApiKeyService, w http.ResponseWriter, r *http.Request) error {
+	apiKeys, err := svc.List(r.Context())
+	if err != nil {
+		return err
+	}
+
+	service.SendJSONResponse(w, apiKeys)
+	return nil
+}
+
+func getHandler(svc ApiKeyService, w http.ResponseWriter, r *http.Request) error {
+	name := mux.Vars(r)["name"]
+	apiKey, err := svc.GetByName(r.Context(), name)
+	if err != nil {
+		return err
+	}
+
+	service.SendJSONResponse(w, apiKey)
+	return nil
+}
+
+func revokeHandler(svc ApiKeyService, w http.ResponseWriter, r *http.Request) error {
+	name := mux.Vars(r)["name"]
+	err := svc.Revoke(r.Context(), name)
+	if err != nil {
+		return err
+	}
+
+	w.Header().Set("Content-type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	return nil
+}
+
+func rotateHandler(svc ApiKeyService, w http.ResponseWriter, r *http.Request) error {
+	name := mux.Vars(r)["name"]
+	rotatedApiKey, err := svc.Rotate(r.Context(), name)
+	if err != nil {
+		return err
+	}
+
+	service.SendJSONResponse(w, rotatedApiKey)
+	return nil
+}
@@ -0,0 +1,81 @@
+// Copyright 2024 WorkOS, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package apikey
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+type Model interface {
+	GetID() int64
+	GetName() string
+	GetKeyHash() string
+	GetScopes() string
+	GetCreatedAt() time.Time
+	GetUpdatedAt() time.Time
+	ToApiKeySpec() (*ApiKeySpec, error)
+}
+
+type ApiKey struct {
+	ID        int64     `mysql:"id"        postgres:"id"         sqlite:"id"`
+	Name      string    `mysql:"name"      postgres:"name"       sqlite:"name"`
+	KeyHash   string    `mysql:"keyHash"   postgres:"key_hash"   sqlite:"keyHash"`
+	Scopes    string    `mysql:"scopes"    postgres:"scopes"     sqlite:"scopes"`
+	CreatedAt time.Time `mysql:"createdAt" postgres:"created_at" sqlite:"createdAt"`
+	UpdatedAt time.Time `mysql:"updatedAt" postgres:"updated_at" sqlite:"updatedAt"`
+}
+
+func (apiKey ApiKey) GetID() int64 {
+	return apiKey.ID
+}
+
+func (apiKey ApiKey) GetName() string {
+	return apiKey.Name
+}
+
+func (apiKey ApiKey) GetKeyHash() string {
+	return apiKey.KeyHash
+}
+
+func (apiKey ApiKey) GetScopes() string {
+	return apiKey.Scopes
+}
+
+func (apiKey ApiKey) GetCreatedAt() time.Time {
+	return apiKey.CreatedAt
+}
+
+func (apiKey ApiKey) GetUpdatedAt() time.Time {
+	return apiKey.UpdatedAt
+}
+
+func (apiKey ApiKey) ToApiKeySpec() (*ApiKeySpec, error) {
+	var scopes []string
+	err := json.Unmarshal([]byte(apiKey.Scopes), &scopes)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error unmarshaling scopes for api key %s", apiKey.Name)
+	}
+
+	return &ApiKeySpec{
+		ID:        apiKey.ID,
+		Name:      apiKey.Name,
+		Scopes:    scopes,
+		CreatedAt: apiKey.CreatedAt,
+		UpdatedAt: apiKey.UpdatedAt,
+	}, nil
+}
@@ -0,0 +1,215 @@
+// Copyright 2024 WorkOS, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package apikey
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+
+	"github.com/pkg/errors"
+	"github.com/warrant-dev/warrant/pkg/service"
+)
+
+const keyPrefix = "wk_"
+
+type ApiKeyService struct {
+	service.BaseService
+	repository ApiKeyRepository
+}
+
+func NewService(env service.Env, repository ApiKeyRepository) *ApiKeyService {
+	return &ApiKeyService{
+		BaseService: service.NewBaseService(env),
+		repository:  repository,
+	}
+}
+
+func (svc ApiKeyService) Create(ctx context.Context, spec CreateApiKeySpec) (*ApiKeySpec, error) {
+	// The configured authentication.apiKey is identified by this name
+	if spec.Name == service.DefaultApiKeyId {
+		return nil, service.NewDuplicateRecordError("ApiKey", spec.Name, "An api key with the given name already exists")
+	}
+
+	key, err := generateKey()
+	if err != nil {
+		return nil, err
+	}
+
+	var createdApiKeySpec *ApiKeySpec
+	err = svc.Env().DB().WithinTransaction(ctx, func(txCtx context.Context) error {
+		_, err := svc.repository.GetByName(txCtx, spec.Name)
+		if err == nil {
+			return service.NewDuplicateRecordError("ApiKey", spec.Name, "An api key with the given name already exists")
+		}
+		var recordNotFoundError *service.RecordNotFoundError
+		if !errors.As(err, &recordNotFoundError) {
+			return err
+		}
+
+		apiKey, err := spec.ToApiKey(service.HashApiKey(key))
+		if err != nil {
+			return err
+		}
+
+		newApiKeyId, err := svc.repository.Create(txCtx, apiKey)
+		if err != nil {
+			return err
+		}
+
+		createdApiKey, err := svc.repository.GetById(txCtx, newApiKeyId)
+		if err != nil {
+			return err
+		}
+
+		createdApiKeySpec, err = createdApiKey.ToApiKeySpec()
+		if err != nil {
+			return err
+		}
+
+		return svc.RecordChange(txCtx, ChangeTypeApiKeyCreated, nil, createdApiKeySpec)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	createdApiKeySpec.Key = key
+	return createdApiKeySpec, nil
+}
+
+func (svc ApiKeyService) GetByName(ctx context.Context, name string) (*ApiKeySpec, error) {
+	apiKey, err := svc.repository.GetByName(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+
+	return apiKey.ToApiKeySpec()
+}
+
+func (svc ApiKeyService) List(ctx context.Context) ([]ApiKeySpec, error) {
+	apiKeys, err := svc.repository.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	apiKeySpecs := make([]ApiKeySpec, 0, len(apiKeys))
+	for _, apiKey := range apiKeys {
+		apiKeySpec, err := apiKey.ToApiKeySpec()
+		if err != nil {
+			return nil, err
+		}
+
+		apiKeySpecs = append(apiKeySpecs, *apiKeySpec)
+	}
+
+	return apiKeySpecs, nil
+}
+
+// Rotate replaces the key of the named api key. The previous key stops working immediately.
+func (svc ApiKeyService) Rotate(ctx context.Context, name string) (*ApiKeySpec, error) {
+	key, err := generateKey()
+	if err != nil {
+		return nil, err
+	}
+
+	var rotatedApiKeySpec *ApiKeySpec
+	err = svc.Env().DB().WithinTransaction(ctx, func(txCtx context.Context) error {
+		apiKey, err := svc.repository.GetByName(txCtx, name)
+		if err != nil {
+			return err
+		}
+
+		currentApiKeySpec, err := apiKey.ToApiKeySpec()
+		if err != nil {
+			return err
+		}
+
+		err = svc.repository.UpdateKeyHashByName(txCtx, name, service.HashApiKey(key))
+		if err != nil {
+			return err
+		}
+
+		rotatedApiKey, err := svc.repository.GetByName(txCtx, name)
+		if err != nil {
+			return err
+		}
+
+		rotatedApiKeySpec, err = rotatedApiKey.ToApiKeySpec()
+		if err != nil {
+			return err
+		}
+
+		return svc.RecordChange(txCtx, ChangeTypeApiKeyRotated, currentApiKeySpec, rotatedApiKeySpec)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	rotatedApiKeySpec.Key = key
+	return rotatedApiKeySpec, nil
+}
+
+func (svc ApiKeyService) Revoke(ctx context.Context, name string) error {
+	return svc.Env().DB().WithinTransaction(ctx, func(txCtx context.Context) error {
+		apiKey, err := svc.repository.GetByName(txCtx, name)
+		if err != nil {
+			return err
+		}
+
+		apiKeySpec, err := apiKey.ToApiKeySpec()
+		if err != nil {
+			return err
+		}
+
+		err = svc.repository.DeleteByName(txCtx, name)
+		if err != nil {
+			return err
+		}
+
+		return svc.RecordChange(txCtx, ChangeTypeApiKeyRevoked, apiKeySpec, nil)
+	})
+}
+
+// ResolveApiKey implements service.ApiKeyResolver for keys stored in the database.
+func (svc ApiKeyService) ResolveApiKey(ctx context.Context, keyHash string) (*service.AuthInfo, error) {
+	apiKey, err := svc.repository.GetByKeyHash(ctx, keyHash)
+	if err != nil {
+		var recordNotFoundError *service.RecordNotFoundError
+		if errors.As(err, &recordNotFoundError) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	apiKeySpec, err := apiKey.ToApiKeySpec()
+	if err != nil {
+		return nil, err
+	}
+
+	return &service.AuthInfo{
+		ApiKeyId: apiKeySpec.Name,
+		Scopes:   apiKeySpec.Scopes,
+	}, nil
+}
+
+func generateKey() (string, error) {
+	b := make([]byte, 32)
+	_, err := rand.Read(b)
+	if err != nil {
+		return "", errors.Wrap(err, "error generating api key")
+	}
+
+	return keyPrefix + hex.EncodeToString(b), nil
+}
@@ -0,0 +1,58 @@
+// Copyright 2024 WorkOS, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package apikey
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/warrant-dev/warrant/pkg/service"
+)
+
+const (
+	ChangeTypeApiKeyCreated = "apikey.created"
+	ChangeTypeApiKeyRotated = "apikey.rotated"
+	ChangeTypeApiKeyRevoked = "apikey.revoked"
+)
+
+type ApiKeySpec struct {
+	// NOTE: ID is required here for internal use.
+	// However, we don't return it to the client.
+	ID   int64  `json:"-"`
+	Name string `json:"name"`
+	// NOTE: Key is only returned when the api key is created or rotated.
+	Key       string    `json:"key,omitempty"`
+	Scopes    []string  `json:"scopes"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+type CreateApiKeySpec struct {
+	Name   string   `json:"name"   validate:"required,valid_object_id,max=64"`
+	Scopes []string `json:"scopes" validate:"required,min=1,dive,oneof=admin read-only check-only warrants:write object-types:admin"`
+}
+
+func (spec CreateApiKeySpec) ToApiKey(keyHash string) (*ApiKey, error) {
+	scopes, err := json.Marshal(spec.Scopes)
+	if err != nil {
+		return nil, service.NewInvalidParameterError("scopes", "invalid format")
+	}
+
+	return &ApiKey{
+		Name:    spec.Name,
+		KeyHash: keyHash,
+		Scopes:  string(scopes),
+	}, nil
+}
@@ -0,0 +1,193 @@
+// Copyright 2024 WorkOS, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package apikey
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/warrant-dev/warrant/pkg/database"
+	"github.com/warrant-dev/warrant/pkg/service"
+)
+
+type SQLiteRepository struct {
+	database.SQLRepository
+}
+
+func NewSQLiteRepository(db *database.SQLite) *SQLiteRepository {
+	return &SQLiteRepository{
+		database.NewSQLRepository(&db.SQL),
+	}
+}
+
+func (repo SQLiteRepository) Create(ctx context.Context, model Model) (int64, error) {
+	now := time.Now().UTC()
+	var newApiKeyId int64
+	err := repo.DB.GetContext(
+		database.CtxWithWriterOverride(ctx),
+		&newApiKeyId,
+		`
+			INSERT INTO apiKey (
+				name,
+				keyHash,
+				scopes,
+				createdAt,
+				updatedAt
+			) VALUES (?, ?, ?, ?, ?)
+			RETURNING id
+		`,
+		model.GetName(),
+		model.GetKeyHash(),
+		model.GetScopes(),
+		now,
+		now,
+	)
+	if err != nil {
+		return -1, errors.Wrap(err, "error creating api key")
+	}
+
+	return newApiKeyId, nil
+}
+
+func (repo SQLiteRepository) GetById(ctx context.Context, id int64) (Model, error) {
+	var apiKey ApiKey
+	err := repo.DB.GetContext(
+		ctx,
+		&apiKey,
+		`
+			SELECT id, name, keyHash, scopes, createdAt, updatedAt
+			FROM apiKey
+			WHERE id = ?
+		`,
+		id,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, service.NewRecordNotFoundError("ApiKey", id)
+		}
+		return nil, errors.Wrapf(err, "error getting api key %d", id)
+	}
+
+	return &apiKey, nil
+}
+
+func (repo SQLiteRepository) GetByName(ctx context.Context, name string) (Model, error) {
+	var apiKey ApiKey
+	err := repo.DB.GetContext(
+		ctx,
+		&apiKey,
+		`
+			SELECT id, name, keyHash, scopes, createdAt, updatedAt
+			FROM apiKey
+			WHERE name = ?
+		`,
+		name,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, service.NewRecordNotFoundError("ApiKey", name)
+		}
+		return nil, errors.Wrapf(err, "error getting api key %s", name)
+	}
+
+	return &apiKey, nil
+}
+
+func (repo SQLiteRepository) GetByKeyHash(ctx context.Context, keyHash string) (Model, error) {
+	var apiKey ApiKey
+	err := repo.DB.GetContext(
+		ctx,
+		&apiKey,
+		`
+			SELECT id, name, keyHash, scopes, createdAt, updatedAt
+			FROM apiKey
+			WHERE keyHash = ?
+		`,
+		keyHash,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, service.NewRecordNotFoundError("ApiKey", "")
+		}
+		return nil, errors.Wrap(err, "error getting api key by hash")
+	}
+
+	return &apiKey, nil
+}
+
+func (repo SQLiteRepository) List(ctx context.Context) ([]Model, error) {
+	models := make([]Model, 0)
+	apiKeys := make([]ApiKey, 0)
+	err := repo.DB.SelectContext(
+		ctx,
+		&apiKeys,
+		`
+			SELECT id, name, keyHash, scopes, createdAt, updatedAt
+			FROM apiKey
+			ORDER BY name ASC
+		`,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models, nil
+		}
+		return nil, errors.Wrap(err, "error listing api keys")
+	}
+
+	for i := range apiKeys {
+		models = append(models, &apiKeys[i])
+	}
+
+	return models, nil
+}
+
+func (repo SQLiteRepository) UpdateKeyHashByName(ctx context.Context, name string, keyHash string) error {
+	_, err := repo.DB.ExecContext(
+		ctx,
+		`
+			UPDATE apiKey
+			SET
+				keyHash = ?,
+				updatedAt = ?
+			WHERE name = ?
+		`,
+		keyHash,
+		time.Now().UTC(),
+		name,
+	)
+	if err != nil {
+		return errors.Wrapf(err, "error updating api key %s", name)
+	}
+
+	return nil
+}
+
+func (repo SQLiteRepository) DeleteByName(ctx context.Context, name string) error {
+	_, err := repo.DB.ExecContext(
+		ctx,
+		`
+			DELETE FROM apiKey
+			WHERE name = ?
+		`,
+		name,
+	)
+	if err != nil {
+		return errors.Wrapf(err, "error deleting api key %s", name)
+	}
+
+	return nil
+}
@@ -0,0 +1,58 @@
+// Copyright 2024 WorkOS, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auditlog
+
+import (
+	"net/http"
+
+	"github.com/warrant-dev/warrant/pkg/service"
+)
+
+func (svc AuditLogService) Routes() ([]service.Route, error) {
+	return []service.Route{
+		// list
+		service.WarrantRoute{
+			Pattern: "/v2/audit-logs",
+			Method:  "GET",
+			Handler: service.ChainMiddleware(
+				service.NewRouteHandler(svc, listHandler),
+				service.ListMiddleware[AuditLogListParamParser],
+			),
+		},
+	}, nil
+}
+
+func listHandler(svc AuditLogService, w http.ResponseWriter, r *http.Request) error {
+	listParams := service.GetListParamsFromContext[AuditLogListParamParser](r.Context())
+	queryParams := r.URL.Query()
+	filterParams := FilterParams{
+		Type:      queryParams.Get("type"),
+		ActorType: queryParams.Get("actorType"),
+		ActorId:   queryParams.Get("actorId"),
+		RequestId: queryParams.Get("requestId"),
+	}
+
+	auditLogSpecs, prevCursor, nextCursor, err := svc.List(r.Context(), filterParams, listParams)
+	if err != nil {
+		return err
+	}
+
+	service.SendJSONResponse(w, ListAuditLogsSpec{
+		Results:    auditLogSpecs,
+		PrevCursor: prevCursor,
+		NextCursor: nextCursor,
+	})
+	return nil
+}
@@ -0,0 +1,115 @@
+// Copyright 2024 WorkOS, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auditlog
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type Model interface {
+	GetID() int64
+	GetType() string
+	GetActorType() string
+	GetActorId() string
+	GetRequestId() string
+	GetClientIp() string
+	GetMethod() string
+	GetPath() string
+	GetBeforeData() *string
+	GetAfterData() *string
+	GetCreatedAt() time.Time
+	ToAuditLogSpec() *AuditLogSpec
+}
+
+type AuditLog struct {
+	ID         int64     `mysql:"id"         postgres:"id"          sqlite:"id"`
+	Type       string    `mysql:"type"       postgres:"type"        sqlite:"type"`
+	ActorType  string    `mysql:"actorType"  postgres:"actor_type"  sqlite:"actorType"`
+	ActorId    string    `mysql:"actorId"    postgres:"actor_id"    sqlite:"actorId"`
+	RequestId  string    `mysql:"requestId"  postgres:"request_id"  sqlite:"requestId"`
+	ClientIp   string    `mysql:"clientIp"   postgres:"client_ip"   sqlite:"clientIp"`
+	Method     string    `mysql:"method"     postgres:"method"      sqlite:"method"`
+	Path       string    `mysql:"path"       postgres:"path"        sqlite:"path"`
+	BeforeData *string   `mysql:"beforeData" postgres:"before_data" sqlite:"beforeData"`
+	AfterData  *string   `mysql:"afterData"  postgres:"after_data"  sqlite:"afterData"`
+	CreatedAt  time.Time `mysql:"createdAt"  postgres:"created_at"  sqlite:"createdAt"`
+}
+
+func (auditLog AuditLog) GetID() int64 {
+	return auditLog.ID
+}
+
+func (auditLog AuditLog) GetType() string {
+	return auditLog.Type
+}
+
+func (auditLog AuditLog) GetActorType() string {
+	return auditLog.ActorType
+}
+
+func (auditLog AuditLog) GetActorId() string {
+	return auditLog.ActorId
+}
+
+func (auditLog AuditLog) GetRequestId() string {
+	return auditLog.RequestId
+}
+
+func (auditLog AuditLog) GetClientIp() string {
+	return auditLog.ClientIp
+}
+
+func (auditLog AuditLog) GetMethod() string {
+	return auditLog.Method
+}
+
+func (auditLog AuditLog) GetPath() string {
+	return auditLog.Path
+}
+
+func (auditLog AuditLog) GetBeforeData() *string {
+	return auditLog.BeforeData
+}
+
+func (auditLog AuditLog) GetAfterData() *string {
+	return auditLog.AfterData
+}
+
+func (auditLog AuditLog) GetCreatedAt() time.Time {
+	return auditLog.CreatedAt
+}
+
+func (auditLog AuditLog) ToAuditLogSpec() *AuditLogSpec {
+	auditLogSpec := AuditLogSpec{
+		ID:        auditLog.ID,
+		Type:      auditLog.Type,
+		ActorType: auditLog.ActorType,
+		ActorId:   auditLog.ActorId,
+		RequestId: auditLog.RequestId,
+		ClientIp:  auditLog.ClientIp,
+		Method:    auditLog.Method,
+		Path:      auditLog.Path,
+		CreatedAt: auditLog.CreatedAt,
+	}
+	if auditLog.BeforeData != nil {
+		auditLogSpec.Before = json.RawMessage(*auditLog.BeforeData)
+	}
+	if auditLog.AfterData != nil {
+		auditLogSpec.After = json.RawMessage(*auditLog.AfterData)
+	}
+
+	return &auditLogSpec
+}
@@ -0,0 +1,104 @@
+// Copyright 2024 WorkOS, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auditlog
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/pkg/errors"
+	"github.com/warrant-dev/warrant/pkg/service"
+)
+
+type AuditLogService struct {
+	service.BaseService
+	repository AuditLogRepository
+}
+
+func NewService(env service.Env, repository AuditLogRepository) *AuditLogService {
+	return &AuditLogService{
+		BaseService: service.NewBaseService(env),
+		repository:  repository,
+	}
+}
+
+// RecordChange records a change in the audit log along with the actor and
+// request that made it. Since it's called within the transaction making the
+// change, a change is only committed if its audit log is too.
+func (svc AuditLogService) RecordChange(ctx context.Context, changeType string, before interface{}, after interface{}) error {
+	auditLog := AuditLog{
+		Type:      changeType,
+		ActorType: ActorTypeSystem,
+	}
+
+	if authInfo, err := service.GetAuthInfoFromRequestContext(ctx); err == nil {
+		if authInfo.UserId != "" {
+			auditLog.ActorType = ActorTypeUser
+			auditLog.ActorId = authInfo.UserId
+		} else {
+			auditLog.ActorType = ActorTypeApiKey
+			auditLog.ActorId = authInfo.ApiKeyId
+		}
+	}
+
+	if requestInfo, ok := service.GetRequestInfoFromContext(ctx); ok {
+		auditLog.RequestId = requestInfo.RequestId
+		auditLog.ClientIp = requestInfo.ClientIp
+		auditLog.Method = requestInfo.Method
+		auditLog.Path = requestInfo.Path
+	}
+
+	var err error
+	auditLog.BeforeData, err = marshalData(before)
+	if err != nil {
+		return errors.Wrapf(err, "error marshaling %s audit log", changeType)
+	}
+
+	auditLog.AfterData, err = marshalData(after)
+	if err != nil {
+		return errors.Wrapf(err, "error marshaling %s audit log", changeType)
+	}
+
+	_, err = svc.repository.Create(ctx, auditLog)
+	return err
+}
+
+func (svc AuditLogService) List(ctx context.Context, filterParams FilterParams, listParams service.ListParams) ([]AuditLogSpec, *service.Cursor, *service.Cursor, error) {
+	auditLogSpecs := make([]AuditLogSpec, 0)
+	auditLogs, prevCursor, nextCursor, err := svc.repository.List(ctx, filterParams, listParams)
+	if err != nil {
+		return auditLogSpecs, prevCursor, nextCursor, err
+	}
+
+	for _, auditLog := range auditLogs {
+		auditLogSpecs = append(auditLogSpecs, *auditLog.ToAuditLogSpec())
+	}
+
+	return auditLogSpecs, prevCursor, nextCursor, nil
+}
+
+func marshalData(data interface{}) (*string, error) {
+	if data == nil {
+		return nil, nil
+	}
+
+	marshaledData, err := json.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+
+	marshaledDataStr := string(marshaledData)
+	return &marshaledDataStr, nil
+}
@@ -0,0 +1,58 @@
+// Copyright 2024 WorkOS, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auditlog
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/warrant-dev/warrant/pkg/service"
+)
+
+const (
+	ActorTypeApiKey = "apiKey"
+	ActorTypeUser   = "user"
+	// Changes made outside of a request, like sweeping expired warrants.
+	ActorTypeSystem = "system"
+)
+
+type FilterParams struct {
+	Type      string `json:"type,omitempty"`
+	ActorType string `json:"actorType,omitempty"`
+	ActorId   string `json:"actorId,omitempty"`
+	RequestId string `json:"requestId,omitempty"`
+}
+
+type AuditLogSpec struct {
+	// NOTE: ID is required here for internal use.
+	// However, we don't return it to the client.
+	ID        int64           `json:"-"`
+	Type      string          `json:"type"`
+	ActorType string          `json:"actorType"`
+	ActorId   string          `json:"actorId"`
+	RequestId string          `json:"requestId"`
+	ClientIp  string          `json:"clientIp"`
+	Method    string          `json:"method"`
+	Path      string          `json:"path"`
+	Before    json.RawMessage `json:"before"`
+	After     json.RawMessage `json:"after"`
+	CreatedAt time.Time       `json:"createdAt"`
+}
+
+type ListAuditLogsSpec struct {
+	Results    []AuditLogSpec  `json:"results"`
+	PrevCursor *service.Cursor `json:"prevCursor,omitempty"`
+	NextCursor *service.Cursor `json:"nextCursor,omitempty"`
+}
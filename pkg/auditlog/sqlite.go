@@ -0,0 +1,188 @@
+// Copyright 2024 WorkOS, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auditlog
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/warrant-dev/warrant/pkg/database"
+	"github.com/warrant-dev/warrant/pkg/service"
+)
+
+type SQLiteRepository struct {
+	database.SQLRepository
+}
+
+func NewSQLiteRepository(db *database.SQLite) *SQLiteRepository {
+	return &SQLiteRepository{
+		database.NewSQLRepository(&db.SQL),
+	}
+}
+
+func (repo SQLiteRepository) Create(ctx context.Context, model Model) (int64, error) {
+	var newAuditLogId int64
+	err := repo.DB.GetContext(
+		ctx,
+		&newAuditLogId,
+		`
+			INSERT INTO auditLog (
+				type,
+				actorType,
+				actorId,
+				requestId,
+				clientIp,
+				method,
+				path,
+				beforeData,
+				afterData,
+				createdAt
+			) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+			RETURNING id
+		`,
+		model.GetType(),
+		model.GetActorType(),
+		model.GetActorId(),
+		model.GetRequestId(),
+		model.GetClientIp(),
+		model.GetMethod(),
+		model.GetPath(),
+		model.GetBeforeData(),
+		model.GetAfterData(),
+		time.Now().UTC(),
+	)
+	if err != nil {
+		return -1, errors.Wrap(err, "error creating audit log")
+	}
+
+	return newAuditLogId, nil
+}
+
+func (repo SQLiteRepository) List(ctx context.Context, filterParams FilterParams, listParams service.ListParams) ([]Model, *service.Cursor, *service.Cursor, error) {
+	models := make([]Model, 0)
+	auditLogs := make([]AuditLog, 0)
+	conditions := make([]string, 0)
+	replacements := make([]interface{}, 0)
+	query := `
+		SELECT id, type, actorType, actorId, requestId, clientIp, method, path, beforeData, afterData, createdAt
+		FROM auditLog
+	`
+
+	if filterParams.Type != "" {
+		conditions = append(conditions, "type = ?")
+		replacements = append(replacements, filterParams.Type)
+	}
+
+	if filterParams.ActorType != "" {
+		conditions = append(conditions, "actorType = ?")
+		replacements = append(replacements, filterParams.ActorType)
+	}
+
+	if filterParams.ActorId != "" {
+		conditions = append(conditions, "actorId = ?")
+		replacements = append(replacements, filterParams.ActorId)
+	}
+
+	if filterParams.RequestId != "" {
+		conditions = append(conditions, "requestId = ?")
+		replacements = append(replacements, filterParams.RequestId)
+	}
+
+	if listParams.NextCursor != nil {
+		comparisonOp := "<"
+		if listParams.SortOrder == service.SortOrderAsc {
+			comparisonOp = ">"
+		}
+
+		conditions = append(conditions, fmt.Sprintf("%s %s ?", PrimarySortKey, comparisonOp))
+		replacements = append(replacements, listParams.NextCursor.ID())
+	}
+
+	if listParams.PrevCursor != nil {
+		comparisonOp := ">"
+		if listParams.SortOrder == service.SortOrderAsc {
+			comparisonOp = "<"
+		}
+
+		conditions = append(conditions, fmt.Sprintf("%s %s ?", PrimarySortKey, comparisonOp))
+		replacements = append(replacements, listParams.PrevCursor.ID())
+	}
+
+	if len(conditions) > 0 {
+		query = fmt.Sprintf("%s WHERE %s", query, strings.Join(conditions, " AND "))
+	}
+
+	if listParams.PrevCursor != nil {
+		if listParams.SortOrder == service.SortOrderAsc {
+			query = fmt.Sprintf("%s ORDER BY %s %s LIMIT ?", query, PrimarySortKey, service.SortOrderDesc)
+		} else {
+			query = fmt.Sprintf("%s ORDER BY %s %s LIMIT ?", query, PrimarySortKey, service.SortOrderAsc)
+		}
+		replacements = append(replacements, listParams.Limit+1)
+		query = fmt.Sprintf("With result_set AS (%s) SELECT * FROM result_set ORDER BY %s %s", query, PrimarySortKey, listParams.SortOrder)
+	} else {
+		query = fmt.Sprintf("%s ORDER BY %s %s LIMIT ?", query, PrimarySortKey, listParams.SortOrder)
+		replacements = append(replacements, listParams.Limit+1)
+	}
+
+	err := repo.DB.SelectContext(
+		ctx,
+		&auditLogs,
+		query,
+		replacements...,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models, nil, nil, nil
+		}
+		return nil, nil, nil, errors.Wrap(err, "error listing audit logs")
+	}
+
+	if len(auditLogs) == 0 {
+		return models, nil, nil, nil
+	}
+
+	i := 0
+	if listParams.PrevCursor != nil && len(auditLogs) > listParams.Limit {
+		i = 1
+	}
+	for i < len(auditLogs) && len(models) < listParams.Limit {
+		models = append(models, &auditLogs[i])
+		i++
+	}
+
+	prevCursor := service.NewCursor(strconv.FormatInt(models[0].GetID(), 10), nil)
+	nextCursor := service.NewCursor(strconv.FormatInt(models[len(models)-1].GetID(), 10), nil)
+	if len(auditLogs) <= listParams.Limit {
+		if listParams.PrevCursor != nil {
+			return models, nil, nextCursor, nil
+		}
+
+		if listParams.NextCursor != nil {
+			return models, prevCursor, nil, nil
+		}
+
+		return models, nil, nil, nil
+	} else if listParams.PrevCursor == nil && listParams.NextCursor == nil {
+		return models, nil, nextCursor, nil
+	}
+
+	return models, prevCursor, nextCursor, nil
+}
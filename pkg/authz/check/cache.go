@@ -0,0 +1,268 @@
+// Copyright 2024 WorkOS, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package authz
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	objecttype "github.com/warrant-dev/warrant/pkg/authz/objecttype"
+	warrant "github.com/warrant-dev/warrant/pkg/authz/warrant"
+	"github.com/warrant-dev/warrant/pkg/stats"
+)
+
+const checkCacheStore = "checkCache"
+
+type checkCacheKey struct{}
+
+// Request-scoped cache of the warrants, object types, and sub-check results
+// read while evaluating one or more checks. Only successful reads are cached.
+type checkCache struct {
+	mutex       sync.RWMutex
+	warrants    map[string][]warrant.WarrantSpec
+	objectTypes map[string]*objecttype.ObjectTypeSpec
+	checks      map[string]*checkCacheEntry
+	readTypes   map[string]struct{}
+	waitingOn   map[string]map[string]int
+	// Earliest expiration of the warrants read, nil if none expire
+	warrantsExpireAt *time.Time
+
+	generationOnce sync.Once
+	generation     uint64
+}
+
+// A sub-check that is either in-flight or completed. done is closed once
+// res is set. ok is false if the sub-check failed or was canceled.
+type checkCacheEntry struct {
+	done chan struct{}
+	res  result
+	ok   bool
+}
+
+func newCheckCache() *checkCache {
+	return &checkCache{
+		warrants:    make(map[string][]warrant.WarrantSpec),
+		objectTypes: make(map[string]*objecttype.ObjectTypeSpec),
+		checks:      make(map[string]*checkCacheEntry),
+		readTypes:   make(map[string]struct{}),
+		waitingOn:   make(map[string]map[string]int),
+	}
+}
+
+func (c *checkCache) getWarrants(ctx context.Context, filterParams warrant.FilterParams) ([]warrant.WarrantSpec, bool) {
+	start := time.Now()
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	warrantSpecs, ok := c.warrants[filterParams.String()]
+	recordCacheStat(ctx, "warrants", ok, start)
+	return warrantSpecs, ok
+}
+
+func (c *checkCache) setWarrants(filterParams warrant.FilterParams, warrantSpecs []warrant.WarrantSpec) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.warrants[filterParams.String()] = warrantSpecs
+	for _, warrantSpec := range warrantSpecs {
+		if warrantSpec.ExpiresAt != nil && (c.warrantsExpireAt == nil || warrantSpec.ExpiresAt.Before(*c.warrantsExpireAt)) {
+			c.warrantsExpireAt = warrantSpec.ExpiresAt
+		}
+	}
+}
+
+func (c *checkCache) getObjectType(ctx context.Context, typeId string) (*objecttype.ObjectTypeSpec, bool) {
+	start := time.Now()
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	objectTypeSpec, ok := c.objectTypes[typeId]
+	recordCacheStat(ctx, "objectType", ok, start)
+	return objectTypeSpec, ok
+}
+
+func (c *checkCache) setObjectType(typeId string, objectTypeSpec *objecttype.ObjectTypeSpec) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.objectTypes[typeId] = objectTypeSpec
+}
+
+// Records that the warrants or definition of the given object type were read.
+func (c *checkCache) addReadObjectType(typeId string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.readTypes[typeId] = struct{}{}
+}
+
+// Returns the object types read so far.
+func (c *checkCache) readObjectTypes() []string {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	objectTypes := make([]string, 0, len(c.readTypes))
+	for objectType := range c.readTypes {
+		objectTypes = append(objectTypes, objectType)
+	}
+	return objectTypes
+}
+
+// Returns the earliest expiration of the warrants read so far, or nil if none
+// of them expire. Results computed from the warrants can change once any of
+// them expires, whether it granted, denied, or excluded access.
+func (c *checkCache) readWarrantsExpireAt() *time.Time {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	return c.warrantsExpireAt
+}
+
+// Returns the resultCache generation from before anything was read into this
+// cache, calling currentGeneration to get it on first use.
+func (c *checkCache) resultCacheGeneration(currentGeneration func() uint64) uint64 {
+	c.generationOnce.Do(func() {
+		c.generation = currentGeneration()
+	})
+	return c.generation
+}
+
+// Returns the result of the sub-check identified by key, calling checkFunc to
+// compute it only if the same sub-check is not already completed or in-flight.
+// Callers waiting on an in-flight sub-check that fails or is canceled compute
+// the result themselves, as do callers whose ancestors the in-flight sub-check
+// is itself waiting on, since waiting would deadlock. Results computed with a
+// pruned cycle depend on the path they were computed on and are not reused.
+func (c *checkCache) doCheck(ctx context.Context, key string, ancestors []string, checkFunc func() result) result {
+	start := time.Now()
+	for {
+		c.mutex.Lock()
+		entry, exists := c.checks[key]
+		if !exists {
+			entry = &checkCacheEntry{
+				done: make(chan struct{}),
+			}
+			c.checks[key] = entry
+			c.mutex.Unlock()
+			recordCacheStat(ctx, "check", false, start)
+
+			entry.res = checkFunc()
+			entry.ok = entry.res.Err == nil && !entry.res.Pruned && entry.res.DepthErr == nil
+			if !entry.ok {
+				c.mutex.Lock()
+				delete(c.checks, key)
+				c.mutex.Unlock()
+			}
+			close(entry.done)
+			return entry.res
+		}
+
+		if c.isWaitingOn(key, ancestors) {
+			c.mutex.Unlock()
+			recordCacheStat(ctx, "check", false, start)
+			return checkFunc()
+		}
+		c.addWaitingOn(ancestors, key)
+		c.mutex.Unlock()
+
+		var res *result
+		select {
+		case <-entry.done:
+			if entry.ok {
+				res = &entry.res
+			}
+		case <-ctx.Done():
+			res = &result{
+				Matched: false,
+				Err:     ctx.Err(),
+			}
+		}
+
+		c.mutex.Lock()
+		c.removeWaitingOn(ancestors, key)
+		c.mutex.Unlock()
+		if res != nil {
+			if res.Err == nil {
+				recordCacheStat(ctx, "check", true, start)
+			}
+			return *res
+		}
+	}
+}
+
+// Returns true if the in-flight sub-check identified by key is (transitively)
+// waiting on any of the given sub-checks. Must be called with mutex held.
+func (c *checkCache) isWaitingOn(key string, keys []string) bool {
+	targets := make(map[string]struct{}, len(keys))
+	for _, k := range keys {
+		targets[k] = struct{}{}
+	}
+
+	seen := map[string]struct{}{key: {}}
+	queue := []string{key}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		if _, ok := targets[current]; ok {
+			return true
+		}
+		for next := range c.waitingOn[current] {
+			if _, ok := seen[next]; !ok {
+				seen[next] = struct{}{}
+				queue = append(queue, next)
+			}
+		}
+	}
+	return false
+}
+
+// Records that each of the given in-flight sub-checks is waiting on the
+// sub-check identified by key. Must be called with mutex held.
+func (c *checkCache) addWaitingOn(keys []string, key string) {
+	for _, k := range keys {
+		if _, ok := c.waitingOn[k]; !ok {
+			c.waitingOn[k] = make(map[string]int)
+		}
+		c.waitingOn[k][key]++
+	}
+}
+
+// Must be called with mutex held.
+func (c *checkCache) removeWaitingOn(keys []string, key string) {
+	for _, k := range keys {
+		c.waitingOn[k][key]--
+		if c.waitingOn[k][key] == 0 {
+			delete(c.waitingOn[k], key)
+		}
+		if len(c.waitingOn[k]) == 0 {
+			delete(c.waitingOn, k)
+		}
+	}
+}
+
+func recordCacheStat(ctx context.Context, tag string, hit bool, start time.Time) {
+	if hit {
+		stats.RecordStat(ctx, checkCacheStore, tag+".hit", start)
+	} else {
+		stats.RecordStat(ctx, checkCacheStore, tag+".miss", start)
+	}
+}
+
+// Returns a context containing the given checkCache.
+func contextWithCheckCache(parent context.Context, cache *checkCache) context.Context {
+	return context.WithValue(parent, checkCacheKey{}, cache)
+}
+
+// Get checkCache from ctx, if present.
+func getCheckCacheFromContext(ctx context.Context) *checkCache {
+	if cache, ok := ctx.Value(checkCacheKey{}).(*checkCache); ok {
+		return cache
+	}
+	return nil
+}
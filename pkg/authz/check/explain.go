@@ -0,0 +1,197 @@
+// Copyright 2024 WorkOS, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package authz
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	objecttype "github.com/warrant-dev/warrant/pkg/authz/objecttype"
+	warrant "github.com/warrant-dev/warrant/pkg/authz/warrant"
+)
+
+type explanationKey struct{}
+
+// A node of the tree of sub-checks, groups, and rules evaluated by an explained
+// check. Nodes are built concurrently as the check is evaluated, so all access
+// goes through the node's mutex.
+type explanation struct {
+	mutex        sync.Mutex
+	spec         ExplanationSpec
+	children     []*explanation
+	hasResult    bool
+	shortCircuit bool
+}
+
+func (e *explanation) addChild(child *explanation) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	e.children = append(e.children, child)
+}
+
+func (e *explanation) addWarrants(warrantSpecs []warrant.WarrantSpec) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	e.spec.Warrants = append(e.spec.Warrants, warrantSpecs...)
+}
+
+func (e *explanation) addPolicyEvaluation(policyEvaluation PolicyEvaluationSpec) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	e.spec.Policies = append(e.spec.Policies, policyEvaluation)
+}
+
+func (e *explanation) setResult(res result) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	if e.hasResult {
+		return
+	}
+
+	e.hasResult = true
+	switch {
+	case res.Err != nil:
+		e.spec.Result = ExplanationResultError
+		e.spec.Error = res.Err.Error()
+	case res.Matched:
+		e.spec.Result = ExplanationResultMatched
+	default:
+		e.spec.Result = ExplanationResultNotMatched
+	}
+}
+
+func (e *explanation) setCycle() {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	e.hasResult = true
+	e.spec.Result = ExplanationResultCycle
+}
+
+func (e *explanation) setShortCircuit() {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	e.shortCircuit = true
+}
+
+// Returns a snapshot of the tree rooted at this node. Nodes still being
+// evaluated when the snapshot is taken are marked as canceled.
+func (e *explanation) toSpec() *ExplanationSpec {
+	e.mutex.Lock()
+	spec := e.spec
+	spec.ShortCircuit = e.shortCircuit
+	if !e.hasResult {
+		spec.Result = ExplanationResultCanceled
+	}
+	children := make([]*explanation, len(e.children))
+	copy(children, e.children)
+	e.mutex.Unlock()
+
+	for _, child := range children {
+		spec.Children = append(spec.Children, child.toSpec())
+	}
+
+	// Children are added as they start evaluating, so order them deterministically
+	sort.SliceStable(spec.Children, func(i, j int) bool {
+		if spec.Children[i].Type != spec.Children[j].Type {
+			return spec.Children[i].Type < spec.Children[j].Type
+		}
+		return spec.Children[i].sortKey() < spec.Children[j].sortKey()
+	})
+	return &spec
+}
+
+func (spec ExplanationSpec) sortKey() string {
+	if spec.Check != nil {
+		return spec.Check.String()
+	}
+	return spec.InheritIf + "#" + spec.OfType + "#" + spec.WithRelation
+}
+
+func newCheckExplanation(checkSpec CheckWarrantSpec) *explanation {
+	return &explanation{
+		spec: ExplanationSpec{
+			Type:  ExplanationTypeCheck,
+			Check: &checkSpec,
+		},
+	}
+}
+
+func newGroupExplanation() *explanation {
+	return &explanation{
+		spec: ExplanationSpec{
+			Type: ExplanationTypeGroup,
+		},
+	}
+}
+
+func newDenyExplanation() *explanation {
+	return &explanation{
+		spec: ExplanationSpec{
+			Type: ExplanationTypeDeny,
+		},
+	}
+}
+
+func newRuleExplanation(rule *objecttype.RelationRule) *explanation {
+	e := &explanation{
+		spec: ExplanationSpec{
+			Type: ExplanationTypeRule,
+		},
+	}
+	if rule != nil {
+		e.spec.InheritIf = rule.InheritIf
+		e.spec.OfType = rule.OfType
+		e.spec.WithRelation = rule.WithRelation
+	}
+	return e
+}
+
+// If the check in ctx is being explained, adds e as a child of the current
+// explanation node. Returns a context with e as the current node and a channel
+// that records the result sent to it on e before passing it on to resultC.
+func startExplanation(ctx context.Context, resultC chan<- result, e *explanation) (context.Context, chan<- result) {
+	parent := getExplanationFromContext(ctx)
+	if parent == nil {
+		return ctx, resultC
+	}
+
+	parent.addChild(e)
+	explainedResultC := make(chan result, 1)
+	go func() {
+		select {
+		case res := <-explainedResultC:
+			e.setResult(res)
+			res.explanation = e
+			resultC <- res
+		case <-ctx.Done():
+			return
+		}
+	}()
+	return contextWithExplanation(ctx, e), explainedResultC
+}
+
+// Returns a context containing the given explanation node.
+func contextWithExplanation(parent context.Context, e *explanation) context.Context {
+	return context.WithValue(parent, explanationKey{}, e)
+}
+
+// Get the current explanation node from ctx, if present.
+func getExplanationFromContext(ctx context.Context) *explanation {
+	if e, ok := ctx.Value(explanationKey{}).(*explanation); ok {
+		return e
+	}
+	return nil
+}
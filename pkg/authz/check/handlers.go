@@ -70,15 +70,10 @@ func authorizeHandler(svc CheckService, w http.ResponseWriter, r *http.Request)
 			Warrants: warrantSpecs,
 			Context:  sessionCheckManySpec.Context,
 			Debug:    sessionCheckManySpec.Debug,
+			Explain:  sessionCheckManySpec.Explain,
 		}
 
-		checkResult, err := svc.CheckMany(r.Context(), authInfo, &checkManySpec)
-		if err != nil {
-			return err
-		}
-
-		service.SendJSONResponse(w, checkResult)
-		return nil
+		return sendCheckResponse(svc, w, r, authInfo, &checkManySpec)
 	}
 
 	var checkManySpec CheckManySpec
@@ -87,7 +82,21 @@ func authorizeHandler(svc CheckService, w http.ResponseWriter, r *http.Request)
 		return err
 	}
 
-	checkResult, err := svc.CheckMany(r.Context(), authInfo, &checkManySpec)
+	return sendCheckResponse(svc, w, r, authInfo, &checkManySpec)
+}
+
+func sendCheckResponse(svc CheckService, w http.ResponseWriter, r *http.Request, authInfo *service.AuthInfo, checkManySpec *CheckManySpec) error {
+	if checkManySpec.Op == CheckOpBatch {
+		checkResults, err := svc.CheckBatch(r.Context(), authInfo, checkManySpec)
+		if err != nil {
+			return err
+		}
+
+		service.SendJSONResponse(w, checkResults)
+		return nil
+	}
+
+	checkResult, err := svc.CheckMany(r.Context(), authInfo, checkManySpec)
 	if err != nil {
 		return err
 	}
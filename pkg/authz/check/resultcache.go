@@ -0,0 +1,156 @@
+// Copyright 2024 WorkOS, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package authz
+
+import (
+	"container/list"
+	"sync"
+	"time"
+
+	warrant "github.com/warrant-dev/warrant/pkg/authz/warrant"
+)
+
+type cachedCheckResult struct {
+	Matched      bool
+	DecisionPath []warrant.WarrantSpec
+	IsImplicit   bool
+}
+
+type resultCacheEntry struct {
+	key         string
+	res         cachedCheckResult
+	objectTypes []string
+	expiresAt   time.Time
+}
+
+// In-process LRU cache of check results shared across requests. Each entry
+// tracks the object types read while computing it so that writes to an object
+// type only evict the results they could affect.
+type resultCache struct {
+	mutex        sync.Mutex
+	size         int
+	ttl          time.Duration
+	generation   uint64
+	entries      map[string]*list.Element
+	evictionList *list.List
+	keysByType   map[string]map[string]struct{}
+}
+
+func newResultCache(size int, ttl time.Duration) *resultCache {
+	return &resultCache{
+		size:         size,
+		ttl:          ttl,
+		entries:      make(map[string]*list.Element),
+		evictionList: list.New(),
+		keysByType:   make(map[string]map[string]struct{}),
+	}
+}
+
+// Returns the current generation of the cache. Results computed before an
+// invalidation are not stored, so callers pass the generation read before
+// computing a result to set.
+func (c *resultCache) currentGeneration() uint64 {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.generation
+}
+
+func (c *resultCache) get(key string) (cachedCheckResult, bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	elem, ok := c.entries[key]
+	if !ok {
+		return cachedCheckResult{}, false
+	}
+
+	entry := elem.Value.(*resultCacheEntry)
+	if time.Now().After(entry.expiresAt) {
+		c.remove(elem)
+		return cachedCheckResult{}, false
+	}
+
+	c.evictionList.MoveToFront(elem)
+	return entry.res, true
+}
+
+func (c *resultCache) set(key string, res cachedCheckResult, objectTypes []string, warrantsExpireAt *time.Time, generation uint64) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if generation != c.generation || c.size <= 0 {
+		return
+	}
+
+	if elem, ok := c.entries[key]; ok {
+		c.remove(elem)
+	}
+
+	// A result can't outlive any of the warrants it was computed from
+	expiresAt := time.Now().Add(c.ttl)
+	if warrantsExpireAt != nil && warrantsExpireAt.Before(expiresAt) {
+		expiresAt = *warrantsExpireAt
+	}
+
+	elem := c.evictionList.PushFront(&resultCacheEntry{
+		key:         key,
+		res:         res,
+		objectTypes: objectTypes,
+		expiresAt:   expiresAt,
+	})
+	c.entries[key] = elem
+	for _, objectType := range objectTypes {
+		if _, ok := c.keysByType[objectType]; !ok {
+			c.keysByType[objectType] = make(map[string]struct{})
+		}
+		c.keysByType[objectType][key] = struct{}{}
+	}
+
+	for c.evictionList.Len() > c.size {
+		c.remove(c.evictionList.Back())
+	}
+}
+
+// Evicts all results computed from the given object types. If no object types
+// are given, all results are evicted.
+func (c *resultCache) invalidate(objectTypes ...string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.generation++
+	if len(objectTypes) == 0 {
+		c.entries = make(map[string]*list.Element)
+		c.evictionList.Init()
+		c.keysByType = make(map[string]map[string]struct{})
+		return
+	}
+
+	for _, objectType := range objectTypes {
+		for key := range c.keysByType[objectType] {
+			if elem, ok := c.entries[key]; ok {
+				c.remove(elem)
+			}
+		}
+	}
+}
+
+func (c *resultCache) remove(elem *list.Element) {
+	entry := elem.Value.(*resultCacheEntry)
+	c.evictionList.Remove(elem)
+	delete(c.entries, entry.key)
+	for _, objectType := range entry.objectTypes {
+		delete(c.keysByType[objectType], entry.key)
+		if len(c.keysByType[objectType]) == 0 {
+			delete(c.keysByType, objectType)
+		}
+	}
+}
@@ -0,0 +1,97 @@
+// Copyright 2024 WorkOS, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package authz
+
+import (
+	"testing"
+	"time"
+)
+
+func TestResultCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	t.Parallel()
+	cache := newResultCache(2, time.Minute)
+	cache.set("a", cachedCheckResult{Matched: true}, []string{"report"}, nil, cache.currentGeneration())
+	cache.set("b", cachedCheckResult{Matched: true}, []string{"report"}, nil, cache.currentGeneration())
+	if _, ok := cache.get("a"); !ok {
+		t.Fatalf("expected a to be cached")
+	}
+
+	cache.set("c", cachedCheckResult{Matched: true}, []string{"report"}, nil, cache.currentGeneration())
+	if _, ok := cache.get("b"); ok {
+		t.Fatalf("expected b to be evicted")
+	}
+	if _, ok := cache.get("a"); !ok {
+		t.Fatalf("expected a to be cached")
+	}
+	if _, ok := cache.get("c"); !ok {
+		t.Fatalf("expected c to be cached")
+	}
+}
+
+func TestResultCacheExpiresEntries(t *testing.T) {
+	t.Parallel()
+	cache := newResultCache(2, time.Millisecond)
+	cache.set("a", cachedCheckResult{Matched: true}, []string{"report"}, nil, cache.currentGeneration())
+	time.Sleep(5 * time.Millisecond)
+	if _, ok := cache.get("a"); ok {
+		t.Fatalf("expected a to be expired")
+	}
+}
+
+func TestResultCacheInvalidatesByObjectType(t *testing.T) {
+	t.Parallel()
+	cache := newResultCache(10, time.Minute)
+	cache.set("a", cachedCheckResult{Matched: true}, []string{"report", "role"}, nil, cache.currentGeneration())
+	cache.set("b", cachedCheckResult{Matched: false}, []string{"report"}, nil, cache.currentGeneration())
+	cache.set("c", cachedCheckResult{Matched: false}, []string{"document"}, nil, cache.currentGeneration())
+
+	cache.invalidate("role")
+	if _, ok := cache.get("a"); ok {
+		t.Fatalf("expected a to be invalidated")
+	}
+	if _, ok := cache.get("b"); !ok {
+		t.Fatalf("expected b to be cached")
+	}
+
+	cache.invalidate()
+	if _, ok := cache.get("b"); ok {
+		t.Fatalf("expected b to be invalidated")
+	}
+	if _, ok := cache.get("c"); ok {
+		t.Fatalf("expected c to be invalidated")
+	}
+}
+
+func TestResultCacheIgnoresResultsFromBeforeInvalidation(t *testing.T) {
+	t.Parallel()
+	cache := newResultCache(10, time.Minute)
+	generation := cache.currentGeneration()
+	cache.invalidate("report")
+	cache.set("a", cachedCheckResult{Matched: true}, []string{"report"}, nil, generation)
+	if _, ok := cache.get("a"); ok {
+		t.Fatalf("expected stale result to not be cached")
+	}
+}
+
+func TestResultCacheExpiresEntriesWithExpiringWarrants(t *testing.T) {
+	t.Parallel()
+	cache := newResultCache(2, time.Minute)
+	expiresAt := time.Now().Add(time.Millisecond)
+	cache.set("a", cachedCheckResult{Matched: false}, []string{"report"}, &expiresAt, cache.currentGeneration())
+	time.Sleep(5 * time.Millisecond)
+	if _, ok := cache.get("a"); ok {
+		t.Fatalf("expected a to be expired")
+	}
+}
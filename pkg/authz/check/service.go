@@ -18,16 +18,24 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"slices"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/pkg/errors"
 	"github.com/rs/zerolog/log"
 	objecttype "github.com/warrant-dev/warrant/pkg/authz/objecttype"
 	warrant "github.com/warrant-dev/warrant/pkg/authz/warrant"
+	whatif "github.com/warrant-dev/warrant/pkg/authz/whatif"
 	"github.com/warrant-dev/warrant/pkg/config"
+	"github.com/warrant-dev/warrant/pkg/decisionlog"
+	"github.com/warrant-dev/warrant/pkg/metrics"
 	"github.com/warrant-dev/warrant/pkg/service"
 	"github.com/warrant-dev/warrant/pkg/stats"
 	"github.com/warrant-dev/warrant/pkg/wookie"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
 const (
@@ -42,13 +50,20 @@ type CheckService struct {
 	objectTypeSvc      objecttype.Service
 	checkConfig        *config.CheckConfig
 	createCheckContext CheckContextFunc
+	resultCache        *resultCache
+	decisionLogger     *decisionlog.DecisionLogger
 }
 
 func defaultCreateCheckContext(ctx context.Context) (context.Context, error) {
 	checkCtx := stats.BlankContextWithRequestStats(ctx)
+	// Keep the request's span so check spans are part of the request's trace
+	checkCtx = trace.ContextWithSpan(checkCtx, trace.SpanFromContext(ctx))
 	if wookie.ContainsLatest(ctx) {
 		return wookie.WithLatest(checkCtx), nil
 	}
+	if token, ok := wookie.GetTokenFromContext(ctx); ok {
+		return wookie.WithToken(checkCtx, *token), nil
+	}
 	return checkCtx, nil
 }
 
@@ -65,41 +80,74 @@ func NewService(env service.Env, warrantSvc warrant.Service, objectTypeSvc objec
 		svc.createCheckContext = defaultCreateCheckContext
 	}
 
+	if checkConfig.Cache != nil && checkConfig.Cache.Enabled {
+		svc.resultCache = newResultCache(checkConfig.Cache.Size, checkConfig.Cache.TTL)
+	}
+
 	return svc
 }
 
+// SetDecisionLogger sets the DecisionLogger that check decisions are logged to.
+func (svc *CheckService) SetDecisionLogger(decisionLogger *decisionlog.DecisionLogger) {
+	svc.decisionLogger = decisionLogger
+}
+
+// InvalidateCache evicts cached check results computed from the given object types, or all cached check results if none are given.
+func (svc CheckService) InvalidateCache(ctx context.Context, objectTypes ...string) {
+	if svc.resultCache != nil {
+		svc.resultCache.invalidate(objectTypes...)
+	}
+}
+
+// Returns a copy of svc that evaluates checks as if the changes in whatIfSpec
+// were persisted. Its results are never cached across requests.
+func (svc CheckService) withWhatIf(whatIfSpec *whatif.WhatIfSpec) (CheckService, error) {
+	whatIfWarrantSvc, err := whatif.NewWarrantService(svc.warrantSvc, *whatIfSpec)
+	if err != nil {
+		return svc, err
+	}
+
+	whatIfObjectTypeSvc, err := whatif.NewObjectTypeService(svc.objectTypeSvc, *whatIfSpec)
+	if err != nil {
+		return svc, err
+	}
+
+	svc.warrantSvc = whatIfWarrantSvc
+	svc.objectTypeSvc = whatIfObjectTypeSvc
+	svc.resultCache = nil
+	return svc, nil
+}
+
 func (svc CheckService) getWithPolicyMatch(ctx context.Context, checkPipeline *pipeline, spec CheckWarrantSpec) (*warrant.WarrantSpec, error) {
 	checkPipeline.AcquireServiceLock()
 	defer checkPipeline.ReleaseServiceLock()
 
-	listParams := service.DefaultListParams(warrant.WarrantListParamParser{})
-	listParams.WithLimit(MaxWarrants)
-	warrantSpecs, _, _, err := svc.warrantSvc.List(
-		ctx,
-		warrant.FilterParams{
-			ObjectType:      spec.ObjectType,
-			ObjectId:        spec.ObjectId,
-			Relation:        spec.Relation,
-			SubjectType:     spec.Subject.ObjectType,
-			SubjectId:       spec.Subject.ObjectId,
-			SubjectRelation: spec.Subject.Relation,
-		},
-		listParams,
-	)
+	warrantSpecs, err := svc.listWarrants(ctx, warrant.FilterParams{
+		ObjectType:      spec.ObjectType,
+		ObjectId:        spec.ObjectId,
+		Relation:        spec.Relation,
+		SubjectType:     spec.Subject.ObjectType,
+		SubjectId:       spec.Subject.ObjectId,
+		SubjectRelation: spec.Subject.Relation,
+	})
 	if err != nil || len(warrantSpecs) == 0 {
 		return nil, err
 	}
 
+	if e := getExplanationFromContext(ctx); e != nil {
+		e.addWarrants(warrantSpecs)
+	}
+
 	// if a warrant without a policy is found, match it
 	for _, w := range warrantSpecs {
-		if w.Policy == "" {
+		if w.Policy == "" && !w.IsDeny() {
 			return &w, nil
 		}
 	}
 
 	for _, w := range warrantSpecs {
-		if w.Policy != "" {
-			if policyMatched := evalWarrantPolicy(w, spec.Context); policyMatched {
+		if w.Policy != "" && !w.IsDeny() {
+			if policyMatched := evalWarrantPolicy(ctx, w, spec.Context); policyMatched {
 				return &w, nil
 			}
 		}
@@ -113,7 +161,7 @@ func (svc CheckService) getMatchingSubjects(ctx context.Context, checkPipeline *
 	defer checkPipeline.ReleaseServiceLock()
 
 	warrantSpecs := make([]warrant.WarrantSpec, 0)
-	objectTypeSpec, err := svc.objectTypeSvc.GetByTypeId(ctx, objectType)
+	objectTypeSpec, err := svc.getObjectType(ctx, objectType)
 	if err != nil {
 		return warrantSpecs, err
 	}
@@ -122,27 +170,25 @@ func (svc CheckService) getMatchingSubjects(ctx context.Context, checkPipeline *
 		return warrantSpecs, nil
 	}
 
-	listParams := service.DefaultListParams(warrant.WarrantListParamParser{})
-	listParams.WithLimit(MaxWarrants)
-	warrantSpecs, _, _, err = svc.warrantSvc.List(
-		ctx,
-		warrant.FilterParams{
-			ObjectType: objectType,
-			ObjectId:   objectId,
-			Relation:   relation,
-		},
-		listParams,
-	)
+	warrantSpecs, err = svc.listWarrants(ctx, warrant.FilterParams{
+		ObjectType: objectType,
+		ObjectId:   objectId,
+		Relation:   relation,
+	})
 	if err != nil {
 		return warrantSpecs, err
 	}
 
 	matchingSpecs := make([]warrant.WarrantSpec, 0)
 	for _, w := range warrantSpecs {
+		if w.IsDeny() {
+			continue
+		}
+
 		if w.Policy == "" {
 			matchingSpecs = append(matchingSpecs, w)
 		} else {
-			if policyMatched := evalWarrantPolicy(w, checkCtx); policyMatched {
+			if policyMatched := evalWarrantPolicy(ctx, w, checkCtx); policyMatched {
 				matchingSpecs = append(matchingSpecs, w)
 			}
 		}
@@ -157,7 +203,7 @@ func (svc CheckService) getMatchingSubjectsBySubjectType(ctx context.Context, ch
 	defer checkPipeline.ReleaseServiceLock()
 
 	warrantSpecs := make([]warrant.WarrantSpec, 0)
-	objectTypeSpec, err := svc.objectTypeSvc.GetByTypeId(ctx, objectType)
+	objectTypeSpec, err := svc.getObjectType(ctx, objectType)
 	if err != nil {
 		return warrantSpecs, err
 	}
@@ -166,28 +212,26 @@ func (svc CheckService) getMatchingSubjectsBySubjectType(ctx context.Context, ch
 		return warrantSpecs, nil
 	}
 
-	listParams := service.DefaultListParams(warrant.WarrantListParamParser{})
-	listParams.WithLimit(MaxWarrants)
-	warrantSpecs, _, _, err = svc.warrantSvc.List(
-		ctx,
-		warrant.FilterParams{
-			ObjectType:  objectType,
-			ObjectId:    objectId,
-			Relation:    relation,
-			SubjectType: subjectType,
-		},
-		listParams,
-	)
+	warrantSpecs, err = svc.listWarrants(ctx, warrant.FilterParams{
+		ObjectType:  objectType,
+		ObjectId:    objectId,
+		Relation:    relation,
+		SubjectType: subjectType,
+	})
 	if err != nil {
 		return warrantSpecs, err
 	}
 
 	matchingSpecs := make([]warrant.WarrantSpec, 0)
 	for _, w := range warrantSpecs {
+		if w.IsDeny() {
+			continue
+		}
+
 		if w.Policy == "" {
 			matchingSpecs = append(matchingSpecs, w)
 		} else {
-			if policyMatched := evalWarrantPolicy(w, checkCtx); policyMatched {
+			if policyMatched := evalWarrantPolicy(ctx, w, checkCtx); policyMatched {
 				matchingSpecs = append(matchingSpecs, w)
 			}
 		}
@@ -196,27 +240,134 @@ func (svc CheckService) getMatchingSubjectsBySubjectType(ctx context.Context, ch
 	return matchingSpecs, nil
 }
 
+// Returns the deny warrants on the checked object and relation whose policies match the check context.
+func (svc CheckService) getDenyWarrants(ctx context.Context, checkPipeline *pipeline, spec CheckWarrantSpec) ([]warrant.WarrantSpec, error) {
+	checkPipeline.AcquireServiceLock()
+	defer checkPipeline.ReleaseServiceLock()
+
+	warrantSpecs, err := svc.listWarrants(ctx, warrant.FilterParams{
+		ObjectType: spec.ObjectType,
+		ObjectId:   spec.ObjectId,
+		Relation:   spec.Relation,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	denyWarrants := make([]warrant.WarrantSpec, 0)
+	for _, w := range warrantSpecs {
+		if !w.IsDeny() {
+			continue
+		}
+
+		if w.Policy == "" || evalWarrantPolicy(ctx, w, spec.Context) {
+			denyWarrants = append(denyWarrants, w)
+		}
+	}
+
+	return denyWarrants, nil
+}
+
+func (svc CheckService) listWarrants(ctx context.Context, filterParams warrant.FilterParams) ([]warrant.WarrantSpec, error) {
+	// Expired warrants are ignored even if they haven't been swept yet
+	expired := false
+	filterParams.Expired = &expired
+
+	cache := getCheckCacheFromContext(ctx)
+	if cache != nil {
+		cache.addReadObjectType(filterParams.ObjectType)
+		if warrantSpecs, ok := cache.getWarrants(ctx, filterParams); ok {
+			return warrantSpecs, nil
+		}
+	}
+
+	listParams := service.DefaultListParams(warrant.WarrantListParamParser{})
+	listParams.WithLimit(MaxWarrants)
+	warrantSpecs, _, _, err := svc.warrantSvc.List(ctx, filterParams, listParams)
+	if err != nil {
+		return warrantSpecs, err
+	}
+
+	if cache != nil {
+		cache.setWarrants(filterParams, warrantSpecs)
+	}
+
+	return warrantSpecs, nil
+}
+
+func (svc CheckService) getObjectType(ctx context.Context, typeId string) (*objecttype.ObjectTypeSpec, error) {
+	cache := getCheckCacheFromContext(ctx)
+	if cache != nil {
+		cache.addReadObjectType(typeId)
+		if objectTypeSpec, ok := cache.getObjectType(ctx, typeId); ok {
+			return objectTypeSpec, nil
+		}
+	}
+
+	objectTypeSpec, err := svc.objectTypeSvc.GetByTypeId(ctx, typeId)
+	if err != nil {
+		return nil, err
+	}
+
+	if cache != nil {
+		cache.setObjectType(typeId, objectTypeSpec)
+	}
+
+	return objectTypeSpec, nil
+}
+
 func (svc CheckService) CheckMany(ctx context.Context, authInfo *service.AuthInfo, warrantCheck *CheckManySpec) (*CheckResultSpec, error) {
+	start := time.Now()
+	checkResult, err := svc.checkMany(ctx, authInfo, warrantCheck)
+	observeCheckResult(checkResult, err)
+	svc.logDecision(ctx, warrantCheck, checkResult, err, time.Since(start))
+	return checkResult, err
+}
+
+func (svc CheckService) checkMany(ctx context.Context, authInfo *service.AuthInfo, warrantCheck *CheckManySpec) (*CheckResultSpec, error) {
 	start := time.Now().UTC()
 	if warrantCheck.Op != "" && warrantCheck.Op != objecttype.InheritIfAllOf && warrantCheck.Op != objecttype.InheritIfAnyOf {
-		return nil, service.NewInvalidParameterError("op", "must be either anyOf or allOf")
+		return nil, service.NewInvalidParameterError("op", "must be one of anyOf, allOf, or batch")
+	}
+
+	if warrantCheck.WhatIf != nil {
+		whatIfSvc, err := svc.withWhatIf(warrantCheck.WhatIf)
+		if err != nil {
+			return nil, err
+		}
+
+		svc = whatIfSvc
+		ctx = contextWithCheckCache(ctx, newCheckCache())
+	}
+
+	// Share warrants, object types, and sub-check results across each check
+	if getCheckCacheFromContext(ctx) == nil {
+		ctx = contextWithCheckCache(ctx, newCheckCache())
 	}
 
 	var checkResult CheckResultSpec
 	checkResult.DecisionPath = make(map[string][]warrant.WarrantSpec, 0)
+	if warrantCheck.Explain {
+		checkResult.Explanation = make(map[string]*ExplanationSpec, 0)
+	}
 
 	if warrantCheck.Op == objecttype.InheritIfAllOf {
 		var processingTime int64
 		var isImplicit bool
 		for _, warrantSpec := range warrantCheck.Warrants {
-			match, decisionPath, implicit, err := svc.Check(ctx, authInfo, CheckSpec{
+			match, decisionPath, implicit, explanation, err := svc.explainCheck(ctx, authInfo, CheckSpec{
 				CheckWarrantSpec: warrantSpec,
 				Debug:            warrantCheck.Debug,
+				Explain:          warrantCheck.Explain,
 			})
 			if err != nil {
 				return nil, err
 			}
 
+			if explanation != nil {
+				checkResult.Explanation[warrantSpec.String()] = explanation
+			}
+
 			isImplicit = isImplicit || implicit
 			if warrantCheck.Debug {
 				checkResult.ProcessingTime = processingTime + time.Since(start).Milliseconds()
@@ -241,15 +392,31 @@ func (svc CheckService) CheckMany(ctx context.Context, authInfo *service.AuthInf
 
 	if warrantCheck.Op == objecttype.InheritIfAnyOf {
 		var processingTime int64
+		numDepthErrs := 0
 		for _, warrantSpec := range warrantCheck.Warrants {
-			match, decisionPath, isImplicit, err := svc.Check(ctx, authInfo, CheckSpec{
+			match, decisionPath, isImplicit, explanation, err := svc.explainCheck(ctx, authInfo, CheckSpec{
 				CheckWarrantSpec: warrantSpec,
 				Debug:            warrantCheck.Debug,
+				Explain:          warrantCheck.Explain,
 			})
+			// As with anyOf rules, a warrant whose check exceeded the max depth
+			// is not matched unless every warrant's check exceeded it
+			var maxDepthExceededError *service.MaxDepthExceededError
+			if errors.As(err, &maxDepthExceededError) {
+				numDepthErrs++
+				if numDepthErrs == len(warrantCheck.Warrants) {
+					return nil, err
+				}
+				continue
+			}
 			if err != nil {
 				return nil, err
 			}
 
+			if explanation != nil {
+				checkResult.Explanation[warrantSpec.String()] = explanation
+			}
+
 			if warrantCheck.Debug {
 				checkResult.ProcessingTime = processingTime + time.Since(start).Milliseconds()
 				if len(decisionPath) > 0 {
@@ -276,14 +443,19 @@ func (svc CheckService) CheckMany(ctx context.Context, authInfo *service.AuthInf
 	}
 
 	warrantSpec := warrantCheck.Warrants[0]
-	match, decisionPath, isImplicit, err := svc.Check(ctx, authInfo, CheckSpec{
+	match, decisionPath, isImplicit, explanation, err := svc.explainCheck(ctx, authInfo, CheckSpec{
 		CheckWarrantSpec: warrantSpec,
 		Debug:            warrantCheck.Debug,
+		Explain:          warrantCheck.Explain,
 	})
 	if err != nil {
 		return nil, err
 	}
 
+	if explanation != nil {
+		checkResult.Explanation[warrantSpec.String()] = explanation
+	}
+
 	if warrantCheck.Debug {
 		checkResult.ProcessingTime = time.Since(start).Milliseconds()
 		if len(decisionPath) > 0 {
@@ -304,8 +476,155 @@ func (svc CheckService) CheckMany(ctx context.Context, authInfo *service.AuthInf
 	return &checkResult, nil
 }
 
+// CheckBatch concurrently evaluates each of the given warrants and returns a result for each, in the order they were provided.
+// A warrant whose check fails doesn't fail the batch. Its result has the error's status code and the error instead.
+func (svc CheckService) CheckBatch(ctx context.Context, authInfo *service.AuthInfo, warrantCheck *CheckManySpec) ([]CheckResultSpec, error) {
+	if warrantCheck.Op != CheckOpBatch {
+		return nil, service.NewInvalidParameterError("op", "must be batch")
+	}
+
+	if warrantCheck.WhatIf != nil {
+		whatIfSvc, err := svc.withWhatIf(warrantCheck.WhatIf)
+		if err != nil {
+			return nil, err
+		}
+
+		svc = whatIfSvc
+	}
+
+	// Share warrants & object types read by each check across the entire batch
+	batchCtx := contextWithCheckCache(ctx, newCheckCache())
+	checkResults := make([]CheckResultSpec, len(warrantCheck.Warrants))
+	semaphore := make(chan struct{}, svc.checkConfig.Concurrency)
+	var wg sync.WaitGroup
+	for i, warrantSpec := range warrantCheck.Warrants {
+		wg.Add(1)
+		semaphore <- struct{}{}
+		go func(i int, warrantSpec CheckWarrantSpec) {
+			defer func() {
+				if err := recover(); err != nil {
+					checkResults[i] = checkErrorResult(ctx, errors.New(fmt.Sprintf("check: panic: %v", err)))
+				}
+				<-semaphore
+				wg.Done()
+			}()
+
+			start := time.Now().UTC()
+			itemCheck := &CheckManySpec{
+				Op:       CheckOpBatch,
+				Warrants: []CheckWarrantSpec{warrantSpec},
+			}
+			match, decisionPath, isImplicit, explanation, err := svc.explainCheck(batchCtx, authInfo, CheckSpec{
+				CheckWarrantSpec: warrantSpec,
+				Debug:            warrantCheck.Debug,
+				Explain:          warrantCheck.Explain,
+			})
+			if err != nil {
+				checkResults[i] = checkErrorResult(ctx, err)
+				observeCheckResult(nil, err)
+				svc.logDecision(ctx, itemCheck, nil, err, time.Since(start))
+				return
+			}
+
+			checkResult := CheckResultSpec{
+				Code:       http.StatusForbidden,
+				Result:     NotAuthorized,
+				IsImplicit: false,
+			}
+			if match {
+				checkResult.Code = http.StatusOK
+				checkResult.Result = Authorized
+				checkResult.IsImplicit = isImplicit
+			}
+			if warrantCheck.Debug {
+				checkResult.ProcessingTime = time.Since(start).Milliseconds()
+				if len(decisionPath) > 0 {
+					checkResult.DecisionPath = map[string][]warrant.WarrantSpec{
+						warrantSpec.String(): decisionPath,
+					}
+				}
+			}
+			if explanation != nil {
+				checkResult.Explanation = map[string]*ExplanationSpec{
+					warrantSpec.String(): explanation,
+				}
+			}
+			checkResults[i] = checkResult
+			observeCheckResult(&checkResult, nil)
+			svc.logDecision(ctx, itemCheck, &checkResult, nil, time.Since(start))
+		}(i, warrantSpec)
+	}
+	wg.Wait()
+
+	return checkResults, nil
+}
+
+// Returns the result of a batch check that failed with err. Errors other than
+// API errors are logged and reported as internal errors.
+func checkErrorResult(ctx context.Context, err error) CheckResultSpec {
+	var apiError service.Error
+	if !errors.As(err, &apiError) {
+		log.Ctx(ctx).Error().Err(err).Msg("check: error evaluating batch check")
+		apiError = service.NewInternalError("Internal Server Error")
+	}
+
+	return CheckResultSpec{
+		Code:   int64(apiError.GetStatus()),
+		Result: CheckError,
+		Error:  apiError,
+	}
+}
+
+// Counts the result of a check in the check results metric.
+func observeCheckResult(checkResult *CheckResultSpec, err error) {
+	switch {
+	case err != nil || checkResult == nil:
+		metrics.IncCheckResults(metrics.CheckResultError)
+	case checkResult.Result == Authorized:
+		metrics.IncCheckResults(metrics.CheckResultAuthorized)
+	default:
+		metrics.IncCheckResults(metrics.CheckResultNotAuthorized)
+	}
+}
+
+// Logs the decision made for warrantCheck if a DecisionLogger is set.
+func (svc CheckService) logDecision(ctx context.Context, warrantCheck *CheckManySpec, checkResult *CheckResultSpec, err error, latency time.Duration) {
+	if svc.decisionLogger == nil {
+		return
+	}
+
+	subjects := make([]string, 0)
+	for _, warrantSpec := range warrantCheck.Warrants {
+		if warrantSpec.Subject != nil && !slices.Contains(subjects, warrantSpec.Subject.String()) {
+			subjects = append(subjects, warrantSpec.Subject.String())
+		}
+	}
+
+	entry := decisionlog.Entry{
+		Type:      decisionlog.TypeCheck,
+		Subject:   strings.Join(subjects, ", "),
+		Request:   warrantCheck,
+		LatencyMs: float64(latency.Microseconds()) / 1000,
+	}
+	if checkResult != nil {
+		entry.Result = checkResult.Result
+		entry.IsImplicit = checkResult.IsImplicit
+	}
+	if err != nil {
+		entry.Error = err.Error()
+	}
+
+	svc.decisionLogger.Log(ctx, entry)
+}
+
 // Check returns true if the subject has a warrant (explicitly or implicitly) for given objectType:objectId#relation and context.
 func (svc CheckService) Check(ctx context.Context, authInfo *service.AuthInfo, warrantCheck CheckSpec) (bool, []warrant.WarrantSpec, bool, error) {
+	match, decisionPath, isImplicit, _, err := svc.explainCheck(ctx, authInfo, warrantCheck)
+	return match, decisionPath, isImplicit, err
+}
+
+// explainCheck is Check, additionally returning the tree of evaluated sub-checks and rules if warrantCheck.Explain is set.
+func (svc CheckService) explainCheck(ctx context.Context, authInfo *service.AuthInfo, warrantCheck CheckSpec) (bool, []warrant.WarrantSpec, bool, *ExplanationSpec, error) {
 	// Used to automatically append tenant context for session token w/ tenantId checks
 	if authInfo != nil && authInfo.TenantId != "" {
 		if warrantCheck.CheckWarrantSpec.Context == nil {
@@ -317,12 +636,45 @@ func (svc CheckService) Check(ctx context.Context, authInfo *service.AuthInfo, w
 		}
 	}
 
+	cache := getCheckCacheFromContext(ctx)
+	if warrantCheck.WhatIf != nil {
+		whatIfSvc, err := svc.withWhatIf(warrantCheck.WhatIf)
+		if err != nil {
+			return false, nil, false, nil, err
+		}
+
+		svc = whatIfSvc
+		cache = nil
+	}
+	if cache == nil {
+		cache = newCheckCache()
+	}
+
+	// Unless the latest data is requested, use a cached result if one exists
+	var cacheGeneration uint64
+	useResultCache := svc.resultCache != nil && !wookie.ContainsLatest(ctx) && !warrantCheck.Explain
+	if useResultCache {
+		start := time.Now()
+		cachedResult, ok := svc.resultCache.get(warrantCheck.CheckWarrantSpec.String())
+		recordCacheStat(ctx, "result", ok, start)
+		if ok {
+			return cachedResult.Matched, cachedResult.DecisionPath, cachedResult.IsImplicit, nil, nil
+		}
+		cacheGeneration = cache.resultCacheGeneration(svc.resultCache.currentGeneration)
+	}
+
 	resultsC := make(chan result, 1)
 	pipeline := NewPipeline(svc.checkConfig.Concurrency, svc.checkConfig.MaxConcurrency)
 
 	checkCtx, err := svc.createCheckContext(ctx)
 	if err != nil {
-		return false, nil, false, err
+		return false, nil, false, nil, err
+	}
+	checkCtx = contextWithCheckCache(checkCtx, cache)
+	var root *explanation
+	if warrantCheck.Explain {
+		root = &explanation{}
+		checkCtx = contextWithExplanation(checkCtx, root)
 	}
 	childCtx, cancelFunc := context.WithTimeout(checkCtx, svc.checkConfig.Timeout)
 	defer cancelFunc()
@@ -337,29 +689,162 @@ func (svc CheckService) Check(ctx context.Context, authInfo *service.AuthInfo, w
 			}
 		}()
 
-		svc.check(0, pipeline, childCtx, warrantCheck, make([]warrant.WarrantSpec, 0), resultsC)
+		svc.check(0, pipeline, childCtx, warrantCheck, make([]warrant.WarrantSpec, 0), nil, resultsC)
 	}()
 
-	result := <-resultsC
+	var res result
+	select {
+	case res = <-resultsC:
+	case <-childCtx.Done():
+		return false, nil, false, nil, errors.Wrap(childCtx.Err(), "check: evaluation did not complete")
+	}
 
-	if result.Err != nil {
-		return false, nil, false, result.Err
+	if res.Err != nil {
+		return false, nil, false, nil, res.Err
 	}
 
-	if result.Matched {
-		return true, result.DecisionPath, len(result.DecisionPath) != 1 || result.DecisionPath[0].Relation != warrantCheck.Relation, nil
+	var checkResult cachedCheckResult
+	if res.Matched {
+		checkResult = cachedCheckResult{
+			Matched:      true,
+			DecisionPath: res.DecisionPath,
+			IsImplicit:   len(res.DecisionPath) != 1 || res.DecisionPath[0].Relation != warrantCheck.Relation,
+		}
+	}
+
+	if useResultCache {
+		svc.resultCache.set(warrantCheck.CheckWarrantSpec.String(), checkResult, cache.readObjectTypes(), cache.readWarrantsExpireAt(), cacheGeneration)
+	}
+
+	var explanationSpec *ExplanationSpec
+	if root != nil {
+		if rootSpec := root.toSpec(); len(rootSpec.Children) > 0 {
+			explanationSpec = rootSpec.Children[0]
+		}
 	}
 
-	return false, nil, false, nil
+	return checkResult.Matched, checkResult.DecisionPath, checkResult.IsImplicit, explanationSpec, nil
 }
 
 type result struct {
 	Matched      bool
 	DecisionPath []warrant.WarrantSpec
-	Err          error
+	// Set if a cycle was pruned while computing the result, making it specific to the path it was computed on
+	Pruned bool
+	// Set if the result is not matched but a branch treated as not matched
+	// exceeded the max depth, so the result may have matched with a higher max depth
+	DepthErr error
+	Err      error
+	// The explanation node of the result, if the check is being explained
+	explanation *explanation
+}
+
+// The sub-checks visited along the path to the current sub-check
+type checkPath struct {
+	key    string
+	depth  int
+	parent *checkPath
 }
 
-func (svc CheckService) check(level int, checkPipeline *pipeline, ctx context.Context, checkSpec CheckSpec, currentPath []warrant.WarrantSpec, resultC chan<- result) {
+func (p *checkPath) push(key string) *checkPath {
+	return &checkPath{
+		key:    key,
+		depth:  p.getDepth() + 1,
+		parent: p,
+	}
+}
+
+func (p *checkPath) getDepth() int {
+	if p == nil {
+		return 0
+	}
+	return p.depth
+}
+
+func (p *checkPath) contains(key string) bool {
+	for node := p; node != nil; node = node.parent {
+		if node.key == key {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *checkPath) keys() []string {
+	keys := make([]string, 0, p.getDepth())
+	for node := p; node != nil; node = node.parent {
+		keys = append(keys, node.key)
+	}
+	return keys
+}
+
+func (svc CheckService) check(level int, checkPipeline *pipeline, ctx context.Context, checkSpec CheckSpec, currentPath []warrant.WarrantSpec, visited *checkPath, resultC chan<- result) {
+	ctx, resultC = startCheckSpan(ctx, resultC, "check.check",
+		attribute.Int("check.level", level),
+		attribute.String("check.spec", checkSpec.String()),
+	)
+	ctx, resultC = startExplanation(ctx, resultC, newCheckExplanation(checkSpec.CheckWarrantSpec))
+	key := checkSpec.String()
+	if visited.contains(key) {
+		log.Ctx(ctx).Debug().Msgf("pruned cycle check[%d] [%s]", level, checkSpec)
+		if e := getExplanationFromContext(ctx); e != nil {
+			e.setCycle()
+		}
+		resultC <- result{
+			Matched:      false,
+			DecisionPath: currentPath,
+			Pruned:       true,
+		}
+		return
+	}
+	metrics.ObserveCheckDepth(visited.getDepth())
+	if svc.checkConfig.MaxDepth > 0 && visited.getDepth() >= svc.checkConfig.MaxDepth {
+		resultC <- result{
+			Matched:      false,
+			DecisionPath: currentPath,
+			Err:          service.NewMaxDepthExceededError(svc.checkConfig.MaxDepth),
+		}
+		return
+	}
+
+	ancestors := visited.keys()
+	visited = visited.push(key)
+	cache := getCheckCacheFromContext(ctx)
+	// Explained checks are evaluated in full so that every sub-check appears in the explanation
+	if cache == nil || checkSpec.Explain {
+		svc.evalCheck(level, checkPipeline, ctx, checkSpec, currentPath, visited, resultC)
+		return
+	}
+
+	// Evaluate the sub-check independent of currentPath so its result can be reused by other paths
+	res := cache.doCheck(ctx, key, ancestors, func() result {
+		subResultC := make(chan result, 1)
+		svc.evalCheck(level, checkPipeline, ctx, checkSpec, make([]warrant.WarrantSpec, 0), visited, subResultC)
+		select {
+		case res := <-subResultC:
+			return res
+		case <-ctx.Done():
+			return result{
+				Matched: false,
+				Err:     ctx.Err(),
+			}
+		}
+	})
+
+	decisionPath := make([]warrant.WarrantSpec, 0, len(res.DecisionPath)+len(currentPath))
+	if res.Matched {
+		decisionPath = append(decisionPath, res.DecisionPath...)
+	}
+	resultC <- result{
+		Matched:      res.Matched,
+		DecisionPath: append(decisionPath, currentPath...),
+		Pruned:       res.Pruned,
+		DepthErr:     res.DepthErr,
+		Err:          res.Err,
+	}
+}
+
+func (svc CheckService) evalCheck(level int, checkPipeline *pipeline, ctx context.Context, checkSpec CheckSpec, currentPath []warrant.WarrantSpec, visited *checkPath, resultC chan<- result) {
 	select {
 	case <-ctx.Done():
 		log.Ctx(ctx).Debug().Msgf("canceled check[%d] [%s]", level, checkSpec)
@@ -370,7 +855,26 @@ func (svc CheckService) check(level int, checkPipeline *pipeline, ctx context.Co
 			log.Ctx(ctx).Debug().Msgf("exec check[%d] [%s] [%s]", level, checkSpec, time.Since(start))
 		}()
 
-		// 1. Check for direct warrant match
+		// 1. Check for deny warrants, which override any other match
+		denied, err := svc.checkDenied(level, checkPipeline, ctx, checkSpec, visited)
+		if err != nil {
+			resultC <- result{
+				Matched:      false,
+				DecisionPath: currentPath,
+				Err:          err,
+			}
+			return
+		}
+		if denied {
+			resultC <- result{
+				Matched:      false,
+				DecisionPath: currentPath,
+				Err:          nil,
+			}
+			return
+		}
+
+		// 2. Check for direct warrant match
 		matchedWarrant, err := svc.getWithPolicyMatch(ctx, checkPipeline, checkSpec.CheckWarrantSpec)
 		if err != nil {
 			resultC <- result{
@@ -390,14 +894,14 @@ func (svc CheckService) check(level int, checkPipeline *pipeline, ctx context.Co
 			return
 		}
 
-		// 2. Check through indirect/group warrants
+		// 3. Check through indirect/group warrants
 		var additionalTasks []func(execCtx context.Context, resultC chan<- result)
 		additionalTasks = append(additionalTasks, func(execCtx context.Context, resultC chan<- result) {
-			svc.checkGroup(level+1, checkPipeline, execCtx, checkSpec, currentPath, resultC)
+			svc.checkGroup(level+1, checkPipeline, execCtx, checkSpec, currentPath, visited, resultC)
 		})
 
-		// 3. And/or defined rules for target relation
-		objectTypeSpec, err := svc.objectTypeSvc.GetByTypeId(ctx, checkSpec.ObjectType)
+		// 4. And/or defined rules for target relation
+		objectTypeSpec, err := svc.getObjectType(ctx, checkSpec.ObjectType)
 		if err != nil {
 			resultC <- result{
 				Matched:      false,
@@ -408,7 +912,7 @@ func (svc CheckService) check(level int, checkPipeline *pipeline, ctx context.Co
 		}
 		if relationRule, ok := objectTypeSpec.Relations[checkSpec.Relation]; ok {
 			additionalTasks = append(additionalTasks, func(execCtx context.Context, resultC chan<- result) {
-				svc.checkRule(level+1, checkPipeline, execCtx, checkSpec, currentPath, resultC, &relationRule)
+				svc.checkRule(level+1, checkPipeline, execCtx, checkSpec, currentPath, visited, resultC, &relationRule)
 			})
 		}
 
@@ -416,7 +920,82 @@ func (svc CheckService) check(level int, checkPipeline *pipeline, ctx context.Co
 	}
 }
 
-func (svc CheckService) checkGroup(level int, checkPipeline *pipeline, ctx context.Context, checkSpec CheckSpec, currentPath []warrant.WarrantSpec, resultC chan<- result) {
+// Returns true if a deny warrant on the checked object and relation applies to
+// the subject, either directly or through a group the subject belongs to.
+func (svc CheckService) checkDenied(level int, checkPipeline *pipeline, ctx context.Context, checkSpec CheckSpec, visited *checkPath) (bool, error) {
+	denyWarrants, err := svc.getDenyWarrants(ctx, checkPipeline, checkSpec.CheckWarrantSpec)
+	if err != nil || len(denyWarrants) == 0 {
+		return false, err
+	}
+
+	resultC := make(chan result, 1)
+	denyCtx, denyResultC := startExplanation(ctx, resultC, newDenyExplanation())
+	if e := getExplanationFromContext(denyCtx); e != nil {
+		e.addWarrants(denyWarrants)
+	}
+
+	var directDenyWarrant *warrant.WarrantSpec
+	var tasks []func(execCtx context.Context, resultC chan<- result)
+	for _, w := range denyWarrants {
+		if w.Subject.ObjectType == checkSpec.Subject.ObjectType &&
+			(w.Subject.ObjectId == checkSpec.Subject.ObjectId || w.Subject.ObjectId == warrant.Wildcard) &&
+			w.Subject.Relation == checkSpec.Subject.Relation {
+			directDenyWarrant = &w
+			break
+		}
+
+		if w.Subject.Relation == "" {
+			continue
+		}
+
+		denyWarrant := w
+		tasks = append(tasks, func(execCtx context.Context, resultC chan<- result) {
+			svc.check(level+1, checkPipeline, execCtx, CheckSpec{
+				CheckWarrantSpec: CheckWarrantSpec{
+					ObjectType: denyWarrant.Subject.ObjectType,
+					ObjectId:   denyWarrant.Subject.ObjectId,
+					Relation:   denyWarrant.Subject.Relation,
+					Subject:    checkSpec.Subject,
+					Context:    checkSpec.Context,
+				},
+				Debug:   checkSpec.Debug,
+				Explain: checkSpec.Explain,
+			}, []warrant.WarrantSpec{denyWarrant}, visited, resultC)
+		})
+	}
+
+	switch {
+	case directDenyWarrant != nil:
+		denyResultC <- result{
+			Matched:      true,
+			DecisionPath: []warrant.WarrantSpec{*directDenyWarrant},
+		}
+	case len(tasks) > 0:
+		checkPipeline.AnyOf(denyCtx, denyResultC, tasks)
+	default:
+		denyResultC <- result{
+			Matched: false,
+		}
+	}
+
+	select {
+	case res := <-resultC:
+		// Fail closed if a deny warrant may have applied with a higher max depth
+		if !res.Matched && res.Err == nil && res.DepthErr != nil {
+			return false, res.DepthErr
+		}
+		return res.Matched, res.Err
+	case <-ctx.Done():
+		return false, ctx.Err()
+	}
+}
+
+func (svc CheckService) checkGroup(level int, checkPipeline *pipeline, ctx context.Context, checkSpec CheckSpec, currentPath []warrant.WarrantSpec, visited *checkPath, resultC chan<- result) {
+	ctx, resultC = startCheckSpan(ctx, resultC, "check.checkGroup",
+		attribute.Int("check.level", level),
+		attribute.String("check.spec", checkSpec.String()),
+	)
+	ctx, resultC = startExplanation(ctx, resultC, newGroupExplanation())
 	select {
 	case <-ctx.Done():
 		log.Ctx(ctx).Debug().Msgf("canceled checkGroup[%d] [%s]", level, checkSpec)
@@ -444,6 +1023,9 @@ func (svc CheckService) checkGroup(level int, checkPipeline *pipeline, ctx conte
 			}
 			matchingWarrants = append(matchingWarrants, w)
 		}
+		if e := getExplanationFromContext(ctx); e != nil {
+			e.addWarrants(matchingWarrants)
+		}
 		if len(matchingWarrants) == 0 {
 			resultC <- result{
 				Matched:      false,
@@ -464,15 +1046,22 @@ func (svc CheckService) checkGroup(level int, checkPipeline *pipeline, ctx conte
 						Subject:    checkSpec.Subject,
 						Context:    checkSpec.Context,
 					},
-					Debug: checkSpec.Debug,
-				}, append([]warrant.WarrantSpec{matchingWarrant}, currentPath...), resultC)
+					Debug:   checkSpec.Debug,
+					Explain: checkSpec.Explain,
+				}, append([]warrant.WarrantSpec{matchingWarrant}, currentPath...), visited, resultC)
 			})
 		}
 		checkPipeline.AnyOf(ctx, resultC, additionalTasks)
 	}
 }
 
-func (svc CheckService) checkRule(level int, checkPipeline *pipeline, ctx context.Context, checkSpec CheckSpec, currentPath []warrant.WarrantSpec, resultC chan<- result, rule *objecttype.RelationRule) {
+func (svc CheckService) checkRule(level int, checkPipeline *pipeline, ctx context.Context, checkSpec CheckSpec, currentPath []warrant.WarrantSpec, visited *checkPath, resultC chan<- result, rule *objecttype.RelationRule) {
+	ctx, resultC = startCheckSpan(ctx, resultC, "check.checkRule",
+		attribute.Int("check.level", level),
+		attribute.String("check.spec", checkSpec.String()),
+		ruleAttribute(rule),
+	)
+	ctx, resultC = startExplanation(ctx, resultC, newRuleExplanation(rule))
 	select {
 	case <-ctx.Done():
 		log.Ctx(ctx).Debug().Msgf("canceled checkRule[%d] [%s] [%s]", level, checkSpec, rule)
@@ -505,7 +1094,7 @@ func (svc CheckService) checkRule(level int, checkPipeline *pipeline, ctx contex
 			for _, r := range rule.Rules {
 				subRule := r
 				additionalTasks = append(additionalTasks, func(execCtx context.Context, resultC chan<- result) {
-					svc.checkRule(level+1, checkPipeline, execCtx, checkSpec, currentPath, resultC, &subRule)
+					svc.checkRule(level+1, checkPipeline, execCtx, checkSpec, currentPath, visited, resultC, &subRule)
 				})
 			}
 			checkPipeline.AllOf(ctx, resultC, additionalTasks)
@@ -514,7 +1103,7 @@ func (svc CheckService) checkRule(level int, checkPipeline *pipeline, ctx contex
 			for _, r := range rule.Rules {
 				subRule := r
 				additionalTasks = append(additionalTasks, func(execCtx context.Context, resultC chan<- result) {
-					svc.checkRule(level+1, checkPipeline, execCtx, checkSpec, currentPath, resultC, &subRule)
+					svc.checkRule(level+1, checkPipeline, execCtx, checkSpec, currentPath, visited, resultC, &subRule)
 				})
 			}
 			checkPipeline.AnyOf(ctx, resultC, additionalTasks)
@@ -523,7 +1112,7 @@ func (svc CheckService) checkRule(level int, checkPipeline *pipeline, ctx contex
 			for _, r := range rule.Rules {
 				subRule := r
 				additionalTasks = append(additionalTasks, func(execCtx context.Context, resultC chan<- result) {
-					svc.checkRule(level+1, checkPipeline, execCtx, checkSpec, currentPath, resultC, &subRule)
+					svc.checkRule(level+1, checkPipeline, execCtx, checkSpec, currentPath, visited, resultC, &subRule)
 				})
 			}
 			checkPipeline.NoneOf(ctx, resultC, additionalTasks)
@@ -537,8 +1126,9 @@ func (svc CheckService) checkRule(level int, checkPipeline *pipeline, ctx contex
 						Subject:    warrantSpec.Subject,
 						Context:    warrantSpec.Context,
 					},
-					Debug: checkSpec.Debug,
-				}, currentPath, resultC)
+					Debug:   checkSpec.Debug,
+					Explain: checkSpec.Explain,
+				}, currentPath, visited, resultC)
 				return
 			}
 
@@ -551,6 +1141,9 @@ func (svc CheckService) checkRule(level int, checkPipeline *pipeline, ctx contex
 				}
 				return
 			}
+			if e := getExplanationFromContext(ctx); e != nil {
+				e.addWarrants(matchingWarrants)
+			}
 			if len(matchingWarrants) == 0 {
 				resultC <- result{
 					Matched:      false,
@@ -571,8 +1164,9 @@ func (svc CheckService) checkRule(level int, checkPipeline *pipeline, ctx contex
 							Subject:    warrantSpec.Subject,
 							Context:    warrantSpec.Context,
 						},
-						Debug: checkSpec.Debug,
-					}, append([]warrant.WarrantSpec{matchingWarrant}, currentPath...), resultC)
+						Debug:   checkSpec.Debug,
+						Explain: checkSpec.Explain,
+					}, append([]warrant.WarrantSpec{matchingWarrant}, currentPath...), visited, resultC)
 				})
 			}
 			checkPipeline.AnyOf(ctx, resultC, additionalTasks)
@@ -593,7 +1187,12 @@ func NewPipeline(maxServiceConcurrency int, maxSubtaskConcurrency int) *pipeline
 }
 
 func (p *pipeline) AcquireServiceLock() {
-	p.serviceSemaphore <- struct{}{}
+	select {
+	case p.serviceSemaphore <- struct{}{}:
+	default:
+		metrics.IncCheckSemaphoreSaturated(metrics.SemaphoreService)
+		p.serviceSemaphore <- struct{}{}
+	}
 }
 
 func (p *pipeline) ReleaseServiceLock() {
@@ -601,7 +1200,26 @@ func (p *pipeline) ReleaseServiceLock() {
 }
 
 func (p *pipeline) AnyOf(ctx context.Context, parentResultC chan<- result, tasks []func(execCtx context.Context, resultC chan<- result)) {
+	numDepthErrs := 0
+	var depthErr error
 	p.execTasks(ctx, parentResultC, tasks, func(res result, isLastExpected bool) (*result, bool) {
+		// A branch that exceeded the max depth is treated as not matched since
+		// another branch may still match, unless every branch exceeded it
+		var maxDepthExceededError *service.MaxDepthExceededError
+		if errors.As(res.Err, &maxDepthExceededError) {
+			numDepthErrs++
+			if numDepthErrs == len(tasks) {
+				return &res, true
+			}
+			depthErr = res.Err
+			res = result{
+				Matched:      false,
+				DecisionPath: res.DecisionPath,
+			}
+		}
+		if res.Err != nil {
+			return &res, true
+		}
 		// Short-circuit - pick this result if it's a match
 		if res.Matched {
 			return &res, true
@@ -611,6 +1229,7 @@ func (p *pipeline) AnyOf(ctx context.Context, parentResultC chan<- result, tasks
 			return &result{
 				Matched:      false,
 				DecisionPath: res.DecisionPath,
+				DepthErr:     depthErr,
 				Err:          nil,
 			}, true
 		}
@@ -621,6 +1240,9 @@ func (p *pipeline) AnyOf(ctx context.Context, parentResultC chan<- result, tasks
 
 func (p *pipeline) AllOf(ctx context.Context, parentResultC chan<- result, tasks []func(execCtx context.Context, resultC chan<- result)) {
 	p.execTasks(ctx, parentResultC, tasks, func(res result, isLastExpected bool) (*result, bool) {
+		if res.Err != nil {
+			return &res, true
+		}
 		// Short-circuit - return not matched if any sub-result is not matched
 		if !res.Matched {
 			return &res, true
@@ -640,6 +1262,16 @@ func (p *pipeline) AllOf(ctx context.Context, parentResultC chan<- result, tasks
 
 func (p *pipeline) NoneOf(ctx context.Context, parentResultC chan<- result, tasks []func(execCtx context.Context, resultC chan<- result)) {
 	p.execTasks(ctx, parentResultC, tasks, func(res result, isLastExpected bool) (*result, bool) {
+		if res.Err != nil {
+			return &res, true
+		}
+		// An excluded branch may have matched with a higher max depth, so fail closed
+		if !res.Matched && res.DepthErr != nil {
+			return &result{
+				Matched: false,
+				Err:     res.DepthErr,
+			}, true
+		}
 		// Short-circuit - return not matched
 		if res.Matched {
 			return &result{
@@ -678,14 +1310,27 @@ func (p *pipeline) execTasks(ctx context.Context, parentResultC chan<- result, t
 		// Monitor task results, short-circuit as needed
 		defer childCtxCancelFunc()
 		resultsReceived := 0
+		pruned := false
+		var depthErr error
 		for result := range childResultC {
-			if result.Err != nil {
-				parentResultC <- result
-				return
-			}
 			resultsReceived++
 			r, returnResult := checkResultFunc(result, resultsReceived == len(tasks))
+			if returnResult && r.Err != nil {
+				parentResultC <- *r
+				return
+			}
+			pruned = pruned || result.Pruned
+			if result.DepthErr != nil {
+				depthErr = result.DepthErr
+			}
 			if returnResult {
+				if resultsReceived < len(tasks) && result.explanation != nil {
+					result.explanation.setShortCircuit()
+				}
+				r.Pruned = pruned
+				if !r.Matched && r.DepthErr == nil {
+					r.DepthErr = depthErr
+				}
 				parentResultC <- *r
 				return
 			}
@@ -694,9 +1339,11 @@ func (p *pipeline) execTasks(ctx context.Context, parentResultC chan<- result, t
 
 	for _, t := range tasks {
 		task := t
+		stats.RecordCheckSubtask(ctx)
 		// Exec each task on new goroutine unless at capacity. In that case, run task(s) locally
 		select {
 		case p.subtaskSemaphore <- struct{}{}:
+			metrics.IncCheckSubtasks(false)
 			go func() {
 				defer func() {
 					if err := recover(); err != nil {
@@ -711,12 +1358,13 @@ func (p *pipeline) execTasks(ctx context.Context, parentResultC chan<- result, t
 				task(childContext, childResultC)
 			}()
 		default:
+			metrics.IncCheckSubtasks(true)
 			task(childContext, childResultC)
 		}
 	}
 }
 
-func evalWarrantPolicy(w warrant.WarrantSpec, policyCtx warrant.PolicyContext) bool {
+func evalWarrantPolicy(ctx context.Context, w warrant.WarrantSpec, policyCtx warrant.PolicyContext) bool {
 	policyCtxWithWarrant := make(warrant.PolicyContext)
 	for k, v := range policyCtx {
 		policyCtxWithWarrant[k] = v
@@ -724,6 +1372,17 @@ func evalWarrantPolicy(w warrant.WarrantSpec, policyCtx warrant.PolicyContext) b
 	policyCtxWithWarrant["warrant"] = w
 
 	policyMatched, err := w.Policy.Eval(policyCtxWithWarrant)
+	if e := getExplanationFromContext(ctx); e != nil {
+		policyEvaluation := PolicyEvaluationSpec{
+			Warrant: w,
+			Context: policyCtx,
+			Result:  err == nil && policyMatched,
+		}
+		if err != nil {
+			policyEvaluation.Error = err.Error()
+		}
+		e.addPolicyEvaluation(policyEvaluation)
+	}
 	if err != nil {
 		log.Err(err).Msgf("check: error while evaluating policy %s", w.Policy)
 		return false
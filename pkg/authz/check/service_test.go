@@ -0,0 +1,235 @@
+// Copyright 2024 WorkOS, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package authz
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	objecttype "github.com/warrant-dev/warrant/pkg/authz/objecttype"
+	warrant "github.com/warrant-dev/warrant/pkg/authz/warrant"
+	"github.com/warrant-dev/warrant/pkg/config"
+	"github.com/warrant-dev/warrant/pkg/service"
+)
+
+func TestCachedResultDeniedByExpiringWarrantExpiresWithIt(t *testing.T) {
+	t.Parallel()
+	denyExpiresAt := time.Now().Add(100 * time.Millisecond)
+	warrantSvc := testWarrantService{
+		warrants: []warrant.WarrantSpec{
+			{
+				ObjectType: "document",
+				ObjectId:   "doc1",
+				Relation:   "viewer",
+				Subject:    &warrant.SubjectSpec{ObjectType: "user", ObjectId: "user1"},
+			},
+			{
+				ObjectType: "document",
+				ObjectId:   "doc1",
+				Relation:   "viewer",
+				Subject:    &warrant.SubjectSpec{ObjectType: "user", ObjectId: "user1"},
+				Effect:     warrant.EffectDeny,
+				ExpiresAt:  &denyExpiresAt,
+			},
+		},
+	}
+	objectTypeSvc := testObjectTypeService{
+		objectTypes: map[string]objecttype.ObjectTypeSpec{
+			"document": {
+				Type: "document",
+				Relations: map[string]objecttype.RelationRule{
+					"viewer": {},
+				},
+			},
+			"user": {
+				Type:      "user",
+				Relations: map[string]objecttype.RelationRule{},
+			},
+		},
+	}
+	checkSvc := NewService(nil, warrantSvc, objectTypeSvc, &config.CheckConfig{
+		Concurrency:    4,
+		MaxConcurrency: 1000,
+		Timeout:        time.Minute,
+		Cache: &config.CheckCacheConfig{
+			Enabled: true,
+			Size:    10,
+			TTL:     time.Minute,
+		},
+	}, nil)
+	check := CheckSpec{
+		CheckWarrantSpec: CheckWarrantSpec{
+			ObjectType: "document",
+			ObjectId:   "doc1",
+			Relation:   "viewer",
+			Subject:    &warrant.SubjectSpec{ObjectType: "user", ObjectId: "user1"},
+		},
+	}
+
+	match, _, _, err := checkSvc.Check(context.Background(), nil, check)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if match {
+		t.Fatalf("expected check to be denied")
+	}
+
+	time.Sleep(time.Until(denyExpiresAt) + 10*time.Millisecond)
+	match, _, _, err = checkSvc.Check(context.Background(), nil, check)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !match {
+		t.Fatalf("expected check to match once the deny warrant expired")
+	}
+}
+
+// Returns a task that sends res to its result channel after delay.
+func testTask(res result, delay time.Duration) func(execCtx context.Context, resultC chan<- result) {
+	return func(execCtx context.Context, resultC chan<- result) {
+		time.Sleep(delay)
+		resultC <- res
+	}
+}
+
+func runTasks(run func(ctx context.Context, parentResultC chan<- result, tasks []func(execCtx context.Context, resultC chan<- result)), tasks ...func(execCtx context.Context, resultC chan<- result)) result {
+	resultC := make(chan result, 1)
+	run(context.Background(), resultC, tasks)
+	return <-resultC
+}
+
+func TestAnyOfTreatsBranchesExceedingMaxDepthAsNotMatched(t *testing.T) {
+	t.Parallel()
+	p := NewPipeline(4, 1000)
+	depthErr := result{Err: service.NewMaxDepthExceededError(2)}
+	var maxDepthExceededError *service.MaxDepthExceededError
+
+	res := runTasks(p.AnyOf, testTask(depthErr, 0), testTask(result{Matched: true}, 20*time.Millisecond))
+	if res.Err != nil || !res.Matched {
+		t.Fatalf("expected a match from the branch within the max depth, got matched %t and error %v", res.Matched, res.Err)
+	}
+
+	res = runTasks(p.AnyOf, testTask(depthErr, 0), testTask(result{Matched: false}, 0))
+	if res.Err != nil || res.Matched {
+		t.Fatalf("expected no match, got matched %t and error %v", res.Matched, res.Err)
+	}
+	if !errors.As(res.DepthErr, &maxDepthExceededError) {
+		t.Fatalf("expected the not matched result to record that the max depth was exceeded")
+	}
+
+	res = runTasks(p.AnyOf, testTask(depthErr, 0), testTask(depthErr, 0))
+	if !errors.As(res.Err, &maxDepthExceededError) {
+		t.Fatalf("expected max depth error when every branch exceeded the max depth, got %v", res.Err)
+	}
+
+	res = runTasks(p.AnyOf, testTask(depthErr, 0), testTask(result{Err: errors.New("failed")}, 0))
+	if res.Err == nil || errors.As(res.Err, &maxDepthExceededError) {
+		t.Fatalf("expected other errors to fail the check, got %v", res.Err)
+	}
+}
+
+func TestNoneOfFailsWhenExcludedBranchExceededMaxDepth(t *testing.T) {
+	t.Parallel()
+	p := NewPipeline(4, 1000)
+	notMatchedPastMaxDepth := result{Matched: false, DepthErr: service.NewMaxDepthExceededError(2)}
+	var maxDepthExceededError *service.MaxDepthExceededError
+
+	res := runTasks(p.NoneOf, testTask(notMatchedPastMaxDepth, 0))
+	if !errors.As(res.Err, &maxDepthExceededError) || res.Matched {
+		t.Fatalf("expected max depth error, got matched %t and error %v", res.Matched, res.Err)
+	}
+}
+
+func TestCheckMatchesWhenAnotherBranchExceedsMaxDepth(t *testing.T) {
+	t.Parallel()
+	user := &warrant.SubjectSpec{ObjectType: "user", ObjectId: "user1"}
+	warrants := []warrant.WarrantSpec{
+		{
+			ObjectType: "document",
+			ObjectId:   "doc1",
+			Relation:   "editor",
+			Subject:    user,
+		},
+		{
+			ObjectType: "document",
+			ObjectId:   "doc1",
+			Relation:   "viewer",
+			Subject:    &warrant.SubjectSpec{ObjectType: "group", ObjectId: "group0", Relation: "member"},
+		},
+	}
+	// user1 is a member of group0 through a chain of groups deeper than the max depth
+	for i := 0; i < 10; i++ {
+		warrants = append(warrants, warrant.WarrantSpec{
+			ObjectType: "group",
+			ObjectId:   fmt.Sprintf("group%d", i),
+			Relation:   "member",
+			Subject:    &warrant.SubjectSpec{ObjectType: "group", ObjectId: fmt.Sprintf("group%d", i+1), Relation: "member"},
+		})
+	}
+	warrants = append(warrants, warrant.WarrantSpec{
+		ObjectType: "group",
+		ObjectId:   "group10",
+		Relation:   "member",
+		Subject:    user,
+	})
+	objectTypeSvc := testObjectTypeService{
+		objectTypes: map[string]objecttype.ObjectTypeSpec{
+			"document": {
+				Type: "document",
+				Relations: map[string]objecttype.RelationRule{
+					"editor": {},
+					"viewer": {
+						InheritIf: "editor",
+					},
+				},
+			},
+			"group": {
+				Type: "group",
+				Relations: map[string]objecttype.RelationRule{
+					"member": {},
+				},
+			},
+			"user": {
+				Type:      "user",
+				Relations: map[string]objecttype.RelationRule{},
+			},
+		},
+	}
+	checkSvc := NewService(nil, testWarrantService{warrants: warrants}, objectTypeSvc, &config.CheckConfig{
+		Concurrency:    4,
+		MaxConcurrency: 1000,
+		Timeout:        time.Minute,
+		MaxDepth:       4,
+	}, nil)
+
+	match, _, _, err := checkSvc.Check(context.Background(), nil, CheckSpec{
+		CheckWarrantSpec: CheckWarrantSpec{
+			ObjectType: "document",
+			ObjectId:   "doc1",
+			Relation:   "viewer",
+			Subject:    user,
+		},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !match {
+		t.Fatalf("expected check to match through the editor relation")
+	}
+
+}
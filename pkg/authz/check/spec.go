@@ -18,10 +18,29 @@ import (
 	"fmt"
 
 	warrant "github.com/warrant-dev/warrant/pkg/authz/warrant"
+	whatif "github.com/warrant-dev/warrant/pkg/authz/whatif"
+	"github.com/warrant-dev/warrant/pkg/service"
 )
 
 const Authorized = "Authorized"
 const NotAuthorized = "Not Authorized"
+const CheckError = "Error"
+const CheckOpBatch = "batch"
+
+const (
+	ExplanationTypeCheck = "check"
+	ExplanationTypeGroup = "group"
+	ExplanationTypeRule  = "rule"
+	ExplanationTypeDeny  = "deny"
+)
+
+const (
+	ExplanationResultMatched    = "matched"
+	ExplanationResultNotMatched = "notMatched"
+	ExplanationResultCanceled   = "canceled"
+	ExplanationResultCycle      = "cycle"
+	ExplanationResultError      = "error"
+)
 
 type CheckWarrantSpec struct {
 	ObjectType string                `json:"objectType" validate:"required,valid_object_type"`
@@ -51,7 +70,9 @@ type CheckSessionWarrantSpec struct {
 
 type CheckSpec struct {
 	CheckWarrantSpec
-	Debug bool `json:"debug" validate:"boolean"`
+	Debug   bool               `json:"debug" validate:"boolean"`
+	Explain bool               `json:"explain" validate:"boolean"`
+	WhatIf  *whatif.WhatIfSpec `json:"whatIf,omitempty"`
 }
 
 type CheckManySpec struct {
@@ -59,6 +80,8 @@ type CheckManySpec struct {
 	Warrants []CheckWarrantSpec    `json:"warrants" validate:"min=1,dive"`
 	Context  warrant.PolicyContext `json:"context"`
 	Debug    bool                  `json:"debug"`
+	Explain  bool                  `json:"explain"`
+	WhatIf   *whatif.WhatIfSpec    `json:"whatIf,omitempty"`
 }
 
 type SessionCheckManySpec struct {
@@ -66,6 +89,7 @@ type SessionCheckManySpec struct {
 	Warrants []CheckSessionWarrantSpec `json:"warrants" validate:"min=1,dive"`
 	Context  warrant.PolicyContext     `json:"context"`
 	Debug    bool                      `json:"debug"`
+	Explain  bool                      `json:"explain"`
 }
 
 type CheckResultSpec struct {
@@ -74,4 +98,30 @@ type CheckResultSpec struct {
 	IsImplicit     bool                             `json:"isImplicit"`
 	ProcessingTime int64                            `json:"processingTime,omitempty"`
 	DecisionPath   map[string][]warrant.WarrantSpec `json:"decisionPath,omitempty"`
+	Explanation    map[string]*ExplanationSpec      `json:"explanation,omitempty"`
+	Error          service.Error                    `json:"error,omitempty"`
+}
+
+// ExplanationSpec is a node in the tree of sub-checks (check), group warrants
+// (group), and relation rules (rule) evaluated by a check, along with the
+// warrants and policies consulted at that node.
+type ExplanationSpec struct {
+	Type         string                 `json:"type"`
+	Check        *CheckWarrantSpec      `json:"check,omitempty"`
+	InheritIf    string                 `json:"inheritIf,omitempty"`
+	OfType       string                 `json:"ofType,omitempty"`
+	WithRelation string                 `json:"withRelation,omitempty"`
+	Warrants     []warrant.WarrantSpec  `json:"warrants,omitempty"`
+	Policies     []PolicyEvaluationSpec `json:"policies,omitempty"`
+	Result       string                 `json:"result"`
+	ShortCircuit bool                   `json:"shortCircuit,omitempty"`
+	Error        string                 `json:"error,omitempty"`
+	Children     []*ExplanationSpec     `json:"children,omitempty"`
+}
+
+type PolicyEvaluationSpec struct {
+	Warrant warrant.WarrantSpec   `json:"warrant"`
+	Context warrant.PolicyContext `json:"context,omitempty"`
+	Result  bool                  `json:"result"`
+	Error   string                `json:"error,omitempty"`
 }
@@ -0,0 +1,59 @@
+// Copyright 2024 WorkOS, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package authz
+
+import (
+	"context"
+
+	objecttype "github.com/warrant-dev/warrant/pkg/authz/objecttype"
+	"github.com/warrant-dev/warrant/pkg/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+)
+
+// Starts a span for a step of a check that ends when the step's result is sent
+// to the returned channel, which passes it on to resultC. If the check isn't
+// being traced, ctx and resultC are returned as is.
+func startCheckSpan(ctx context.Context, resultC chan<- result, name string, attrs ...attribute.KeyValue) (context.Context, chan<- result) {
+	spanCtx, span := tracing.StartSpan(ctx, name, attrs...)
+	if !span.IsRecording() {
+		return ctx, resultC
+	}
+
+	tracedResultC := make(chan result, 1)
+	go func() {
+		defer span.End()
+		select {
+		case res := <-tracedResultC:
+			span.SetAttributes(
+				attribute.Bool("check.matched", res.Matched),
+				attribute.Bool("check.pruned", res.Pruned),
+			)
+			tracing.SetError(span, res.Err)
+			resultC <- res
+		case <-spanCtx.Done():
+			span.SetStatus(codes.Unset, "canceled")
+			span.SetAttributes(attribute.Bool("check.canceled", true))
+		}
+	}()
+	return spanCtx, tracedResultC
+}
+
+func ruleAttribute(rule *objecttype.RelationRule) attribute.KeyValue {
+	if rule == nil {
+		return attribute.String("check.rule", "")
+	}
+	return attribute.String("check.rule", rule.InheritIf+"#"+rule.OfType+"#"+rule.WithRelation)
+}
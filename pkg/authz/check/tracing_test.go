@@ -0,0 +1,169 @@
+// Copyright 2024 WorkOS, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package authz
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	objecttype "github.com/warrant-dev/warrant/pkg/authz/objecttype"
+	warrant "github.com/warrant-dev/warrant/pkg/authz/warrant"
+	"github.com/warrant-dev/warrant/pkg/config"
+	"github.com/warrant-dev/warrant/pkg/service"
+	"github.com/warrant-dev/warrant/pkg/tracing"
+	"github.com/warrant-dev/warrant/pkg/wookie"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+var errNotImplemented = errors.New("not implemented")
+
+type testWarrantService struct {
+	warrants []warrant.WarrantSpec
+}
+
+func (svc testWarrantService) Create(ctx context.Context, spec warrant.CreateWarrantSpec) (*warrant.WarrantSpec, *wookie.Token, error) {
+	return nil, nil, errNotImplemented
+}
+
+func (svc testWarrantService) List(ctx context.Context, filterParams warrant.FilterParams, listParams service.ListParams) ([]warrant.WarrantSpec, *service.Cursor, *service.Cursor, error) {
+	warrants := make([]warrant.WarrantSpec, 0)
+	for _, warrantSpec := range svc.warrants {
+		if (filterParams.ObjectType == "" || filterParams.ObjectType == warrantSpec.ObjectType) &&
+			(filterParams.ObjectId == "" || filterParams.ObjectId == warrantSpec.ObjectId) &&
+			(filterParams.Relation == "" || filterParams.Relation == warrantSpec.Relation) &&
+			(filterParams.SubjectType == "" || filterParams.SubjectType == warrantSpec.Subject.ObjectType) &&
+			(filterParams.SubjectId == "" || filterParams.SubjectId == warrantSpec.Subject.ObjectId) &&
+			(filterParams.Expired == nil || *filterParams.Expired == warrantSpec.IsExpired(time.Now())) {
+			warrants = append(warrants, warrantSpec)
+		}
+	}
+	return warrants, nil, nil, nil
+}
+
+func (svc testWarrantService) Delete(ctx context.Context, spec warrant.DeleteWarrantSpec) (*wookie.Token, error) {
+	return nil, errNotImplemented
+}
+
+type testObjectTypeService struct {
+	objectTypes map[string]objecttype.ObjectTypeSpec
+}
+
+func (svc testObjectTypeService) Create(ctx context.Context, spec objecttype.CreateObjectTypeSpec) (*objecttype.ObjectTypeSpec, *wookie.Token, error) {
+	return nil, nil, errNotImplemented
+}
+
+func (svc testObjectTypeService) GetByTypeId(ctx context.Context, typeId string) (*objecttype.ObjectTypeSpec, error) {
+	objectType, ok := svc.objectTypes[typeId]
+	if !ok {
+		return nil, service.NewRecordNotFoundError("ObjectType", typeId)
+	}
+	return &objectType, nil
+}
+
+func (svc testObjectTypeService) List(ctx context.Context, listParams service.ListParams) ([]objecttype.ObjectTypeSpec, *service.Cursor, *service.Cursor, error) {
+	return nil, nil, nil, errNotImplemented
+}
+
+func (svc testObjectTypeService) UpdateByTypeId(ctx context.Context, typeId string, spec objecttype.UpdateObjectTypeSpec) (*objecttype.ObjectTypeSpec, *wookie.Token, error) {
+	return nil, nil, errNotImplemented
+}
+
+func (svc testObjectTypeService) DeleteByTypeId(ctx context.Context, typeId string) (*wookie.Token, error) {
+	return nil, errNotImplemented
+}
+
+func TestCheckSpansAreChildrenOfRequestSpan(t *testing.T) {
+	spanRecorder := tracetest.NewSpanRecorder()
+	tracerProvider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(spanRecorder))
+	otel.SetTracerProvider(tracerProvider)
+	defer func() {
+		_ = tracerProvider.Shutdown(context.Background())
+	}()
+
+	warrantSvc := testWarrantService{
+		warrants: []warrant.WarrantSpec{
+			{
+				ObjectType: "document",
+				ObjectId:   "doc1",
+				Relation:   "editor",
+				Subject:    &warrant.SubjectSpec{ObjectType: "user", ObjectId: "user1"},
+			},
+		},
+	}
+	objectTypeSvc := testObjectTypeService{
+		objectTypes: map[string]objecttype.ObjectTypeSpec{
+			"document": {
+				Type: "document",
+				Relations: map[string]objecttype.RelationRule{
+					"editor": {},
+					"viewer": {
+						InheritIf: "editor",
+					},
+				},
+			},
+			"user": {
+				Type:      "user",
+				Relations: map[string]objecttype.RelationRule{},
+			},
+		},
+	}
+	checkSvc := NewService(nil, warrantSvc, objectTypeSvc, &config.CheckConfig{
+		Concurrency:    4,
+		MaxConcurrency: 1000,
+		Timeout:        time.Minute,
+	}, nil)
+
+	ctx, requestSpan := tracing.StartSpan(context.Background(), "GET /v2/check")
+	match, _, _, err := checkSvc.Check(ctx, nil, CheckSpec{
+		CheckWarrantSpec: CheckWarrantSpec{
+			ObjectType: "document",
+			ObjectId:   "doc1",
+			Relation:   "viewer",
+			Subject:    &warrant.SubjectSpec{ObjectType: "user", ObjectId: "user1"},
+		},
+	})
+	requestSpan.End()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !match {
+		t.Fatalf("expected check to match")
+	}
+
+	// Check spans end in the background once their result is passed on
+	var checkSpans []sdktrace.ReadOnlySpan
+	deadline := time.Now().Add(5 * time.Second)
+	for len(checkSpans) == 0 && time.Now().Before(deadline) {
+		for _, span := range spanRecorder.Ended() {
+			if span.Name() == "check.check" && !span.Parent().IsRemote() && span.Parent().SpanID() == requestSpan.SpanContext().SpanID() {
+				checkSpans = append(checkSpans, span)
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if len(checkSpans) == 0 {
+		t.Fatalf("expected a check span to be a child of the request span")
+	}
+
+	for _, span := range spanRecorder.Ended() {
+		if span.SpanContext().TraceID() != requestSpan.SpanContext().TraceID() {
+			t.Errorf("expected span %s to be in the request's trace", span.Name())
+		}
+	}
+}
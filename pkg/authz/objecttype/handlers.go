@@ -19,6 +19,7 @@ import (
 
 	"github.com/gorilla/mux"
 	"github.com/warrant-dev/warrant/pkg/service"
+	"github.com/warrant-dev/warrant/pkg/wookie"
 )
 
 func (svc ObjectTypeService) Routes() ([]service.Route, error) {
@@ -108,11 +109,12 @@ func createHandler(svc ObjectTypeService, w http.ResponseWriter, r *http.Request
 		return err
 	}
 
-	createdObjectTypeSpec, _, err := svc.Create(r.Context(), spec)
+	createdObjectTypeSpec, newWookie, err := svc.Create(r.Context(), spec)
 	if err != nil {
 		return err
 	}
 
+	wookie.AddAsResponseHeader(w, newWookie)
 	service.SendJSONResponse(w, createdObjectTypeSpec)
 	return nil
 }
@@ -162,22 +164,24 @@ func updateHandler(svc ObjectTypeService, w http.ResponseWriter, r *http.Request
 	}
 
 	typeId := mux.Vars(r)["type"]
-	updatedObjectTypeSpec, _, err := svc.UpdateByTypeId(r.Context(), typeId, spec)
+	updatedObjectTypeSpec, newWookie, err := svc.UpdateByTypeId(r.Context(), typeId, spec)
 	if err != nil {
 		return err
 	}
 
+	wookie.AddAsResponseHeader(w, newWookie)
 	service.SendJSONResponse(w, updatedObjectTypeSpec)
 	return nil
 }
 
 func deleteHandler(svc ObjectTypeService, w http.ResponseWriter, r *http.Request) error {
 	typeId := mux.Vars(r)["type"]
-	_, err := svc.DeleteByTypeId(r.Context(), typeId)
+	newWookie, err := svc.DeleteByTypeId(r.Context(), typeId)
 	if err != nil {
 		return err
 	}
 
+	wookie.AddAsResponseHeader(w, newWookie)
 	w.Header().Set("Content-type", "application/json")
 	w.WriteHeader(http.StatusOK)
 	return nil
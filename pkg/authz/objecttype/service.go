@@ -16,7 +16,9 @@ package authz
 
 import (
 	"context"
+	"errors"
 
+	"github.com/warrant-dev/warrant/pkg/changelog"
 	"github.com/warrant-dev/warrant/pkg/service"
 	"github.com/warrant-dev/warrant/pkg/wookie"
 )
@@ -43,7 +45,7 @@ func NewService(env service.Env, repository ObjectTypeRepository) *ObjectTypeSer
 
 func (svc ObjectTypeService) Create(ctx context.Context, spec CreateObjectTypeSpec) (*ObjectTypeSpec, *wookie.Token, error) {
 	var newObjectTypeSpec *ObjectTypeSpec
-	err := svc.Env().DB().WithinTransaction(ctx, func(txCtx context.Context) error {
+	newWookie, err := svc.Env().DB().WithinConsistentTransaction(ctx, func(txCtx context.Context) error {
 		objectType, err := spec.ToObjectType()
 		if err != nil {
 			return err
@@ -64,12 +66,14 @@ func (svc ObjectTypeService) Create(ctx context.Context, spec CreateObjectTypeSp
 			return err
 		}
 
-		return nil
+		return svc.RecordChange(txCtx, changelog.TypeObjectTypeCreated, nil, newObjectTypeSpec)
 	})
 	if err != nil {
 		return nil, nil, err
 	}
-	return newObjectTypeSpec, nil, nil
+
+	svc.NotifyWrite(ctx, newObjectTypeSpec.Type)
+	return newObjectTypeSpec, newWookie, nil
 }
 
 func (svc ObjectTypeService) GetByTypeId(ctx context.Context, typeId string) (*ObjectTypeSpec, error) {
@@ -107,12 +111,17 @@ func (svc ObjectTypeService) List(ctx context.Context, listParams service.ListPa
 
 func (svc ObjectTypeService) UpdateByTypeId(ctx context.Context, typeId string, spec UpdateObjectTypeSpec) (*ObjectTypeSpec, *wookie.Token, error) {
 	var updatedObjectTypeSpec *ObjectTypeSpec
-	err := svc.Env().DB().WithinTransaction(ctx, func(txCtx context.Context) error {
+	newWookie, err := svc.Env().DB().WithinConsistentTransaction(ctx, func(txCtx context.Context) error {
 		currentObjectType, err := svc.repository.GetByTypeId(txCtx, typeId)
 		if err != nil {
 			return err
 		}
 
+		currentObjectTypeSpec, err := currentObjectType.ToObjectTypeSpec()
+		if err != nil {
+			return err
+		}
+
 		updateTo, err := spec.ToObjectType(typeId)
 		if err != nil {
 			return err
@@ -129,27 +138,41 @@ func (svc ObjectTypeService) UpdateByTypeId(ctx context.Context, typeId string,
 			return err
 		}
 
-		return nil
+		return svc.RecordChange(txCtx, changelog.TypeObjectTypeUpdated, currentObjectTypeSpec, updatedObjectTypeSpec)
 	})
 	if err != nil {
 		return nil, nil, err
 	}
-	return updatedObjectTypeSpec, nil, nil
+
+	svc.NotifyWrite(ctx, typeId)
+	return updatedObjectTypeSpec, newWookie, nil
 }
 
 func (svc ObjectTypeService) DeleteByTypeId(ctx context.Context, typeId string) (*wookie.Token, error) {
-	err := svc.Env().DB().WithinTransaction(ctx, func(txCtx context.Context) error {
-		err := svc.repository.DeleteByTypeId(txCtx, typeId)
+	newWookie, err := svc.Env().DB().WithinConsistentTransaction(ctx, func(txCtx context.Context) error {
+		deletedObjectTypeSpec, err := svc.GetByTypeId(txCtx, typeId)
+		if err != nil {
+			var recordNotFoundError *service.RecordNotFoundError
+			if !errors.As(err, &recordNotFoundError) {
+				return err
+			}
+		}
+
+		err = svc.repository.DeleteByTypeId(txCtx, typeId)
 		if err != nil {
 			return err
 		}
 
-		return nil
+		if deletedObjectTypeSpec == nil {
+			return nil
+		}
+
+		return svc.RecordChange(txCtx, changelog.TypeObjectTypeDeleted, deletedObjectTypeSpec, nil)
 	})
 	if err != nil {
 		return nil, err
 	}
 
-	//nolint:nilnil
-	return nil, nil
+	svc.NotifyWrite(ctx, typeId)
+	return newWookie, nil
 }
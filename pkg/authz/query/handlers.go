@@ -17,6 +17,7 @@ package authz
 import (
 	"net/http"
 
+	whatif "github.com/warrant-dev/warrant/pkg/authz/whatif"
 	"github.com/warrant-dev/warrant/pkg/service"
 )
 
@@ -110,6 +111,21 @@ func queryV2(svc QueryService, w http.ResponseWriter, r *http.Request) error {
 		}
 	}
 
+	if queryParams.Has("whatIf") {
+		var whatIfSpec whatif.WhatIfSpec
+		err = service.ParseJSONBytes(r.Context(), []byte(queryParams.Get("whatIf")), &whatIfSpec)
+		if err != nil {
+			return err
+		}
+
+		err = service.ValidateStruct(r.Context(), &whatIfSpec)
+		if err != nil {
+			return err
+		}
+
+		query.WhatIf = &whatIfSpec
+	}
+
 	listParams := service.GetListParamsFromContext[QueryListParamParser](r.Context())
 	results, prevCursor, nextCursor, err := svc.Query(r.Context(), query, listParams)
 	if err != nil {
@@ -19,12 +19,18 @@ import (
 	"fmt"
 	"sort"
 	"strings"
+	"time"
 
 	"github.com/pkg/errors"
 	objecttype "github.com/warrant-dev/warrant/pkg/authz/objecttype"
 	warrant "github.com/warrant-dev/warrant/pkg/authz/warrant"
+	whatif "github.com/warrant-dev/warrant/pkg/authz/whatif"
+	"github.com/warrant-dev/warrant/pkg/decisionlog"
+	"github.com/warrant-dev/warrant/pkg/metrics"
 	"github.com/warrant-dev/warrant/pkg/object"
 	"github.com/warrant-dev/warrant/pkg/service"
+	"github.com/warrant-dev/warrant/pkg/tracing"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 const (
@@ -36,9 +42,10 @@ var ErrInvalidQuery = errors.New("invalid query")
 
 type QueryService struct {
 	service.BaseService
-	objectTypeSvc objecttype.Service
-	warrantSvc    warrant.Service
-	objectSvc     object.Service
+	objectTypeSvc  objecttype.Service
+	warrantSvc     warrant.Service
+	objectSvc      object.Service
+	decisionLogger *decisionlog.DecisionLogger
 }
 
 func NewService(env service.Env, objectTypeSvc objecttype.Service, warrantSvc warrant.Service, objectSvc object.Service) QueryService {
@@ -50,7 +57,77 @@ func NewService(env service.Env, objectTypeSvc objecttype.Service, warrantSvc wa
 	}
 }
 
+// SetDecisionLogger sets the DecisionLogger that query decisions are logged to.
+func (svc *QueryService) SetDecisionLogger(decisionLogger *decisionlog.DecisionLogger) {
+	svc.decisionLogger = decisionLogger
+}
+
+// Returns a copy of svc that queries as if the changes in whatIfSpec were persisted.
+func (svc QueryService) withWhatIf(whatIfSpec *whatif.WhatIfSpec) (QueryService, error) {
+	whatIfWarrantSvc, err := whatif.NewWarrantService(svc.warrantSvc, *whatIfSpec)
+	if err != nil {
+		return svc, err
+	}
+
+	whatIfObjectTypeSvc, err := whatif.NewObjectTypeService(svc.objectTypeSvc, *whatIfSpec)
+	if err != nil {
+		return svc, err
+	}
+
+	svc.warrantSvc = whatIfWarrantSvc
+	svc.objectTypeSvc = whatIfObjectTypeSvc
+	return svc, nil
+}
+
 func (svc QueryService) Query(ctx context.Context, query Query, listParams service.ListParams) ([]QueryResult, *service.Cursor, *service.Cursor, error) {
+	start := time.Now()
+	queryResults, prevCursor, nextCursor, err := svc.runQuery(ctx, query, listParams)
+	if err != nil {
+		metrics.IncQueryResults(metrics.QueryResultError)
+	} else {
+		metrics.IncQueryResults(metrics.QueryResultSuccess)
+	}
+	svc.logDecision(ctx, query, queryResults, err, time.Since(start))
+	return queryResults, prevCursor, nextCursor, err
+}
+
+// Logs the decision made for query if a DecisionLogger is set.
+func (svc QueryService) logDecision(ctx context.Context, query Query, queryResults []QueryResult, err error, latency time.Duration) {
+	if svc.decisionLogger == nil {
+		return
+	}
+
+	entry := decisionlog.Entry{
+		Type:      decisionlog.TypeQuery,
+		Request:   strings.TrimSpace(query.String()),
+		LatencyMs: float64(latency.Microseconds()) / 1000,
+	}
+	if query.SelectObjects != nil && query.SelectObjects.WhereSubject != nil {
+		entry.Subject = query.SelectObjects.WhereSubject.String()
+	}
+	if err != nil {
+		entry.Error = err.Error()
+	} else {
+		numResults := len(queryResults)
+		entry.NumResults = &numResults
+		for _, queryResult := range queryResults {
+			entry.IsImplicit = entry.IsImplicit || queryResult.IsImplicit
+		}
+	}
+
+	svc.decisionLogger.Log(ctx, entry)
+}
+
+func (svc QueryService) runQuery(ctx context.Context, query Query, listParams service.ListParams) ([]QueryResult, *service.Cursor, *service.Cursor, error) {
+	if query.WhatIf != nil {
+		whatIfSvc, err := svc.withWhatIf(query.WhatIf)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+
+		svc = whatIfSvc
+	}
+
 	queryResults := make([]QueryResult, 0)
 	resultMap := make(map[string][]int)
 	objects := make(map[string][]string)
@@ -321,6 +398,12 @@ func (svc QueryService) Query(ctx context.Context, query Query, listParams servi
 }
 
 func (svc QueryService) query(ctx context.Context, query Query, level int) (*ResultSet, error) {
+	ctx, span := tracing.StartSpan(ctx, "query.query",
+		attribute.Int("query.level", level),
+		attribute.String("query.spec", strings.TrimSpace(query.String())),
+	)
+	defer span.End()
+
 	switch {
 	case query.SelectObjects != nil:
 		objectType := query.SelectObjects.ObjectTypes[0]
@@ -335,7 +418,7 @@ func (svc QueryService) query(ctx context.Context, query Query, level int) (*Res
 		}
 
 		// base case: explicit query
-		matchedWarrants, err := svc.listWarrants(ctx, warrant.FilterParams{
+		warrantSpecs, err := svc.listAllWarrants(ctx, warrant.FilterParams{
 			ObjectType: query.SelectObjects.ObjectTypes[0],
 			Relation:   query.SelectObjects.Relations[0],
 		})
@@ -343,6 +426,8 @@ func (svc QueryService) query(ctx context.Context, query Query, level int) (*Res
 			return nil, err
 		}
 
+		matchedWarrants, denyWarrants := partitionByEffect(warrantSpecs)
+
 		resultSet := NewResultSet()
 		for _, matchedWarrant := range matchedWarrants {
 			if matchedWarrant.Subject.Relation != "" {
@@ -411,7 +496,7 @@ func (svc QueryService) query(ctx context.Context, query Query, level int) (*Res
 			}
 		}
 
-		return resultSet, nil
+		return svc.applyDenyWarrants(ctx, query, denyWarrants, resultSet)
 	case query.SelectSubjects != nil:
 		objectType := query.SelectSubjects.ForObject.Type
 		relation := query.SelectSubjects.Relations[0]
@@ -425,7 +510,7 @@ func (svc QueryService) query(ctx context.Context, query Query, level int) (*Res
 		}
 
 		// base case: explicit query
-		matchedWarrants, err := svc.listWarrants(ctx, warrant.FilterParams{
+		warrantSpecs, err := svc.listAllWarrants(ctx, warrant.FilterParams{
 			ObjectType: query.SelectSubjects.ForObject.Type,
 			ObjectId:   query.SelectSubjects.ForObject.Id,
 			Relation:   query.SelectSubjects.Relations[0],
@@ -434,6 +519,8 @@ func (svc QueryService) query(ctx context.Context, query Query, level int) (*Res
 			return nil, err
 		}
 
+		matchedWarrants, denyWarrants := partitionByEffect(warrantSpecs)
+
 		resultSet := NewResultSet()
 		for _, matchedWarrant := range matchedWarrants {
 			if matchedWarrant.Subject.Relation != "" {
@@ -473,13 +560,20 @@ func (svc QueryService) query(ctx context.Context, query Query, level int) (*Res
 			}
 		}
 
-		return resultSet, nil
+		return svc.applyDenyWarrants(ctx, query, denyWarrants, resultSet)
 	default:
 		return nil, ErrInvalidQuery
 	}
 }
 
 func (svc QueryService) queryRule(ctx context.Context, query Query, level int, relation string, rule objecttype.RelationRule) (*ResultSet, error) {
+	ctx, span := tracing.StartSpan(ctx, "query.queryRule",
+		attribute.Int("query.level", level),
+		attribute.String("query.relation", relation),
+		attribute.String("query.rule", rule.InheritIf+"#"+rule.OfType+"#"+rule.WithRelation),
+	)
+	defer span.End()
+
 	switch rule.InheritIf {
 	case "":
 		return NewResultSet(), nil
@@ -678,7 +772,129 @@ func (svc QueryService) queryRule(ctx context.Context, query Query, level int, r
 	}
 }
 
+// Removes results overridden by deny warrants. Results denied by a deny warrant
+// with a policy are kept, conditioned on that policy not matching.
+func (svc QueryService) applyDenyWarrants(ctx context.Context, query Query, denyWarrants []warrant.WarrantSpec, resultSet *ResultSet) (*ResultSet, error) {
+	if len(denyWarrants) == 0 || resultSet.Len() == 0 {
+		return resultSet, nil
+	}
+
+	// policies under which each object (or subject) in the result set is denied
+	denyPolicies := make(map[string][]warrant.Policy)
+	switch {
+	case query.SelectObjects != nil:
+		whereSubject := query.SelectObjects.WhereSubject
+		if whereSubject == nil {
+			return resultSet, nil
+		}
+
+		for _, denyWarrant := range denyWarrants {
+			if denyWarrant.Subject.Relation == "" {
+				if denyWarrant.Subject.ObjectType == whereSubject.Type && (denyWarrant.Subject.ObjectId == whereSubject.Id || denyWarrant.Subject.ObjectId == warrant.Wildcard) {
+					key := objectKey(denyWarrant.ObjectType, denyWarrant.ObjectId)
+					denyPolicies[key] = append(denyPolicies[key], denyWarrant.Policy)
+				}
+				continue
+			}
+
+			// handle group deny warrants
+			members, err := svc.query(ctx, Query{
+				Expand: true,
+				SelectSubjects: &SelectSubjects{
+					Relations:    []string{denyWarrant.Subject.Relation},
+					SubjectTypes: []string{whereSubject.Type},
+					ForObject: &Resource{
+						Type: denyWarrant.Subject.ObjectType,
+						Id:   denyWarrant.Subject.ObjectId,
+					},
+				},
+				Context: query.Context,
+			}, 0)
+			if err != nil {
+				return nil, err
+			}
+
+			if member := members.Get(whereSubject.Type, whereSubject.Id, denyWarrant.Subject.Relation); member != nil {
+				key := objectKey(denyWarrant.ObjectType, denyWarrant.ObjectId)
+				denyPolicies[key] = append(denyPolicies[key], denyWarrant.Policy.And(member.Policy))
+			}
+		}
+	case query.SelectSubjects != nil:
+		for _, denyWarrant := range denyWarrants {
+			if denyWarrant.Subject.Relation == "" {
+				key := objectKey(denyWarrant.Subject.ObjectType, denyWarrant.Subject.ObjectId)
+				denyPolicies[key] = append(denyPolicies[key], denyWarrant.Policy)
+				continue
+			}
+
+			// handle group deny warrants
+			members, err := svc.query(ctx, Query{
+				Expand: true,
+				SelectSubjects: &SelectSubjects{
+					Relations:    []string{denyWarrant.Subject.Relation},
+					SubjectTypes: query.SelectSubjects.SubjectTypes,
+					ForObject: &Resource{
+						Type: denyWarrant.Subject.ObjectType,
+						Id:   denyWarrant.Subject.ObjectId,
+					},
+				},
+				Context: query.Context,
+			}, 0)
+			if err != nil {
+				return nil, err
+			}
+
+			for member := members.List(); member != nil; member = member.Next() {
+				key := objectKey(member.ObjectType, member.ObjectId)
+				denyPolicies[key] = append(denyPolicies[key], denyWarrant.Policy.And(member.Policy))
+			}
+		}
+	default:
+		return nil, ErrInvalidQuery
+	}
+
+	if len(denyPolicies) == 0 {
+		return resultSet, nil
+	}
+
+	allowedResultSet := NewResultSet()
+	for res := resultSet.List(); res != nil; res = res.Next() {
+		policies := append(denyPolicies[objectKey(res.ObjectType, res.ObjectId)], denyPolicies[objectKey(res.ObjectType, warrant.Wildcard)]...)
+		policy := res.Policy
+		denied := false
+		for _, denyPolicy := range policies {
+			if denyPolicy == "" {
+				denied = true
+				break
+			}
+
+			policy = policy.And(denyPolicy.Not())
+		}
+
+		if !denied {
+			allowedResultSet.Add(res.ObjectType, res.ObjectId, res.Relation, res.Warrant, policy, res.IsImplicit)
+		}
+	}
+
+	return allowedResultSet, nil
+}
+
+// Lists the warrants matching filterParams, excluding deny warrants.
 func (svc QueryService) listWarrants(ctx context.Context, filterParams warrant.FilterParams) ([]warrant.WarrantSpec, error) {
+	warrantSpecs, err := svc.listAllWarrants(ctx, filterParams)
+	if err != nil {
+		return nil, err
+	}
+
+	allowWarrants, _ := partitionByEffect(warrantSpecs)
+	return allowWarrants, nil
+}
+
+func (svc QueryService) listAllWarrants(ctx context.Context, filterParams warrant.FilterParams) ([]warrant.WarrantSpec, error) {
+	// Expired warrants are ignored even if they haven't been swept yet
+	expired := false
+	filterParams.Expired = &expired
+
 	var result []warrant.WarrantSpec
 	listParams := service.DefaultListParams(warrant.WarrantListParamParser{})
 	listParams.WithLimit(MaxEdges)
@@ -726,6 +942,20 @@ func (svc QueryService) listObjectsByType(ctx context.Context, objectType string
 	}
 }
 
+func partitionByEffect(warrantSpecs []warrant.WarrantSpec) ([]warrant.WarrantSpec, []warrant.WarrantSpec) {
+	allowWarrants := make([]warrant.WarrantSpec, 0, len(warrantSpecs))
+	denyWarrants := make([]warrant.WarrantSpec, 0)
+	for _, warrantSpec := range warrantSpecs {
+		if warrantSpec.IsDeny() {
+			denyWarrants = append(denyWarrants, warrantSpec)
+		} else {
+			allowWarrants = append(allowWarrants, warrantSpec)
+		}
+	}
+
+	return allowWarrants, denyWarrants
+}
+
 func objectKey(objectType string, objectId string) string {
 	return fmt.Sprintf("%s:%s", objectType, objectId)
 }
@@ -21,6 +21,7 @@ import (
 
 	"github.com/pkg/errors"
 	warrant "github.com/warrant-dev/warrant/pkg/authz/warrant"
+	whatif "github.com/warrant-dev/warrant/pkg/authz/whatif"
 	"github.com/warrant-dev/warrant/pkg/service"
 )
 
@@ -29,6 +30,7 @@ type Query struct {
 	SelectSubjects *SelectSubjects
 	SelectObjects  *SelectObjects
 	Context        warrant.PolicyContext
+	WhatIf         *whatif.WhatIfSpec
 }
 
 func (q *Query) WithContext(contextString string) error {
@@ -16,8 +16,10 @@ package authz
 
 import (
 	"net/http"
+	"strconv"
 
 	"github.com/warrant-dev/warrant/pkg/service"
+	"github.com/warrant-dev/warrant/pkg/wookie"
 )
 
 func (svc WarrantService) Routes() ([]service.Route, error) {
@@ -38,6 +40,21 @@ func (svc WarrantService) Routes() ([]service.Route, error) {
 			),
 		},
 
+		service.WarrantRoute{
+			Pattern: "/v2/warrants",
+			Method:  "PUT",
+			Handler: service.ChainMiddleware(
+				service.NewRouteHandler(svc, upsertHandler),
+			),
+		},
+		service.WarrantRoute{
+			Pattern: "/v2/warrants/batch",
+			Method:  "POST",
+			Handler: service.ChainMiddleware(
+				service.NewRouteHandler(svc, batchHandler),
+			),
+		},
+
 		// list
 		service.WarrantRoute{
 			Pattern: "/v1/warrants",
@@ -68,7 +85,7 @@ func (svc WarrantService) Routes() ([]service.Route, error) {
 			Pattern: "/v2/warrants",
 			Method:  "DELETE",
 			Handler: service.ChainMiddleware(
-				service.NewRouteHandler(svc, deleteHandler),
+				service.NewRouteHandler(svc, deleteV2Handler),
 			),
 		},
 	}, nil
@@ -89,19 +106,61 @@ func createHandler(svc WarrantService, w http.ResponseWriter, r *http.Request) e
 		}
 	}
 
-	createdWarrant, _, err := svc.Create(r.Context(), spec)
+	createdWarrant, newWookie, err := svc.Create(r.Context(), spec)
 	if err != nil {
 		return err
 	}
 
+	wookie.AddAsResponseHeader(w, newWookie)
 	service.SendJSONResponse(w, createdWarrant)
 	return nil
 }
 
+func upsertHandler(svc WarrantService, w http.ResponseWriter, r *http.Request) error {
+	var spec CreateWarrantSpec
+	err := service.ParseJSONBody(r.Context(), r.Body, &spec)
+	if err != nil {
+		return err
+	}
+
+	upsertedWarrant, newWookie, err := svc.Upsert(r.Context(), spec)
+	if err != nil {
+		return err
+	}
+
+	wookie.AddAsResponseHeader(w, newWookie)
+	service.SendJSONResponse(w, upsertedWarrant)
+	return nil
+}
+
+func batchHandler(svc WarrantService, w http.ResponseWriter, r *http.Request) error {
+	var spec BatchWarrantSpec
+	err := service.ParseJSONBody(r.Context(), r.Body, &spec)
+	if err != nil {
+		return err
+	}
+
+	results, newWookie, err := svc.Batch(r.Context(), spec)
+	if err != nil {
+		return err
+	}
+
+	wookie.AddAsResponseHeader(w, newWookie)
+	service.SendJSONResponse(w, BatchWarrantResultSpec{
+		Results: results,
+	})
+	return nil
+}
+
 func listV1Handler(svc WarrantService, w http.ResponseWriter, r *http.Request) error {
+	filterOptions, err := buildFilterOptions(r)
+	if err != nil {
+		return err
+	}
+
 	warrants, _, _, err := svc.List(
 		r.Context(),
-		*buildFilterOptions(r),
+		*filterOptions,
 		service.GetListParamsFromContext[WarrantListParamParser](r.Context()),
 	)
 	if err != nil {
@@ -113,9 +172,14 @@ func listV1Handler(svc WarrantService, w http.ResponseWriter, r *http.Request) e
 }
 
 func listV2Handler(svc WarrantService, w http.ResponseWriter, r *http.Request) error {
+	filterOptions, err := buildFilterOptions(r)
+	if err != nil {
+		return err
+	}
+
 	warrants, prevCursor, nextCursor, err := svc.List(
 		r.Context(),
-		*buildFilterOptions(r),
+		*filterOptions,
 		service.GetListParamsFromContext[WarrantListParamParser](r.Context()),
 	)
 	if err != nil {
@@ -130,6 +194,37 @@ func listV2Handler(svc WarrantService, w http.ResponseWriter, r *http.Request) e
 	return nil
 }
 
+func deleteV2Handler(svc WarrantService, w http.ResponseWriter, r *http.Request) error {
+	// Warrants are deleted by filter if any query params are given
+	if len(r.URL.Query()) == 0 {
+		return deleteHandler(svc, w, r)
+	}
+
+	queryParams := r.URL.Query()
+	dryRun := false
+	if queryParams.Has("dryRun") {
+		var err error
+		dryRun, err = strconv.ParseBool(queryParams.Get("dryRun"))
+		if err != nil {
+			return service.NewInvalidParameterError("dryRun", "must be true or false")
+		}
+	}
+
+	filterOptions, err := buildFilterOptions(r)
+	if err != nil {
+		return err
+	}
+
+	result, newWookie, err := svc.DeleteByFilter(r.Context(), *filterOptions, dryRun)
+	if err != nil {
+		return err
+	}
+
+	wookie.AddAsResponseHeader(w, newWookie)
+	service.SendJSONResponse(w, result)
+	return nil
+}
+
 func deleteHandler(svc WarrantService, w http.ResponseWriter, r *http.Request) error {
 	var spec DeleteWarrantSpec
 	err := service.ParseJSONBody(r.Context(), r.Body, &spec)
@@ -137,17 +232,18 @@ func deleteHandler(svc WarrantService, w http.ResponseWriter, r *http.Request) e
 		return err
 	}
 
-	_, err = svc.Delete(r.Context(), spec)
+	newWookie, err := svc.Delete(r.Context(), spec)
 	if err != nil {
 		return err
 	}
 
+	wookie.AddAsResponseHeader(w, newWookie)
 	w.Header().Set("Content-type", "application/json")
 	w.WriteHeader(http.StatusOK)
 	return nil
 }
 
-func buildFilterOptions(r *http.Request) *FilterParams {
+func buildFilterOptions(r *http.Request) (*FilterParams, error) {
 	var filterOptions FilterParams
 	queryParams := r.URL.Query()
 
@@ -175,5 +271,17 @@ func buildFilterOptions(r *http.Request) *FilterParams {
 		filterOptions.SubjectRelation = queryParams.Get("subjectRelation")
 	}
 
-	return &filterOptions
+	if queryParams.Has("policyHash") {
+		filterOptions.PolicyHash = queryParams.Get("policyHash")
+	}
+
+	if queryParams.Has("expired") {
+		expired, err := strconv.ParseBool(queryParams.Get("expired"))
+		if err != nil {
+			return nil, service.NewInvalidParameterError("expired", "must be true or false")
+		}
+		filterOptions.Expired = &expired
+	}
+
+	return &filterOptions, nil
 }
@@ -29,6 +29,8 @@ type FilterParams struct {
 	SubjectType     string `json:"subjectType,omitempty"`
 	SubjectId       string `json:"subjectId,omitempty"`
 	SubjectRelation string `json:"subjectRelation,omitempty"`
+	PolicyHash      string `json:"policyHash,omitempty"`
+	Expired         *bool  `json:"expired,omitempty"`
 }
 
 func (fp FilterParams) String() string {
@@ -57,9 +59,29 @@ func (fp FilterParams) String() string {
 		s = fmt.Sprintf("%s&subjectRelation=%s", s, fp.SubjectRelation)
 	}
 
+	if len(fp.PolicyHash) > 0 {
+		s = fmt.Sprintf("%s&policyHash=%s", s, fp.PolicyHash)
+	}
+
+	if fp.Expired != nil {
+		s = fmt.Sprintf("%s&expired=%t", s, *fp.Expired)
+	}
+
 	return strings.TrimPrefix(s, "&")
 }
 
+// IsEmpty returns true if no filters are set.
+func (fp FilterParams) IsEmpty() bool {
+	return fp.ObjectType == "" &&
+		fp.ObjectId == "" &&
+		fp.Relation == "" &&
+		fp.SubjectType == "" &&
+		fp.SubjectId == "" &&
+		fp.SubjectRelation == "" &&
+		fp.PolicyHash == "" &&
+		fp.Expired == nil
+}
+
 const PrimarySortKey = "id"
 
 type WarrantListParamParser struct{}
@@ -29,6 +29,8 @@ type Model interface {
 	GetSubjectRelation() string
 	GetPolicy() Policy
 	GetPolicyHash() string
+	GetEffect() string
+	GetExpiresAt() *time.Time
 	GetCreatedAt() time.Time
 	GetUpdatedAt() time.Time
 	GetDeletedAt() *time.Time
@@ -46,6 +48,8 @@ type Warrant struct {
 	SubjectRelation string     `mysql:"subjectRelation" postgres:"subject_relation" sqlite:"subjectRelation"`
 	Policy          Policy     `mysql:"policy"          postgres:"policy"           sqlite:"policy"`
 	PolicyHash      string     `mysql:"policyHash"      postgres:"policy_hash"      sqlite:"policyHash"`
+	Effect          string     `mysql:"effect"          postgres:"effect"           sqlite:"effect"`
+	ExpiresAt       *time.Time `mysql:"expiresAt"       postgres:"expires_at"       sqlite:"expiresAt"`
 	CreatedAt       time.Time  `mysql:"createdAt"       postgres:"created_at"       sqlite:"createdAt"`
 	UpdatedAt       time.Time  `mysql:"updatedAt"       postgres:"updated_at"       sqlite:"updatedAt"`
 	DeletedAt       *time.Time `mysql:"deletedAt"       postgres:"deleted_at"       sqlite:"deletedAt"`
@@ -87,6 +91,18 @@ func (warrant Warrant) GetPolicyHash() string {
 	return warrant.PolicyHash
 }
 
+func (warrant Warrant) GetEffect() string {
+	if warrant.Effect == "" {
+		return EffectAllow
+	}
+
+	return warrant.Effect
+}
+
+func (warrant Warrant) GetExpiresAt() *time.Time {
+	return warrant.ExpiresAt
+}
+
 func (warrant Warrant) GetCreatedAt() time.Time {
 	return warrant.CreatedAt
 }
@@ -110,9 +126,14 @@ func (warrant Warrant) ToWarrantSpec() *WarrantSpec {
 			Relation:   warrant.SubjectRelation,
 		},
 		Policy:    warrant.Policy,
+		ExpiresAt: warrant.ExpiresAt,
 		CreatedAt: warrant.CreatedAt,
 	}
 
+	if warrant.Effect == EffectDeny {
+		warrantSpec.Effect = EffectDeny
+	}
+
 	return &warrantSpec
 }
 
@@ -127,5 +148,9 @@ func (warrant Warrant) String() string {
 		str = fmt.Sprintf("%s[%s]", str, warrant.Policy)
 	}
 
+	if warrant.Effect == EffectDeny {
+		str = fmt.Sprintf("!%s", str)
+	}
+
 	return str
 }
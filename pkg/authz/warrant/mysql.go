@@ -19,6 +19,8 @@ import (
 	"database/sql"
 	"fmt"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/pkg/errors"
 	"github.com/warrant-dev/warrant/pkg/database"
@@ -47,11 +49,14 @@ func (repo MySQLRepository) Create(ctx context.Context, model Model) (int64, err
 				subjectId,
 				subjectRelation,
 				policy,
-				policyHash
-			) VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+				policyHash,
+				effect,
+				expiresAt
+			) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 			ON DUPLICATE KEY UPDATE
 				createdAt = IF(warrant.deletedAt IS NULL, warrant.createdAt, CURRENT_TIMESTAMP(6)),
 				updatedAt = CURRENT_TIMESTAMP(6),
+				expiresAt = ?,
 				deletedAt = NULL
 		`,
 		model.GetObjectType(),
@@ -62,6 +67,9 @@ func (repo MySQLRepository) Create(ctx context.Context, model Model) (int64, err
 		model.GetSubjectRelation(),
 		model.GetPolicy(),
 		model.GetPolicy().Hash(),
+		model.GetEffect(),
+		model.GetExpiresAt(),
+		model.GetExpiresAt(),
 	)
 	if err != nil {
 		return -1, errors.Wrap(err, "error creating warrant")
@@ -75,7 +83,7 @@ func (repo MySQLRepository) Create(ctx context.Context, model Model) (int64, err
 	return newWarrantId, nil
 }
 
-func (repo MySQLRepository) Delete(ctx context.Context, objectType string, objectId string, relation string, subjectType string, subjectId string, subjectRelation string, policyHash string) error {
+func (repo MySQLRepository) Delete(ctx context.Context, objectType string, objectId string, relation string, subjectType string, subjectId string, subjectRelation string, policyHash string, effect string) error {
 	_, err := repo.DB.ExecContext(
 		ctx,
 		`
@@ -91,6 +99,7 @@ func (repo MySQLRepository) Delete(ctx context.Context, objectType string, objec
 				subjectId = ? AND
 				subjectRelation = ? AND
 				policyHash = ? AND
+				effect = ? AND
 				deletedAt IS NULL
 		`,
 		objectType,
@@ -100,6 +109,7 @@ func (repo MySQLRepository) Delete(ctx context.Context, objectType string, objec
 		subjectId,
 		subjectRelation,
 		policyHash,
+		effect,
 	)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
@@ -110,6 +120,9 @@ func (repo MySQLRepository) Delete(ctx context.Context, objectType string, objec
 			if policyHash != "" {
 				wntErrorId = fmt.Sprintf("%s[%s]", wntErrorId, policyHash)
 			}
+			if effect == EffectDeny {
+				wntErrorId = fmt.Sprintf("!%s", wntErrorId)
+			}
 
 			return service.NewRecordNotFoundError("Warrant", wntErrorId)
 		}
@@ -119,13 +132,148 @@ func (repo MySQLRepository) Delete(ctx context.Context, objectType string, objec
 	return nil
 }
 
-func (repo MySQLRepository) Get(ctx context.Context, objectType string, objectId string, relation string, subjectType string, subjectId string, subjectRelation string, policyHash string) (Model, error) {
+func (repo MySQLRepository) DeleteByIds(ctx context.Context, ids []int64) (int64, error) {
+	replacements := make([]interface{}, 0, len(ids))
+	for _, id := range ids {
+		replacements = append(replacements, id)
+	}
+
+	result, err := repo.DB.ExecContext(
+		ctx,
+		fmt.Sprintf(`
+			UPDATE warrant
+			SET
+				updatedAt = CURRENT_TIMESTAMP(6),
+				deletedAt = CURRENT_TIMESTAMP(6)
+			WHERE
+				id IN (%s) AND
+				deletedAt IS NULL
+		`, BuildQuestionMarkString(len(ids))),
+		replacements...,
+	)
+	if err != nil {
+		return 0, errors.Wrap(err, "error deleting warrants")
+	}
+
+	numDeleted, err := result.RowsAffected()
+	if err != nil {
+		return 0, errors.Wrap(err, "error deleting warrants")
+	}
+
+	return numDeleted, nil
+}
+
+func (repo MySQLRepository) CountByFilter(ctx context.Context, filterParams FilterParams) (int64, error) {
+	var count int64
+	conditions, replacements := buildMySQLFilterConditions(filterParams)
+	err := repo.DB.GetContext(
+		ctx,
+		&count,
+		fmt.Sprintf(`
+			SELECT COUNT(*)
+			FROM warrant
+			WHERE
+				%s
+		`, conditions),
+		replacements...,
+	)
+	if err != nil {
+		return 0, errors.Wrap(err, "error counting warrants")
+	}
+
+	return count, nil
+}
+
+func (repo MySQLRepository) ListByFilter(ctx context.Context, filterParams FilterParams, limit int) ([]Model, error) {
+	models := make([]Model, 0)
+	warrants := make([]Warrant, 0)
+	conditions, replacements := buildMySQLFilterConditions(filterParams)
+	err := repo.DB.SelectContext(
+		ctx,
+		&warrants,
+		fmt.Sprintf(`
+			SELECT id, objectType, objectId, relation, subjectType, subjectId, subjectRelation, policy, effect, expiresAt, createdAt, updatedAt, deletedAt
+			FROM warrant
+			WHERE
+				%s
+			ORDER BY id ASC
+			LIMIT ?
+		`, conditions),
+		append(replacements, limit)...,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models, nil
+		}
+		return nil, errors.Wrap(err, "error listing warrants")
+	}
+
+	for i := range warrants {
+		models = append(models, &warrants[i])
+	}
+
+	return models, nil
+}
+
+// Builds the WHERE conditions matching warrants by filterParams. Unlike List,
+// objectId and subjectId match exactly, so wildcard warrants only match a '*' filter.
+func buildMySQLFilterConditions(filterParams FilterParams) (string, []interface{}) {
+	conditions := []string{"deletedAt IS NULL"}
+	var replacements []interface{}
+	if len(filterParams.ObjectType) > 0 {
+		conditions = append(conditions, "objectType = ?")
+		replacements = append(replacements, filterParams.ObjectType)
+	}
+
+	if len(filterParams.ObjectId) > 0 {
+		conditions = append(conditions, "objectId = ?")
+		replacements = append(replacements, filterParams.ObjectId)
+	}
+
+	if len(filterParams.Relation) > 0 {
+		conditions = append(conditions, "relation = ?")
+		replacements = append(replacements, filterParams.Relation)
+	}
+
+	if len(filterParams.SubjectType) > 0 {
+		conditions = append(conditions, "subjectType = ?")
+		replacements = append(replacements, filterParams.SubjectType)
+	}
+
+	if len(filterParams.SubjectId) > 0 {
+		conditions = append(conditions, "subjectId = ?")
+		replacements = append(replacements, filterParams.SubjectId)
+	}
+
+	if len(filterParams.SubjectRelation) > 0 {
+		conditions = append(conditions, "subjectRelation = ?")
+		replacements = append(replacements, filterParams.SubjectRelation)
+	}
+
+	if len(filterParams.PolicyHash) > 0 {
+		conditions = append(conditions, "policyHash = ?")
+		replacements = append(replacements, filterParams.PolicyHash)
+	}
+
+	if filterParams.Expired != nil {
+		if *filterParams.Expired {
+			conditions = append(conditions, "expiresAt <= ?")
+		} else {
+			conditions = append(conditions, "(expiresAt IS NULL OR expiresAt > ?)")
+		}
+		replacements = append(replacements, time.Now().UTC())
+	}
+
+	return strings.Join(conditions, " AND "), replacements
+}
+
+func (repo MySQLRepository) Get(ctx context.Context, objectType string, objectId string, relation string, subjectType string, subjectId string, subjectRelation string, policyHash string, effect string) (Model, error) {
 	var warrant Warrant
 	err := repo.DB.GetContext(
 		ctx,
 		&warrant,
 		`
-			SELECT id, objectType, objectId, relation, subjectType, subjectId, subjectRelation, policy, createdAt, updatedAt, deletedAt
+			SELECT id, objectType, objectId, relation, subjectType, subjectId, subjectRelation, policy, effect, expiresAt, createdAt, updatedAt, deletedAt
 			FROM warrant
 			WHERE
 				objectType = ? AND
@@ -135,6 +283,7 @@ func (repo MySQLRepository) Get(ctx context.Context, objectType string, objectId
 				subjectId = ? AND
 				subjectRelation = ? AND
 				policyHash = ? AND
+				effect = ? AND
 				deletedAt IS NULL
 		`,
 		objectType,
@@ -144,6 +293,7 @@ func (repo MySQLRepository) Get(ctx context.Context, objectType string, objectId
 		subjectId,
 		subjectRelation,
 		policyHash,
+		effect,
 	)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
@@ -154,6 +304,9 @@ func (repo MySQLRepository) Get(ctx context.Context, objectType string, objectId
 			if policyHash != "" {
 				wntErrorId = fmt.Sprintf("%s[%s]", wntErrorId, policyHash)
 			}
+			if effect == EffectDeny {
+				wntErrorId = fmt.Sprintf("!%s", wntErrorId)
+			}
 
 			return nil, service.NewRecordNotFoundError("Warrant", wntErrorId)
 		}
@@ -169,7 +322,7 @@ func (repo MySQLRepository) GetByID(ctx context.Context, id int64) (Model, error
 		ctx,
 		&warrant,
 		`
-			SELECT id, objectType, objectId, relation, subjectType, subjectId, subjectRelation, policy, createdAt, updatedAt, deletedAt
+			SELECT id, objectType, objectId, relation, subjectType, subjectId, subjectRelation, policy, effect, expiresAt, createdAt, updatedAt, deletedAt
 			FROM warrant
 			WHERE
 				id = ? AND
@@ -191,7 +344,7 @@ func (repo MySQLRepository) List(ctx context.Context, filterParams FilterParams,
 	models := make([]Model, 0)
 	warrants := make([]Warrant, 0)
 	query := `
-		SELECT id, objectType, objectId, relation, subjectType, subjectId, subjectRelation, policy, createdAt, updatedAt, deletedAt
+		SELECT id, objectType, objectId, relation, subjectType, subjectId, subjectRelation, policy, effect, expiresAt, createdAt, updatedAt, deletedAt
 		FROM warrant
 		WHERE
 			deletedAt IS NULL
@@ -229,6 +382,20 @@ func (repo MySQLRepository) List(ctx context.Context, filterParams FilterParams,
 		replacements = append(replacements, filterParams.SubjectRelation)
 	}
 
+	if len(filterParams.PolicyHash) > 0 {
+		query = fmt.Sprintf("%s AND policyHash = ?", query)
+		replacements = append(replacements, filterParams.PolicyHash)
+	}
+
+	if filterParams.Expired != nil {
+		if *filterParams.Expired {
+			query = fmt.Sprintf("%s AND expiresAt <= ?", query)
+		} else {
+			query = fmt.Sprintf("%s AND (expiresAt IS NULL OR expiresAt > ?)", query)
+		}
+		replacements = append(replacements, time.Now().UTC())
+	}
+
 	if listParams.NextCursor != nil {
 		comparisonOp := "<"
 		if listParams.SortOrder == service.SortOrderAsc {
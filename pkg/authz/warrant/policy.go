@@ -123,6 +123,14 @@ func (policy Policy) Or(or Policy) Policy {
 	return Policy(fmt.Sprintf("(%s) || (%s)", policy, or))
 }
 
+func (policy Policy) Not() Policy {
+	if policy == "" {
+		return Policy("false")
+	}
+
+	return Policy(fmt.Sprintf("!(%s)", policy))
+}
+
 func (policy Policy) And(and Policy) Policy {
 	if policy == "" {
 		return and
@@ -20,6 +20,8 @@ import (
 	"fmt"
 	"regexp"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/pkg/errors"
 	"github.com/warrant-dev/warrant/pkg/database"
@@ -52,14 +54,17 @@ func (repo PostgresRepository) Create(ctx context.Context, model Model) (int64,
 				subject_id,
 				subject_relation,
 				policy,
-				policy_hash
-			) VALUES (?, ?, ?, ?, ?, ?, ?, ?)
-			ON CONFLICT (object_type, object_id, relation, subject_type, subject_id, subject_relation, policy_hash) DO UPDATE SET
+				policy_hash,
+				effect,
+				expires_at
+			) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+			ON CONFLICT (object_type, object_id, relation, subject_type, subject_id, subject_relation, policy_hash, effect) DO UPDATE SET
 				created_at = CASE
 					WHEN warrant.deleted_at IS NULL THEN warrant.created_at
 					ELSE CURRENT_TIMESTAMP(6)
 				END,
 				updated_at = CURRENT_TIMESTAMP(6),
+				expires_at = EXCLUDED.expires_at,
 				deleted_at = NULL
 			RETURNING id
 		`,
@@ -71,6 +76,8 @@ func (repo PostgresRepository) Create(ctx context.Context, model Model) (int64,
 		model.GetSubjectRelation(),
 		model.GetPolicy(),
 		model.GetPolicy().Hash(),
+		model.GetEffect(),
+		model.GetExpiresAt(),
 	)
 	if err != nil {
 		return -1, errors.Wrap(err, "error creating warrant")
@@ -79,7 +86,7 @@ func (repo PostgresRepository) Create(ctx context.Context, model Model) (int64,
 	return newWarrantId, nil
 }
 
-func (repo PostgresRepository) Delete(ctx context.Context, objectType string, objectId string, relation string, subjectType string, subjectId string, subjectRelation string, policyHash string) error {
+func (repo PostgresRepository) Delete(ctx context.Context, objectType string, objectId string, relation string, subjectType string, subjectId string, subjectRelation string, policyHash string, effect string) error {
 	_, err := repo.DB.ExecContext(
 		ctx,
 		`
@@ -95,6 +102,7 @@ func (repo PostgresRepository) Delete(ctx context.Context, objectType string, ob
 				subject_id = ? AND
 				subject_relation = ? AND
 				policy_hash = ? AND
+				effect = ? AND
 				deleted_at IS NULL
 		`,
 		objectType,
@@ -104,6 +112,7 @@ func (repo PostgresRepository) Delete(ctx context.Context, objectType string, ob
 		subjectId,
 		subjectRelation,
 		policyHash,
+		effect,
 	)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
@@ -114,6 +123,9 @@ func (repo PostgresRepository) Delete(ctx context.Context, objectType string, ob
 			if policyHash != "" {
 				wntErrorId = fmt.Sprintf("%s[%s]", wntErrorId, policyHash)
 			}
+			if effect == EffectDeny {
+				wntErrorId = fmt.Sprintf("!%s", wntErrorId)
+			}
 
 			return service.NewRecordNotFoundError("Warrant", wntErrorId)
 		}
@@ -123,13 +135,148 @@ func (repo PostgresRepository) Delete(ctx context.Context, objectType string, ob
 	return nil
 }
 
-func (repo PostgresRepository) Get(ctx context.Context, objectType string, objectId string, relation string, subjectType string, subjectId string, subjectRelation string, policyHash string) (Model, error) {
+func (repo PostgresRepository) DeleteByIds(ctx context.Context, ids []int64) (int64, error) {
+	replacements := make([]interface{}, 0, len(ids))
+	for _, id := range ids {
+		replacements = append(replacements, id)
+	}
+
+	result, err := repo.DB.ExecContext(
+		ctx,
+		fmt.Sprintf(`
+			UPDATE warrant
+			SET
+				updated_at = CURRENT_TIMESTAMP(6),
+				deleted_at = CURRENT_TIMESTAMP(6)
+			WHERE
+				id IN (%s) AND
+				deleted_at IS NULL
+		`, BuildQuestionMarkString(len(ids))),
+		replacements...,
+	)
+	if err != nil {
+		return 0, errors.Wrap(err, "error deleting warrants")
+	}
+
+	numDeleted, err := result.RowsAffected()
+	if err != nil {
+		return 0, errors.Wrap(err, "error deleting warrants")
+	}
+
+	return numDeleted, nil
+}
+
+func (repo PostgresRepository) CountByFilter(ctx context.Context, filterParams FilterParams) (int64, error) {
+	var count int64
+	conditions, replacements := buildPostgresFilterConditions(filterParams)
+	err := repo.DB.GetContext(
+		ctx,
+		&count,
+		fmt.Sprintf(`
+			SELECT COUNT(*)
+			FROM warrant
+			WHERE
+				%s
+		`, conditions),
+		replacements...,
+	)
+	if err != nil {
+		return 0, errors.Wrap(err, "error counting warrants")
+	}
+
+	return count, nil
+}
+
+func (repo PostgresRepository) ListByFilter(ctx context.Context, filterParams FilterParams, limit int) ([]Model, error) {
+	models := make([]Model, 0)
+	warrants := make([]Warrant, 0)
+	conditions, replacements := buildPostgresFilterConditions(filterParams)
+	err := repo.DB.SelectContext(
+		ctx,
+		&warrants,
+		fmt.Sprintf(`
+			SELECT id, object_type, object_id, relation, subject_type, subject_id, subject_relation, policy, effect, expires_at, created_at, updated_at, deleted_at
+			FROM warrant
+			WHERE
+				%s
+			ORDER BY id ASC
+			LIMIT ?
+		`, conditions),
+		append(replacements, limit)...,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models, nil
+		}
+		return nil, errors.Wrap(err, "error listing warrants")
+	}
+
+	for i := range warrants {
+		models = append(models, &warrants[i])
+	}
+
+	return models, nil
+}
+
+// Builds the WHERE conditions matching warrants by filterParams. Unlike List,
+// objectId and subjectId match exactly, so wildcard warrants only match a '*' filter.
+func buildPostgresFilterConditions(filterParams FilterParams) (string, []interface{}) {
+	conditions := []string{"deleted_at IS NULL"}
+	var replacements []interface{}
+	if len(filterParams.ObjectType) > 0 {
+		conditions = append(conditions, "object_type = ?")
+		replacements = append(replacements, filterParams.ObjectType)
+	}
+
+	if len(filterParams.ObjectId) > 0 {
+		conditions = append(conditions, "object_id = ?")
+		replacements = append(replacements, filterParams.ObjectId)
+	}
+
+	if len(filterParams.Relation) > 0 {
+		conditions = append(conditions, "relation = ?")
+		replacements = append(replacements, filterParams.Relation)
+	}
+
+	if len(filterParams.SubjectType) > 0 {
+		conditions = append(conditions, "subject_type = ?")
+		replacements = append(replacements, filterParams.SubjectType)
+	}
+
+	if len(filterParams.SubjectId) > 0 {
+		conditions = append(conditions, "subject_id = ?")
+		replacements = append(replacements, filterParams.SubjectId)
+	}
+
+	if len(filterParams.SubjectRelation) > 0 {
+		conditions = append(conditions, "subject_relation = ?")
+		replacements = append(replacements, filterParams.SubjectRelation)
+	}
+
+	if len(filterParams.PolicyHash) > 0 {
+		conditions = append(conditions, "policy_hash = ?")
+		replacements = append(replacements, filterParams.PolicyHash)
+	}
+
+	if filterParams.Expired != nil {
+		if *filterParams.Expired {
+			conditions = append(conditions, "expires_at <= ?")
+		} else {
+			conditions = append(conditions, "(expires_at IS NULL OR expires_at > ?)")
+		}
+		replacements = append(replacements, time.Now().UTC())
+	}
+
+	return strings.Join(conditions, " AND "), replacements
+}
+
+func (repo PostgresRepository) Get(ctx context.Context, objectType string, objectId string, relation string, subjectType string, subjectId string, subjectRelation string, policyHash string, effect string) (Model, error) {
 	var warrant Warrant
 	err := repo.DB.GetContext(
 		ctx,
 		&warrant,
 		`
-			SELECT id, object_type, object_id, relation, subject_type, subject_id, subject_relation, policy, created_at, updated_at, deleted_at
+			SELECT id, object_type, object_id, relation, subject_type, subject_id, subject_relation, policy, effect, expires_at, created_at, updated_at, deleted_at
 			FROM warrant
 			WHERE
 				object_type = ? AND
@@ -139,6 +286,7 @@ func (repo PostgresRepository) Get(ctx context.Context, objectType string, objec
 				subject_id = ? AND
 				subject_relation = ? AND
 				policy_hash = ? AND
+				effect = ? AND
 				deleted_at IS NULL
 		`,
 		objectType,
@@ -148,6 +296,7 @@ func (repo PostgresRepository) Get(ctx context.Context, objectType string, objec
 		subjectId,
 		subjectRelation,
 		policyHash,
+		effect,
 	)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
@@ -158,6 +307,9 @@ func (repo PostgresRepository) Get(ctx context.Context, objectType string, objec
 			if policyHash != "" {
 				wntErrorId = fmt.Sprintf("%s[%s]", wntErrorId, policyHash)
 			}
+			if effect == EffectDeny {
+				wntErrorId = fmt.Sprintf("!%s", wntErrorId)
+			}
 
 			return nil, service.NewRecordNotFoundError("Warrant", wntErrorId)
 		}
@@ -173,7 +325,7 @@ func (repo PostgresRepository) GetByID(ctx context.Context, id int64) (Model, er
 		ctx,
 		&warrant,
 		`
-			SELECT id, object_type, object_id, relation, subject_type, subject_id, subject_relation, policy, created_at, updated_at, deleted_at
+			SELECT id, object_type, object_id, relation, subject_type, subject_id, subject_relation, policy, effect, expires_at, created_at, updated_at, deleted_at
 			FROM warrant
 			WHERE
 				id = ? AND
@@ -195,7 +347,7 @@ func (repo PostgresRepository) List(ctx context.Context, filterParams FilterPara
 	models := make([]Model, 0)
 	warrants := make([]Warrant, 0)
 	query := `
-		SELECT id, object_type, object_id, relation, subject_type, subject_id, subject_relation, policy, created_at, updated_at, deleted_at
+		SELECT id, object_type, object_id, relation, subject_type, subject_id, subject_relation, policy, effect, expires_at, created_at, updated_at, deleted_at
 		FROM warrant
 		WHERE
 			deleted_at IS NULL
@@ -234,6 +386,20 @@ func (repo PostgresRepository) List(ctx context.Context, filterParams FilterPara
 		replacements = append(replacements, filterParams.SubjectRelation)
 	}
 
+	if len(filterParams.PolicyHash) > 0 {
+		query = fmt.Sprintf("%s AND policy_hash = ?", query)
+		replacements = append(replacements, filterParams.PolicyHash)
+	}
+
+	if filterParams.Expired != nil {
+		if *filterParams.Expired {
+			query = fmt.Sprintf("%s AND expires_at <= ?", query)
+		} else {
+			query = fmt.Sprintf("%s AND (expires_at IS NULL OR expires_at > ?)", query)
+		}
+		replacements = append(replacements, time.Now().UTC())
+	}
+
 	if listParams.NextCursor != nil {
 		comparisonOp := "<"
 		if listParams.SortOrder == service.SortOrderAsc {
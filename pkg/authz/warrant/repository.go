@@ -26,10 +26,13 @@ import (
 
 type WarrantRepository interface {
 	Create(ctx context.Context, warrant Model) (int64, error)
-	Get(ctx context.Context, objectType string, objectId string, relation string, subjectType string, subjectId string, subjectRelation string, policyHash string) (Model, error)
+	Get(ctx context.Context, objectType string, objectId string, relation string, subjectType string, subjectId string, subjectRelation string, policyHash string, effect string) (Model, error)
 	GetByID(ctx context.Context, id int64) (Model, error)
 	List(ctx context.Context, filterParams FilterParams, listParams service.ListParams) ([]Model, *service.Cursor, *service.Cursor, error)
-	Delete(ctx context.Context, objectType string, objectId string, relation string, subjectType string, subjectId string, subjectRelation string, policyHash string) error
+	Delete(ctx context.Context, objectType string, objectId string, relation string, subjectType string, subjectId string, subjectRelation string, policyHash string, effect string) error
+	DeleteByIds(ctx context.Context, ids []int64) (int64, error)
+	CountByFilter(ctx context.Context, filterParams FilterParams) (int64, error)
+	ListByFilter(ctx context.Context, filterParams FilterParams, limit int) ([]Model, error)
 }
 
 func NewRepository(db database.Database) (WarrantRepository, error) {
@@ -17,8 +17,12 @@ package authz
 import (
 	"context"
 	"errors"
+	"fmt"
+	"time"
 
 	objecttype "github.com/warrant-dev/warrant/pkg/authz/objecttype"
+	"github.com/warrant-dev/warrant/pkg/changelog"
+	"github.com/warrant-dev/warrant/pkg/config"
 	"github.com/warrant-dev/warrant/pkg/object"
 	"github.com/warrant-dev/warrant/pkg/service"
 	"github.com/warrant-dev/warrant/pkg/wookie"
@@ -30,111 +34,180 @@ type Service interface {
 	Delete(ctx context.Context, spec DeleteWarrantSpec) (*wookie.Token, error)
 }
 
+// The max number of matching warrants returned by a dry run of DeleteByFilter.
+const DeleteDryRunSampleSize = 10
+
+// The max number of warrants DeleteByFilter lists and deletes at a time.
+const deleteBatchSize = 1000
+
 type WarrantService struct {
 	service.BaseService
 	repository    WarrantRepository
 	objectTypeSvc objecttype.Service
 	objectSvc     object.Service
+	batchConfig   *config.WarrantBatchConfig
 }
 
-func NewService(env service.Env, repository WarrantRepository, objectTypeSvc objecttype.Service, objectSvc object.Service) *WarrantService {
+func NewService(env service.Env, repository WarrantRepository, objectTypeSvc objecttype.Service, objectSvc object.Service, batchConfig *config.WarrantBatchConfig) *WarrantService {
 	return &WarrantService{
 		BaseService:   service.NewBaseService(env),
 		repository:    repository,
 		objectTypeSvc: objectTypeSvc,
 		objectSvc:     objectSvc,
+		batchConfig:   batchConfig,
 	}
 }
 
 func (svc WarrantService) Create(ctx context.Context, spec CreateWarrantSpec) (*WarrantSpec, *wookie.Token, error) {
 	var createdWarrant Model
-	err := svc.Env().DB().WithinTransaction(ctx, func(txCtx context.Context) error {
-		// Check that objectType exists
-		objectTypeDef, err := svc.objectTypeSvc.GetByTypeId(txCtx, spec.ObjectType)
-		if err != nil {
-			var recordNotFoundError *service.RecordNotFoundError
-			if errors.As(err, &recordNotFoundError) {
-				return service.NewInvalidParameterError("objectType", "the object type does not exist.")
-			}
+	newWookie, err := svc.Env().DB().WithinConsistentTransaction(ctx, func(txCtx context.Context) error {
+		var err error
+		createdWarrant, err = svc.create(txCtx, spec)
+		return err
+	})
+	if err != nil {
+		return nil, nil, err
+	}
 
-			return err
+	svc.NotifyWrite(ctx, spec.ObjectType)
+	return createdWarrant.ToWarrantSpec(), newWookie, nil
+}
+
+// Creates the warrant and any objects it references. Must be called within a transaction.
+func (svc WarrantService) create(ctx context.Context, spec CreateWarrantSpec) (Model, error) {
+	err := svc.checkPreconditions(ctx, spec.Preconditions)
+	if err != nil {
+		return nil, err
+	}
+
+	// Check that objectType exists
+	objectTypeDef, err := svc.objectTypeSvc.GetByTypeId(ctx, spec.ObjectType)
+	if err != nil {
+		var recordNotFoundError *service.RecordNotFoundError
+		if errors.As(err, &recordNotFoundError) {
+			return nil, service.NewInvalidParameterError("objectType", "the object type does not exist.")
 		}
 
-		// Check that relation is valid for objectType
-		if _, exists := objectTypeDef.Relations[spec.Relation]; !exists {
-			return service.NewInvalidParameterError("relation", "the relation does not exist on the specified object type.")
+		return nil, err
+	}
+
+	// Check that relation is valid for objectType
+	if _, exists := objectTypeDef.Relations[spec.Relation]; !exists {
+		return nil, service.NewInvalidParameterError("relation", "the relation does not exist on the specified object type.")
+	}
+
+	// Unless objectId is wildcard, create referenced object if it does not already exist
+	if spec.ObjectId != Wildcard {
+		objectSpec, err := svc.objectSvc.GetByObjectTypeAndId(ctx, spec.ObjectType, spec.ObjectId)
+		if err != nil {
+			var recordNotFoundError *service.RecordNotFoundError
+			if !errors.As(err, &recordNotFoundError) {
+				return nil, err
+			}
 		}
 
-		// Unless objectId is wildcard, create referenced object if it does not already exist
-		if spec.ObjectId != Wildcard {
-			objectSpec, err := svc.objectSvc.GetByObjectTypeAndId(txCtx, spec.ObjectType, spec.ObjectId)
+		if objectSpec == nil {
+			_, err = svc.objectSvc.Create(ctx, object.CreateObjectSpec{
+				ObjectType: spec.ObjectType,
+				ObjectId:   spec.ObjectId,
+			})
 			if err != nil {
-				var recordNotFoundError *service.RecordNotFoundError
-				if !errors.As(err, &recordNotFoundError) {
-					return err
+				var duplicateRecordError *service.DuplicateRecordError
+				if !errors.As(err, &duplicateRecordError) {
+					return nil, err
 				}
 			}
+		}
+	}
 
-			if objectSpec == nil {
-				_, err = svc.objectSvc.Create(txCtx, object.CreateObjectSpec{
-					ObjectType: spec.ObjectType,
-					ObjectId:   spec.ObjectId,
-				})
-				if err != nil {
-					var duplicateRecordError *service.DuplicateRecordError
-					if !errors.As(err, &duplicateRecordError) {
-						return err
-					}
-				}
+	// Unless subject objectId is wildcard, create referenced subject if it does not already exist
+	if spec.Subject.ObjectId != Wildcard {
+		objectSpec, err := svc.objectSvc.GetByObjectTypeAndId(ctx, spec.Subject.ObjectType, spec.Subject.ObjectId)
+		if err != nil {
+			var recordNotFoundError *service.RecordNotFoundError
+			if !errors.As(err, &recordNotFoundError) {
+				return nil, err
 			}
 		}
 
-		// Unless subject objectId is wildcard, create referenced subject if it does not already exist
-		if spec.Subject.ObjectId != Wildcard {
-			objectSpec, err := svc.objectSvc.GetByObjectTypeAndId(txCtx, spec.Subject.ObjectType, spec.Subject.ObjectId)
+		if objectSpec == nil {
+			_, err = svc.objectSvc.Create(ctx, object.CreateObjectSpec{
+				ObjectType: spec.Subject.ObjectType,
+				ObjectId:   spec.Subject.ObjectId,
+			})
 			if err != nil {
-				var recordNotFoundError *service.RecordNotFoundError
-				if !errors.As(err, &recordNotFoundError) {
-					return err
-				}
-			}
-
-			if objectSpec == nil {
-				_, err = svc.objectSvc.Create(txCtx, object.CreateObjectSpec{
-					ObjectType: spec.Subject.ObjectType,
-					ObjectId:   spec.Subject.ObjectId,
-				})
-				if err != nil {
-					var duplicateRecordError *service.DuplicateRecordError
-					if !errors.As(err, &duplicateRecordError) {
-						return err
-					}
+				var duplicateRecordError *service.DuplicateRecordError
+				if !errors.As(err, &duplicateRecordError) {
+					return nil, err
 				}
 			}
 		}
+	}
+
+	warrant, err := spec.ToWarrant()
+	if err != nil {
+		return nil, err
+	}
+
+	createdWarrantId, err := svc.repository.Create(ctx, warrant)
+	if err != nil {
+		return nil, err
+	}
+
+	createdWarrant, err := svc.repository.GetByID(ctx, createdWarrantId)
+	if err != nil {
+		return nil, err
+	}
+
+	err = svc.RecordChange(ctx, changelog.TypeWarrantCreated, nil, createdWarrant.ToWarrantSpec())
+	if err != nil {
+		return nil, err
+	}
 
+	return createdWarrant, nil
+}
+
+// Upsert creates the warrant if it doesn't already exist. If an identical
+// warrant exists, it is returned without being written and no token is returned.
+func (svc WarrantService) Upsert(ctx context.Context, spec CreateWarrantSpec) (*WarrantSpec, *wookie.Token, error) {
+	var upsertedWarrant Model
+	var newWookie *wookie.Token
+	err := svc.Env().DB().WithinTransaction(ctx, func(txCtx context.Context) error {
 		warrant, err := spec.ToWarrant()
 		if err != nil {
 			return err
 		}
 
-		createdWarrantId, err := svc.repository.Create(txCtx, warrant)
+		existingWarrant, err := svc.getActive(txCtx, warrant)
 		if err != nil {
 			return err
 		}
 
-		createdWarrant, err = svc.repository.GetByID(txCtx, createdWarrantId)
-		if err != nil {
-			return err
+		if existingWarrant != nil && sameExpiration(existingWarrant.GetExpiresAt(), warrant.GetExpiresAt()) {
+			err = svc.checkPreconditions(txCtx, spec.Preconditions)
+			if err != nil {
+				return err
+			}
+
+			upsertedWarrant = existingWarrant
+			return nil
 		}
 
-		return nil
+		newWookie, err = svc.Env().DB().WithinConsistentTransaction(txCtx, func(txCtx context.Context) error {
+			var err error
+			upsertedWarrant, err = svc.create(txCtx, spec)
+			return err
+		})
+		return err
 	})
 	if err != nil {
 		return nil, nil, err
 	}
 
-	return createdWarrant.ToWarrantSpec(), nil, nil
+	if newWookie != nil {
+		svc.NotifyWrite(ctx, spec.ObjectType)
+	}
+	return upsertedWarrant.ToWarrantSpec(), newWookie, nil
 }
 
 func (svc WarrantService) List(ctx context.Context, filterParams FilterParams, listParams service.ListParams) ([]WarrantSpec, *service.Cursor, *service.Cursor, error) {
@@ -152,28 +225,301 @@ func (svc WarrantService) List(ctx context.Context, filterParams FilterParams, l
 }
 
 func (svc WarrantService) Delete(ctx context.Context, spec DeleteWarrantSpec) (*wookie.Token, error) {
-	err := svc.Env().DB().WithinTransaction(ctx, func(txCtx context.Context) error {
-		warrantToDelete, err := spec.ToWarrant()
+	newWookie, err := svc.Env().DB().WithinConsistentTransaction(ctx, func(txCtx context.Context) error {
+		_, err := svc.delete(txCtx, spec)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	svc.NotifyWrite(ctx, spec.ObjectType)
+	return newWookie, nil
+}
+
+// Deletes the warrant and returns it. Must be called within a transaction.
+func (svc WarrantService) delete(ctx context.Context, spec DeleteWarrantSpec) (Model, error) {
+	err := svc.checkPreconditions(ctx, spec.Preconditions)
+	if err != nil {
+		return nil, err
+	}
+
+	warrantToDelete, err := spec.ToWarrant()
+	if err != nil {
+		return nil, err
+	}
+
+	deletedWarrant, err := svc.repository.Get(ctx, warrantToDelete.GetObjectType(), warrantToDelete.GetObjectId(), warrantToDelete.GetRelation(), warrantToDelete.GetSubjectType(), warrantToDelete.GetSubjectId(), warrantToDelete.GetSubjectRelation(), warrantToDelete.GetPolicyHash(), warrantToDelete.GetEffect())
+	if err != nil {
+		return nil, err
+	}
+
+	err = svc.repository.Delete(ctx, warrantToDelete.GetObjectType(), warrantToDelete.GetObjectId(), warrantToDelete.GetRelation(), warrantToDelete.GetSubjectType(), warrantToDelete.GetSubjectId(), warrantToDelete.GetSubjectRelation(), warrantToDelete.GetPolicyHash(), warrantToDelete.GetEffect())
+	if err != nil {
+		return nil, err
+	}
+
+	err = svc.RecordChange(ctx, changelog.TypeWarrantDeleted, deletedWarrant.ToWarrantSpec(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return deletedWarrant, nil
+}
+
+// Returns an error if any of the given preconditions don't hold. Must be
+// called within the transaction of the write the preconditions apply to.
+func (svc WarrantService) checkPreconditions(ctx context.Context, preconditions *PreconditionsSpec) error {
+	if preconditions == nil {
+		return nil
+	}
+
+	for _, spec := range preconditions.Exists {
+		warrant, err := spec.ToWarrant()
+		if err != nil {
+			return err
+		}
+
+		existingWarrant, err := svc.getActive(ctx, warrant)
+		if err != nil {
+			return err
+		}
+
+		if existingWarrant == nil {
+			return service.NewPreconditionFailedError(warrant.String(), "does not exist")
+		}
+	}
+
+	for _, spec := range preconditions.NotExists {
+		warrant, err := spec.ToWarrant()
 		if err != nil {
 			return err
 		}
 
-		_, err = svc.repository.Get(txCtx, warrantToDelete.GetObjectType(), warrantToDelete.GetObjectId(), warrantToDelete.GetRelation(), warrantToDelete.GetSubjectType(), warrantToDelete.GetSubjectId(), warrantToDelete.GetSubjectRelation(), warrantToDelete.GetPolicyHash())
+		existingWarrant, err := svc.getActive(ctx, warrant)
 		if err != nil {
 			return err
 		}
 
-		err = svc.repository.Delete(txCtx, warrantToDelete.GetObjectType(), warrantToDelete.GetObjectId(), warrantToDelete.GetRelation(), warrantToDelete.GetSubjectType(), warrantToDelete.GetSubjectId(), warrantToDelete.GetSubjectRelation(), warrantToDelete.GetPolicyHash())
+		if existingWarrant != nil {
+			return service.NewPreconditionFailedError(warrant.String(), "already exists")
+		}
+	}
+
+	return nil
+}
+
+// Returns the existing unexpired warrant matching warrant, or nil if there isn't one.
+func (svc WarrantService) getActive(ctx context.Context, warrant *Warrant) (Model, error) {
+	existingWarrant, err := svc.repository.Get(ctx, warrant.GetObjectType(), warrant.GetObjectId(), warrant.GetRelation(), warrant.GetSubjectType(), warrant.GetSubjectId(), warrant.GetSubjectRelation(), warrant.GetPolicyHash(), warrant.GetEffect())
+	if err != nil {
+		var recordNotFoundError *service.RecordNotFoundError
+		if errors.As(err, &recordNotFoundError) {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	if existingWarrant.ToWarrantSpec().IsExpired(time.Now()) {
+		return nil, nil
+	}
+
+	return existingWarrant, nil
+}
+
+func sameExpiration(a *time.Time, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+
+	return a.Equal(*b)
+}
+
+// DeleteByFilter deletes all warrants matching filterParams in a single
+// transaction. If dryRun is true, nothing is deleted and the result contains
+// the number of matching warrants and a sample of them instead.
+func (svc WarrantService) DeleteByFilter(ctx context.Context, filterParams FilterParams, dryRun bool) (*DeleteWarrantsResultSpec, *wookie.Token, error) {
+	if filterParams.IsEmpty() {
+		return nil, nil, service.NewInvalidRequestError("must specify at least one filter to delete warrants")
+	}
+
+	if dryRun {
+		count, err := svc.repository.CountByFilter(ctx, filterParams)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		warrants, err := svc.repository.ListByFilter(ctx, filterParams, DeleteDryRunSampleSize)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		sample := make([]WarrantSpec, 0, len(warrants))
+		for _, warrant := range warrants {
+			sample = append(sample, *warrant.ToWarrantSpec())
+		}
+
+		return &DeleteWarrantsResultSpec{
+			DryRun: true,
+			Count:  count,
+			Sample: sample,
+		}, nil, nil
+	}
+
+	var numDeleted int64
+	newWookie, err := svc.Env().DB().WithinConsistentTransaction(ctx, func(txCtx context.Context) error {
+		numDeleted = 0
+		for {
+			warrants, err := svc.repository.ListByFilter(txCtx, filterParams, deleteBatchSize)
+			if err != nil {
+				return err
+			}
+
+			if len(warrants) == 0 {
+				return nil
+			}
+
+			err = svc.deleteListed(txCtx, warrants)
+			if err != nil {
+				return err
+			}
+
+			numDeleted += int64(len(warrants))
+		}
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if filterParams.ObjectType != "" {
+		svc.NotifyWrite(ctx, filterParams.ObjectType)
+	} else {
+		svc.NotifyWrite(ctx)
+	}
+	return &DeleteWarrantsResultSpec{
+		Count: numDeleted,
+	}, newWookie, nil
+}
+
+// Deletes the given warrants by id and records a change for each so that the
+// recorded changes match the deleted rows exactly.
+func (svc WarrantService) deleteListed(ctx context.Context, warrants []Model) error {
+	ids := make([]int64, 0, len(warrants))
+	for _, warrant := range warrants {
+		ids = append(ids, warrant.GetID())
+	}
+
+	numDeleted, err := svc.repository.DeleteByIds(ctx, ids)
+	if err != nil {
+		return err
+	}
+
+	// Some of the listed warrants were deleted by another transaction
+	if numDeleted != int64(len(ids)) {
+		return service.NewConflictError("Warrants were deleted concurrently, please retry the request")
+	}
+
+	for _, warrant := range warrants {
+		err = svc.RecordChange(ctx, changelog.TypeWarrantDeleted, warrant.ToWarrantSpec(), nil)
 		if err != nil {
 			return err
 		}
+	}
+
+	return nil
+}
+
+// Batch executes the given create and delete operations in order within a
+// single transaction. If any operation fails, none of them are applied.
+func (svc WarrantService) Batch(ctx context.Context, spec BatchWarrantSpec) ([]WarrantOperationResultSpec, *wookie.Token, error) {
+	if svc.batchConfig != nil && svc.batchConfig.MaxOperations > 0 && len(spec.Operations) > svc.batchConfig.MaxOperations {
+		return nil, nil, service.NewInvalidParameterError("operations", fmt.Sprintf("must contain at most %d operations", svc.batchConfig.MaxOperations))
+	}
+
+	var results []WarrantOperationResultSpec
+	newWookie, err := svc.Env().DB().WithinConsistentTransaction(ctx, func(txCtx context.Context) error {
+		results = make([]WarrantOperationResultSpec, 0, len(spec.Operations))
+		for i, operation := range spec.Operations {
+			var warrant Model
+			var err error
+			switch operation.Op {
+			case OpCreate:
+				warrant, err = svc.create(txCtx, operation.CreateWarrantSpec)
+			case OpDelete:
+				warrant, err = svc.delete(txCtx, operation.ToDeleteWarrantSpec())
+			default:
+				err = service.NewInvalidParameterError("op", "must be one of create, delete")
+			}
+			if err != nil {
+				return operationError(i, err)
+			}
+
+			results = append(results, WarrantOperationResultSpec{
+				Op:      operation.Op,
+				Warrant: warrant.ToWarrantSpec(),
+			})
+		}
 
 		return nil
 	})
 	if err != nil {
-		return nil, err
+		return nil, nil, err
+	}
+
+	objectTypes := make([]string, 0)
+	seenObjectTypes := make(map[string]bool)
+	for _, operation := range spec.Operations {
+		if !seenObjectTypes[operation.ObjectType] {
+			seenObjectTypes[operation.ObjectType] = true
+			objectTypes = append(objectTypes, operation.ObjectType)
+		}
+	}
+	svc.NotifyWrite(ctx, objectTypes...)
+	return results, newWookie, nil
+}
+
+// Returns err with its parameter prefixed by the index of the failed
+// operation so that callers can tell which operation in a batch failed.
+func operationError(i int, err error) error {
+	var invalidParameterError *service.InvalidParameterError
+	if errors.As(err, &invalidParameterError) {
+		return service.NewInvalidParameterError(fmt.Sprintf("operations[%d].%s", i, invalidParameterError.Parameter), invalidParameterError.Message)
 	}
 
-	//nolint:nilnil
-	return nil, nil
+	return err
+}
+
+// DeleteExpired soft-deletes up to limit warrants whose expiration has passed
+// and returns the number of warrants deleted. A change is recorded for each
+// deleted warrant so that consumers of the changelog see it disappear.
+func (svc WarrantService) DeleteExpired(ctx context.Context, limit int) (int64, error) {
+	var numDeleted int64
+	_, err := svc.Env().DB().WithinConsistentTransaction(ctx, func(txCtx context.Context) error {
+		expired := true
+		warrants, err := svc.repository.ListByFilter(txCtx, FilterParams{Expired: &expired}, limit)
+		if err != nil {
+			return err
+		}
+
+		if len(warrants) == 0 {
+			return nil
+		}
+
+		err = svc.deleteListed(txCtx, warrants)
+		if err != nil {
+			return err
+		}
+
+		numDeleted = int64(len(warrants))
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	if numDeleted > 0 {
+		svc.NotifyWrite(ctx)
+	}
+	return numDeleted, nil
 }
@@ -25,6 +25,11 @@ import (
 
 const Wildcard = "*"
 
+const (
+	EffectAllow = "allow"
+	EffectDeny  = "deny"
+)
+
 type WarrantSpec struct {
 	ObjectType string            `json:"objectType"`
 	ObjectId   string            `json:"objectId"`
@@ -32,9 +37,21 @@ type WarrantSpec struct {
 	Subject    *SubjectSpec      `json:"subject"`
 	Context    map[string]string `json:"context,omitempty"`
 	Policy     Policy            `json:"policy,omitempty"`
+	Effect     string            `json:"effect,omitempty"`
+	ExpiresAt  *time.Time        `json:"expiresAt,omitempty"`
 	CreatedAt  time.Time         `json:"createdAt,omitempty"`
 }
 
+// IsDeny returns true if the warrant excludes its subject from the relation.
+func (spec WarrantSpec) IsDeny() bool {
+	return spec.Effect == EffectDeny
+}
+
+// IsExpired returns true if the warrant has an expiration at or before now.
+func (spec WarrantSpec) IsExpired(now time.Time) bool {
+	return spec.ExpiresAt != nil && !spec.ExpiresAt.After(now)
+}
+
 func (spec WarrantSpec) String() string {
 	str := fmt.Sprintf(
 		"%s:%s#%s@%s",
@@ -48,11 +65,20 @@ func (spec WarrantSpec) String() string {
 		str = fmt.Sprintf("%s[%s]", str, spec.Policy)
 	}
 
+	if spec.Effect == EffectDeny {
+		str = fmt.Sprintf("!%s", str)
+	}
+
 	return str
 }
 
 func StringToWarrantSpec(str string) (*WarrantSpec, error) {
 	var spec WarrantSpec
+	if denyStr, isDeny := strings.CutPrefix(str, "!"); isDeny {
+		spec.Effect = EffectDeny
+		str = denyStr
+	}
+
 	object, rest, found := strings.Cut(str, "#")
 	if !found {
 		return nil, errors.New(fmt.Sprintf("invalid warrant string %s", str))
@@ -91,12 +117,15 @@ func StringToWarrantSpec(str string) (*WarrantSpec, error) {
 }
 
 type CreateWarrantSpec struct {
-	ObjectType string            `json:"objectType"        validate:"required,valid_object_type"`
-	ObjectId   string            `json:"objectId"          validate:"required,valid_object_id"`
-	Relation   string            `json:"relation"          validate:"required,valid_relation"`
-	Subject    *SubjectSpec      `json:"subject"           validate:"required"`
-	Context    map[string]string `json:"context,omitempty" validate:"excluded_with=Policy"`
-	Policy     Policy            `json:"policy,omitempty"  validate:"excluded_with=Context"`
+	ObjectType    string             `json:"objectType"              validate:"required,valid_object_type"`
+	ObjectId      string             `json:"objectId"                validate:"required,valid_object_id"`
+	Relation      string             `json:"relation"                validate:"required,valid_relation"`
+	Subject       *SubjectSpec       `json:"subject"                 validate:"required"`
+	Context       map[string]string  `json:"context,omitempty"       validate:"excluded_with=Policy"`
+	Policy        Policy             `json:"policy,omitempty"        validate:"excluded_with=Context"`
+	Effect        string             `json:"effect,omitempty"        validate:"omitempty,oneof=allow deny"`
+	ExpiresAt     *time.Time         `json:"expiresAt,omitempty"`
+	Preconditions *PreconditionsSpec `json:"preconditions,omitempty"`
 }
 
 func (spec CreateWarrantSpec) ToWarrant() (*Warrant, error) {
@@ -134,6 +163,19 @@ func (spec CreateWarrantSpec) ToWarrant() (*Warrant, error) {
 		warrant.PolicyHash = spec.Policy.Hash()
 	}
 
+	if spec.Effect == EffectDeny {
+		warrant.Effect = EffectDeny
+	}
+
+	if spec.ExpiresAt != nil {
+		if !spec.ExpiresAt.After(time.Now()) {
+			return nil, service.NewInvalidParameterError("expiresAt", "must be in the future")
+		}
+
+		expiresAt := spec.ExpiresAt.UTC()
+		warrant.ExpiresAt = &expiresAt
+	}
+
 	return warrant, nil
 }
 
@@ -150,16 +192,22 @@ func (spec CreateWarrantSpec) String() string {
 		str = fmt.Sprintf("%s[%s]", str, spec.Policy)
 	}
 
+	if spec.Effect == EffectDeny {
+		str = fmt.Sprintf("!%s", str)
+	}
+
 	return str
 }
 
 type DeleteWarrantSpec struct {
-	ObjectType string            `json:"objectType"        validate:"required,valid_object_type"`
-	ObjectId   string            `json:"objectId"          validate:"required,valid_object_id"`
-	Relation   string            `json:"relation"          validate:"required,valid_relation"`
-	Subject    *SubjectSpec      `json:"subject"           validate:"required"`
-	Context    map[string]string `json:"context,omitempty" validate:"excluded_with=Policy"`
-	Policy     Policy            `json:"policy,omitempty"  validate:"excluded_with=Context"`
+	ObjectType    string             `json:"objectType"              validate:"required,valid_object_type"`
+	ObjectId      string             `json:"objectId"                validate:"required,valid_object_id"`
+	Relation      string             `json:"relation"                validate:"required,valid_relation"`
+	Subject       *SubjectSpec       `json:"subject"                 validate:"required"`
+	Context       map[string]string  `json:"context,omitempty"       validate:"excluded_with=Policy"`
+	Policy        Policy             `json:"policy,omitempty"        validate:"excluded_with=Context"`
+	Effect        string             `json:"effect,omitempty"        validate:"omitempty,oneof=allow deny"`
+	Preconditions *PreconditionsSpec `json:"preconditions,omitempty"`
 }
 
 func (spec DeleteWarrantSpec) ToWarrant() (*Warrant, error) {
@@ -197,6 +245,10 @@ func (spec DeleteWarrantSpec) ToWarrant() (*Warrant, error) {
 		warrant.PolicyHash = spec.Policy.Hash()
 	}
 
+	if spec.Effect == EffectDeny {
+		warrant.Effect = EffectDeny
+	}
+
 	return warrant, nil
 }
 
@@ -213,9 +265,81 @@ func (spec DeleteWarrantSpec) String() string {
 		str = fmt.Sprintf("%s[%s]", str, spec.Policy)
 	}
 
+	if spec.Effect == EffectDeny {
+		str = fmt.Sprintf("!%s", str)
+	}
+
 	return str
 }
 
+// Warrants that must or must not exist for a write to be applied.
+type PreconditionsSpec struct {
+	Exists    []PreconditionWarrantSpec `json:"exists,omitempty"    validate:"dive"`
+	NotExists []PreconditionWarrantSpec `json:"notExists,omitempty" validate:"dive"`
+}
+
+type PreconditionWarrantSpec struct {
+	ObjectType string       `json:"objectType"       validate:"required,valid_object_type"`
+	ObjectId   string       `json:"objectId"         validate:"required,valid_object_id"`
+	Relation   string       `json:"relation"         validate:"required,valid_relation"`
+	Subject    *SubjectSpec `json:"subject"          validate:"required"`
+	Policy     Policy       `json:"policy,omitempty"`
+	Effect     string       `json:"effect,omitempty" validate:"omitempty,oneof=allow deny"`
+}
+
+func (spec PreconditionWarrantSpec) ToWarrant() (*Warrant, error) {
+	return DeleteWarrantSpec{
+		ObjectType: spec.ObjectType,
+		ObjectId:   spec.ObjectId,
+		Relation:   spec.Relation,
+		Subject:    spec.Subject,
+		Policy:     spec.Policy,
+		Effect:     spec.Effect,
+	}.ToWarrant()
+}
+
+const (
+	OpCreate = "create"
+	OpDelete = "delete"
+)
+
+type WarrantOperationSpec struct {
+	Op string `json:"op" validate:"required,oneof=create delete"`
+	CreateWarrantSpec
+}
+
+func (spec WarrantOperationSpec) ToDeleteWarrantSpec() DeleteWarrantSpec {
+	return DeleteWarrantSpec{
+		ObjectType:    spec.ObjectType,
+		ObjectId:      spec.ObjectId,
+		Relation:      spec.Relation,
+		Subject:       spec.Subject,
+		Context:       spec.Context,
+		Policy:        spec.Policy,
+		Effect:        spec.Effect,
+		Preconditions: spec.Preconditions,
+	}
+}
+
+type BatchWarrantSpec struct {
+	Operations []WarrantOperationSpec `json:"operations" validate:"min=1,dive"`
+}
+
+type WarrantOperationResultSpec struct {
+	Op      string       `json:"op"`
+	Warrant *WarrantSpec `json:"warrant"`
+}
+
+type BatchWarrantResultSpec struct {
+	Results []WarrantOperationResultSpec `json:"results"`
+}
+
+type DeleteWarrantsResultSpec struct {
+	DryRun bool          `json:"dryRun,omitempty"`
+	Count  int64         `json:"count"`
+	Sample []WarrantSpec `json:"sample,omitempty"`
+}
+
 type ListWarrantsSpecV1 []WarrantSpec
 
 type ListWarrantsSpecV2 struct {
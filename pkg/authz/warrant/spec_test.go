@@ -18,6 +18,7 @@ import (
 	"fmt"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 )
@@ -216,6 +217,55 @@ func TestCreateSpecToWarrantIndirectWarrantWithContext(t *testing.T) {
 	}
 }
 
+func TestCreateSpecToWarrantDenyWarrant(t *testing.T) {
+	t.Parallel()
+	spec := CreateWarrantSpec{
+		ObjectType: "permission",
+		ObjectId:   "test",
+		Relation:   "member",
+		Subject: &SubjectSpec{
+			ObjectType: "user",
+			ObjectId:   "user-A",
+		},
+		Effect: EffectDeny,
+	}
+	expectedWarrant := &Warrant{
+		ObjectType:  "permission",
+		ObjectId:    "test",
+		Relation:    "member",
+		SubjectType: "user",
+		SubjectId:   "user-A",
+		Effect:      EffectDeny,
+	}
+	actualWarrant, err := spec.ToWarrant()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !cmp.Equal(actualWarrant, expectedWarrant) {
+		t.Fatalf("Expected warrant to be %v, but it was %v", expectedWarrant, actualWarrant)
+	}
+}
+
+func TestCreateSpecToWarrantExpiredWarrant(t *testing.T) {
+	t.Parallel()
+	expiresAt := time.Now().Add(-time.Minute)
+	spec := CreateWarrantSpec{
+		ObjectType: "permission",
+		ObjectId:   "test",
+		Relation:   "member",
+		Subject: &SubjectSpec{
+			ObjectType: "user",
+			ObjectId:   "user-A",
+		},
+		ExpiresAt: &expiresAt,
+	}
+	_, err := spec.ToWarrant()
+	if err == nil {
+		t.Fatalf("Expected error for warrant with expiresAt in the past, but got nil")
+	}
+}
+
 func TestToStringDirectWarrantSpec(t *testing.T) {
 	t.Parallel()
 	spec := WarrantSpec{
@@ -292,6 +342,25 @@ func TestToStringIndirectWarrantSpecWithPolicy(t *testing.T) {
 	}
 }
 
+func TestToStringDenyWarrantSpec(t *testing.T) {
+	t.Parallel()
+	spec := WarrantSpec{
+		ObjectType: "permission",
+		ObjectId:   "test",
+		Relation:   "member",
+		Subject: &SubjectSpec{
+			ObjectType: "user",
+			ObjectId:   "user-A",
+		},
+		Effect: EffectDeny,
+	}
+	expectedWarrantStr := "!permission:test#member@user:user-A"
+	actualWarrantStr := spec.String()
+	if actualWarrantStr != expectedWarrantStr {
+		t.Fatalf("Expected spec string to be %s, but it was %s", expectedWarrantStr, actualWarrantStr)
+	}
+}
+
 func TestStringToWarrantSpecDirectWarrantSpec(t *testing.T) {
 	t.Parallel()
 	warrantStr := "permission:test#member@user:user-A"
@@ -384,6 +453,30 @@ func TestStringToWarrantSpecIndirectWarrantSpecWithPolicy(t *testing.T) {
 	}
 }
 
+func TestStringToWarrantSpecDenyWarrantSpec(t *testing.T) {
+	t.Parallel()
+	warrantStr := "!permission:test#member@user:user-A[tenant == 101]"
+	expectedWarrantSpec := &WarrantSpec{
+		ObjectType: "permission",
+		ObjectId:   "test",
+		Relation:   "member",
+		Subject: &SubjectSpec{
+			ObjectType: "user",
+			ObjectId:   "user-A",
+		},
+		Policy: "tenant == 101",
+		Effect: EffectDeny,
+	}
+	actualWarrantSpec, err := StringToWarrantSpec(warrantStr)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !cmp.Equal(actualWarrantSpec, expectedWarrantSpec) {
+		t.Fatalf("Expected warrant spec to be %v, but it was %v", expectedWarrantSpec, actualWarrantSpec)
+	}
+}
+
 func TestStringToWarrantSpecInvalidObject(t *testing.T) {
 	t.Parallel()
 	warrantStr := "permissiontest#member@user:user:-A"
@@ -19,6 +19,7 @@ import (
 	"database/sql"
 	"fmt"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/pkg/errors"
@@ -52,12 +53,15 @@ func (repo SQLiteRepository) Create(ctx context.Context, model Model) (int64, er
 				subjectRelation,
 				policy,
 				policyHash,
+				effect,
+				expiresAt,
 				createdAt,
 				updatedAt
-			) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
-			ON CONFLICT (objectType, objectId, relation, subjectType, subjectId, subjectRelation, policyHash) DO UPDATE SET
+			) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+			ON CONFLICT (objectType, objectId, relation, subjectType, subjectId, subjectRelation, policyHash, effect) DO UPDATE SET
 				createdAt = IIF(warrant.deletedAt IS NULL, warrant.createdAt, ?),
 				updatedAt = ?,
+				expiresAt = excluded.expiresAt,
 				deletedAt = NULL
 			RETURNING id
 		`,
@@ -69,6 +73,8 @@ func (repo SQLiteRepository) Create(ctx context.Context, model Model) (int64, er
 		model.GetSubjectRelation(),
 		model.GetPolicy(),
 		model.GetPolicy().Hash(),
+		model.GetEffect(),
+		model.GetExpiresAt(),
 		now,
 		now,
 		now,
@@ -81,7 +87,7 @@ func (repo SQLiteRepository) Create(ctx context.Context, model Model) (int64, er
 	return newWarrantId, nil
 }
 
-func (repo SQLiteRepository) Delete(ctx context.Context, objectType string, objectId string, relation string, subjectType string, subjectId string, subjectRelation string, policyHash string) error {
+func (repo SQLiteRepository) Delete(ctx context.Context, objectType string, objectId string, relation string, subjectType string, subjectId string, subjectRelation string, policyHash string, effect string) error {
 	now := time.Now().UTC()
 	_, err := repo.DB.ExecContext(
 		ctx,
@@ -98,6 +104,7 @@ func (repo SQLiteRepository) Delete(ctx context.Context, objectType string, obje
 				subjectId = ? AND
 				subjectRelation = ? AND
 				policyHash = ? AND
+				effect = ? AND
 				deletedAt IS NULL
 		`,
 		now,
@@ -109,6 +116,7 @@ func (repo SQLiteRepository) Delete(ctx context.Context, objectType string, obje
 		subjectId,
 		subjectRelation,
 		policyHash,
+		effect,
 	)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
@@ -119,6 +127,9 @@ func (repo SQLiteRepository) Delete(ctx context.Context, objectType string, obje
 			if policyHash != "" {
 				wntErrorId = fmt.Sprintf("%s[%s]", wntErrorId, policyHash)
 			}
+			if effect == EffectDeny {
+				wntErrorId = fmt.Sprintf("!%s", wntErrorId)
+			}
 
 			return service.NewRecordNotFoundError("Warrant", wntErrorId)
 		}
@@ -128,13 +139,149 @@ func (repo SQLiteRepository) Delete(ctx context.Context, objectType string, obje
 	return nil
 }
 
-func (repo SQLiteRepository) Get(ctx context.Context, objectType string, objectId string, relation string, subjectType string, subjectId string, subjectRelation string, policyHash string) (Model, error) {
+func (repo SQLiteRepository) DeleteByIds(ctx context.Context, ids []int64) (int64, error) {
+	now := time.Now().UTC()
+	replacements := []interface{}{now, now}
+	for _, id := range ids {
+		replacements = append(replacements, id)
+	}
+
+	result, err := repo.DB.ExecContext(
+		ctx,
+		fmt.Sprintf(`
+			UPDATE warrant
+			SET
+				updatedAt = ?,
+				deletedAt = ?
+			WHERE
+				id IN (%s) AND
+				deletedAt IS NULL
+		`, BuildQuestionMarkString(len(ids))),
+		replacements...,
+	)
+	if err != nil {
+		return 0, errors.Wrap(err, "error deleting warrants")
+	}
+
+	numDeleted, err := result.RowsAffected()
+	if err != nil {
+		return 0, errors.Wrap(err, "error deleting warrants")
+	}
+
+	return numDeleted, nil
+}
+
+func (repo SQLiteRepository) CountByFilter(ctx context.Context, filterParams FilterParams) (int64, error) {
+	var count int64
+	conditions, replacements := buildSQLiteFilterConditions(filterParams)
+	err := repo.DB.GetContext(
+		ctx,
+		&count,
+		fmt.Sprintf(`
+			SELECT COUNT(*)
+			FROM warrant
+			WHERE
+				%s
+		`, conditions),
+		replacements...,
+	)
+	if err != nil {
+		return 0, errors.Wrap(err, "error counting warrants")
+	}
+
+	return count, nil
+}
+
+func (repo SQLiteRepository) ListByFilter(ctx context.Context, filterParams FilterParams, limit int) ([]Model, error) {
+	models := make([]Model, 0)
+	warrants := make([]Warrant, 0)
+	conditions, replacements := buildSQLiteFilterConditions(filterParams)
+	err := repo.DB.SelectContext(
+		ctx,
+		&warrants,
+		fmt.Sprintf(`
+			SELECT id, objectType, objectId, relation, subjectType, subjectId, subjectRelation, policy, effect, expiresAt, createdAt, updatedAt, deletedAt
+			FROM warrant
+			WHERE
+				%s
+			ORDER BY id ASC
+			LIMIT ?
+		`, conditions),
+		append(replacements, limit)...,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models, nil
+		}
+		return nil, errors.Wrap(err, "error listing warrants")
+	}
+
+	for i := range warrants {
+		models = append(models, &warrants[i])
+	}
+
+	return models, nil
+}
+
+// Builds the WHERE conditions matching warrants by filterParams. Unlike List,
+// objectId and subjectId match exactly, so wildcard warrants only match a '*' filter.
+func buildSQLiteFilterConditions(filterParams FilterParams) (string, []interface{}) {
+	conditions := []string{"deletedAt IS NULL"}
+	var replacements []interface{}
+	if len(filterParams.ObjectType) > 0 {
+		conditions = append(conditions, "objectType = ?")
+		replacements = append(replacements, filterParams.ObjectType)
+	}
+
+	if len(filterParams.ObjectId) > 0 {
+		conditions = append(conditions, "objectId = ?")
+		replacements = append(replacements, filterParams.ObjectId)
+	}
+
+	if len(filterParams.Relation) > 0 {
+		conditions = append(conditions, "relation = ?")
+		replacements = append(replacements, filterParams.Relation)
+	}
+
+	if len(filterParams.SubjectType) > 0 {
+		conditions = append(conditions, "subjectType = ?")
+		replacements = append(replacements, filterParams.SubjectType)
+	}
+
+	if len(filterParams.SubjectId) > 0 {
+		conditions = append(conditions, "subjectId = ?")
+		replacements = append(replacements, filterParams.SubjectId)
+	}
+
+	if len(filterParams.SubjectRelation) > 0 {
+		conditions = append(conditions, "subjectRelation = ?")
+		replacements = append(replacements, filterParams.SubjectRelation)
+	}
+
+	if len(filterParams.PolicyHash) > 0 {
+		conditions = append(conditions, "policyHash = ?")
+		replacements = append(replacements, filterParams.PolicyHash)
+	}
+
+	if filterParams.Expired != nil {
+		if *filterParams.Expired {
+			conditions = append(conditions, "expiresAt <= ?")
+		} else {
+			conditions = append(conditions, "(expiresAt IS NULL OR expiresAt > ?)")
+		}
+		replacements = append(replacements, time.Now().UTC())
+	}
+
+	return strings.Join(conditions, " AND "), replacements
+}
+
+func (repo SQLiteRepository) Get(ctx context.Context, objectType string, objectId string, relation string, subjectType string, subjectId string, subjectRelation string, policyHash string, effect string) (Model, error) {
 	var warrant Warrant
 	err := repo.DB.GetContext(
 		ctx,
 		&warrant,
 		`
-			SELECT id, objectType, objectId, relation, subjectType, subjectId, subjectRelation, policy, createdAt, updatedAt, deletedAt
+			SELECT id, objectType, objectId, relation, subjectType, subjectId, subjectRelation, policy, effect, expiresAt, createdAt, updatedAt, deletedAt
 			FROM warrant
 			WHERE
 				objectType = ? AND
@@ -144,6 +291,7 @@ func (repo SQLiteRepository) Get(ctx context.Context, objectType string, objectI
 				subjectId = ? AND
 				subjectRelation = ? AND
 				policyHash = ? AND
+				effect = ? AND
 				deletedAt IS NULL
 		`,
 		objectType,
@@ -153,6 +301,7 @@ func (repo SQLiteRepository) Get(ctx context.Context, objectType string, objectI
 		subjectId,
 		subjectRelation,
 		policyHash,
+		effect,
 	)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
@@ -163,6 +312,9 @@ func (repo SQLiteRepository) Get(ctx context.Context, objectType string, objectI
 			if policyHash != "" {
 				wntErrorId = fmt.Sprintf("%s[%s]", wntErrorId, policyHash)
 			}
+			if effect == EffectDeny {
+				wntErrorId = fmt.Sprintf("!%s", wntErrorId)
+			}
 
 			return nil, service.NewRecordNotFoundError("Warrant", wntErrorId)
 		}
@@ -178,7 +330,7 @@ func (repo SQLiteRepository) GetByID(ctx context.Context, id int64) (Model, erro
 		ctx,
 		&warrant,
 		`
-			SELECT id, objectType, objectId, relation, subjectType, subjectId, subjectRelation, policy, createdAt, updatedAt, deletedAt
+			SELECT id, objectType, objectId, relation, subjectType, subjectId, subjectRelation, policy, effect, expiresAt, createdAt, updatedAt, deletedAt
 			FROM warrant
 			WHERE
 				id = ? AND
@@ -200,7 +352,7 @@ func (repo SQLiteRepository) List(ctx context.Context, filterParams FilterParams
 	models := make([]Model, 0)
 	warrants := make([]Warrant, 0)
 	query := `
-		SELECT id, objectType, objectId, relation, subjectType, subjectId, subjectRelation, policy, createdAt, updatedAt, deletedAt
+		SELECT id, objectType, objectId, relation, subjectType, subjectId, subjectRelation, policy, effect, expiresAt, createdAt, updatedAt, deletedAt
 		FROM warrant
 		WHERE
 			deletedAt IS NULL
@@ -238,6 +390,20 @@ func (repo SQLiteRepository) List(ctx context.Context, filterParams FilterParams
 		replacements = append(replacements, filterParams.SubjectRelation)
 	}
 
+	if len(filterParams.PolicyHash) > 0 {
+		query = fmt.Sprintf("%s AND policyHash = ?", query)
+		replacements = append(replacements, filterParams.PolicyHash)
+	}
+
+	if filterParams.Expired != nil {
+		if *filterParams.Expired {
+			query = fmt.Sprintf("%s AND expiresAt <= ?", query)
+		} else {
+			query = fmt.Sprintf("%s AND (expiresAt IS NULL OR expiresAt > ?)", query)
+		}
+		replacements = append(replacements, time.Now().UTC())
+	}
+
 	if listParams.NextCursor != nil {
 		comparisonOp := "<"
 		if listParams.SortOrder == service.SortOrderAsc {
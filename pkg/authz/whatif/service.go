@@ -0,0 +1,206 @@
+// Copyright 2024 WorkOS, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package authz
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	objecttype "github.com/warrant-dev/warrant/pkg/authz/objecttype"
+	warrant "github.com/warrant-dev/warrant/pkg/authz/warrant"
+	"github.com/warrant-dev/warrant/pkg/service"
+	"github.com/warrant-dev/warrant/pkg/wookie"
+)
+
+var ErrReadOnly = errors.New("whatif: writes are not supported")
+
+// WarrantService is a read-only warrant.Service that lists warrants as if the
+// warrants added and removed by a WhatIfSpec were persisted.
+type WarrantService struct {
+	base    warrant.Service
+	added   []warrant.WarrantSpec
+	removed map[string]bool
+}
+
+func NewWarrantService(base warrant.Service, spec WhatIfSpec) (*WarrantService, error) {
+	svc := &WarrantService{
+		base:    base,
+		added:   make([]warrant.WarrantSpec, 0),
+		removed: make(map[string]bool),
+	}
+
+	for _, removeSpec := range spec.Remove {
+		w, err := removeSpec.ToWarrant()
+		if err != nil {
+			return nil, err
+		}
+		svc.removed[w.ToWarrantSpec().String()] = true
+	}
+
+	for _, addSpec := range spec.Add {
+		w, err := addSpec.ToWarrant()
+		if err != nil {
+			return nil, err
+		}
+		warrantSpec := w.ToWarrantSpec()
+		if !svc.removed[warrantSpec.String()] {
+			svc.added = append(svc.added, *warrantSpec)
+		}
+	}
+
+	return svc, nil
+}
+
+func (svc WarrantService) Create(ctx context.Context, spec warrant.CreateWarrantSpec) (*warrant.WarrantSpec, *wookie.Token, error) {
+	return nil, nil, ErrReadOnly
+}
+
+// List lists persisted warrants except those removed, followed by the added
+// warrants matching filterParams on the last page.
+func (svc WarrantService) List(ctx context.Context, filterParams warrant.FilterParams, listParams service.ListParams) ([]warrant.WarrantSpec, *service.Cursor, *service.Cursor, error) {
+	warrantSpecs, prevCursor, nextCursor, err := svc.base.List(ctx, filterParams, listParams)
+	if err != nil {
+		return warrantSpecs, prevCursor, nextCursor, err
+	}
+
+	whatIfWarrantSpecs := make([]warrant.WarrantSpec, 0, len(warrantSpecs))
+	for _, warrantSpec := range warrantSpecs {
+		if !svc.removed[warrantSpec.String()] {
+			whatIfWarrantSpecs = append(whatIfWarrantSpecs, warrantSpec)
+		}
+	}
+
+	if nextCursor == nil {
+		for _, warrantSpec := range svc.added {
+			if matchesFilterParams(warrantSpec, filterParams) {
+				whatIfWarrantSpecs = append(whatIfWarrantSpecs, warrantSpec)
+			}
+		}
+	}
+
+	return whatIfWarrantSpecs, prevCursor, nextCursor, nil
+}
+
+func (svc WarrantService) Delete(ctx context.Context, spec warrant.DeleteWarrantSpec) (*wookie.Token, error) {
+	return nil, ErrReadOnly
+}
+
+// Mirrors the filtering done by warrant repositories, where a filter on
+// objectId or subjectId also matches wildcard warrants.
+func matchesFilterParams(warrantSpec warrant.WarrantSpec, filterParams warrant.FilterParams) bool {
+	if filterParams.ObjectType != "" && warrantSpec.ObjectType != filterParams.ObjectType {
+		return false
+	}
+	if filterParams.ObjectId != "" && filterParams.ObjectId != warrant.Wildcard && warrantSpec.ObjectId != filterParams.ObjectId && warrantSpec.ObjectId != warrant.Wildcard {
+		return false
+	}
+	if filterParams.Relation != "" && warrantSpec.Relation != filterParams.Relation {
+		return false
+	}
+	if filterParams.SubjectType != "" && warrantSpec.Subject.ObjectType != filterParams.SubjectType {
+		return false
+	}
+	if filterParams.SubjectId != "" && warrantSpec.Subject.ObjectId != filterParams.SubjectId && warrantSpec.Subject.ObjectId != warrant.Wildcard {
+		return false
+	}
+	if filterParams.SubjectRelation != "" && warrantSpec.Subject.Relation != filterParams.SubjectRelation {
+		return false
+	}
+	if filterParams.PolicyHash != "" && warrantSpec.Policy.Hash() != filterParams.PolicyHash {
+		return false
+	}
+	if filterParams.Expired != nil && warrantSpec.IsExpired(time.Now()) != *filterParams.Expired {
+		return false
+	}
+	return true
+}
+
+// ObjectTypeService is a read-only objecttype.Service that returns object
+// types as if the object type of a WhatIfSpec replaced any existing definition.
+type ObjectTypeService struct {
+	base     objecttype.Service
+	override *objecttype.ObjectTypeSpec
+}
+
+func NewObjectTypeService(base objecttype.Service, spec WhatIfSpec) (*ObjectTypeService, error) {
+	svc := &ObjectTypeService{
+		base: base,
+	}
+
+	if spec.ObjectType != nil {
+		objectType, err := spec.ObjectType.ToObjectType()
+		if err != nil {
+			return nil, err
+		}
+
+		svc.override, err = objectType.ToObjectTypeSpec()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return svc, nil
+}
+
+func (svc ObjectTypeService) Create(ctx context.Context, spec objecttype.CreateObjectTypeSpec) (*objecttype.ObjectTypeSpec, *wookie.Token, error) {
+	return nil, nil, ErrReadOnly
+}
+
+func (svc ObjectTypeService) GetByTypeId(ctx context.Context, typeId string) (*objecttype.ObjectTypeSpec, error) {
+	if svc.override != nil && svc.override.Type == typeId {
+		overrideSpec := *svc.override
+		return &overrideSpec, nil
+	}
+
+	return svc.base.GetByTypeId(ctx, typeId)
+}
+
+// List lists persisted object types with the overriding object type in place
+// of its persisted definition, or on the last page if it is not persisted.
+func (svc ObjectTypeService) List(ctx context.Context, listParams service.ListParams) ([]objecttype.ObjectTypeSpec, *service.Cursor, *service.Cursor, error) {
+	objectTypeSpecs, prevCursor, nextCursor, err := svc.base.List(ctx, listParams)
+	if err != nil || svc.override == nil {
+		return objectTypeSpecs, prevCursor, nextCursor, err
+	}
+
+	for i := range objectTypeSpecs {
+		if objectTypeSpecs[i].Type == svc.override.Type {
+			objectTypeSpecs[i] = *svc.override
+		}
+	}
+
+	if nextCursor == nil {
+		_, err := svc.base.GetByTypeId(ctx, svc.override.Type)
+		if err != nil {
+			var recordNotFoundError *service.RecordNotFoundError
+			if !errors.As(err, &recordNotFoundError) {
+				return nil, nil, nil, err
+			}
+
+			objectTypeSpecs = append(objectTypeSpecs, *svc.override)
+		}
+	}
+
+	return objectTypeSpecs, prevCursor, nextCursor, nil
+}
+
+func (svc ObjectTypeService) UpdateByTypeId(ctx context.Context, typeId string, spec objecttype.UpdateObjectTypeSpec) (*objecttype.ObjectTypeSpec, *wookie.Token, error) {
+	return nil, nil, ErrReadOnly
+}
+
+func (svc ObjectTypeService) DeleteByTypeId(ctx context.Context, typeId string) (*wookie.Token, error) {
+	return nil, ErrReadOnly
+}
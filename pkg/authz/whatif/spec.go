@@ -0,0 +1,28 @@
+// Copyright 2024 WorkOS, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package authz
+
+import (
+	objecttype "github.com/warrant-dev/warrant/pkg/authz/objecttype"
+	warrant "github.com/warrant-dev/warrant/pkg/authz/warrant"
+)
+
+// WhatIfSpec describes hypothetical changes that checks and queries are
+// evaluated against without persisting them.
+type WhatIfSpec struct {
+	Add        []warrant.CreateWarrantSpec      `json:"add,omitempty"        validate:"dive"`
+	Remove     []warrant.DeleteWarrantSpec      `json:"remove,omitempty"     validate:"dive"`
+	ObjectType *objecttype.CreateObjectTypeSpec `json:"objectType,omitempty"`
+}
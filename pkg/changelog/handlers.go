@@ -0,0 +1,163 @@
+// Copyright 2024 WorkOS, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package changelog
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/hlog"
+	"github.com/warrant-dev/warrant/pkg/service"
+	"github.com/warrant-dev/warrant/pkg/wookie"
+)
+
+// How often a comment is sent to streaming clients while there are no new
+// changes so that idle connections aren't closed.
+const keepAliveInterval = 15 * time.Second
+
+func (svc ChangeService) Routes() ([]service.Route, error) {
+	return []service.Route{
+		// list
+		service.WarrantRoute{
+			Pattern: "/v2/changes",
+			Method:  "GET",
+			Handler: service.ChainMiddleware(
+				service.NewRouteHandler(svc, listHandler),
+				service.ListMiddleware[ChangeListParamParser],
+			),
+		},
+	}, nil
+}
+
+func listHandler(svc ChangeService, w http.ResponseWriter, r *http.Request) error {
+	listParams := service.GetListParamsFromContext[ChangeListParamParser](r.Context())
+	if listParams.PrevCursor != nil {
+		return service.NewInvalidParameterError("prevCursor", "is not supported for changes")
+	}
+
+	if listParams.SortOrder != service.SortOrderAsc {
+		return service.NewInvalidParameterError("sortOrder", "must be ASC")
+	}
+
+	queryParams := r.URL.Query()
+	var filterParams FilterParams
+	if queryParams.Has("since") {
+		token, err := wookie.FromString(queryParams.Get("since"))
+		if err != nil {
+			return service.NewInvalidParameterError("since", "must be a valid warrant token")
+		}
+		filterParams.SinceWookieId = token.ID
+	}
+
+	if strings.Contains(r.Header.Get("Accept"), "text/event-stream") {
+		lastEventId := r.Header.Get("Last-Event-ID")
+		if lastEventId != "" {
+			cursor, err := service.NewCursorFromBase64String(lastEventId, ChangeListParamParser{}, PrimarySortKey)
+			if err != nil {
+				return service.NewInvalidParameterError("Last-Event-ID", "invalid cursor")
+			}
+			listParams.NextCursor = cursor
+		}
+
+		return streamChanges(svc, w, r, filterParams, listParams)
+	}
+
+	var wait time.Duration
+	if queryParams.Has("wait") {
+		var err error
+		wait, err = time.ParseDuration(queryParams.Get("wait"))
+		if err != nil || wait < 0 {
+			return service.NewInvalidParameterError("wait", "must be a valid duration")
+		}
+
+		if wait > MaxWait {
+			return service.NewInvalidParameterError("wait", fmt.Sprintf("must be at most %s", MaxWait))
+		}
+	}
+
+	var changes []ChangeSpec
+	var nextCursor *service.Cursor
+	var err error
+	if wait > 0 {
+		changes, nextCursor, err = svc.Wait(r.Context(), filterParams, listParams, wait)
+	} else {
+		changes, nextCursor, err = svc.List(r.Context(), filterParams, listParams)
+	}
+	if err != nil {
+		return err
+	}
+
+	service.SendJSONResponse(w, ListChangesSpec{
+		Results:    changes,
+		NextCursor: nextCursor,
+	})
+	return nil
+}
+
+// Streams changes to the client as server-sent events until the client
+// disconnects. Each event's id is a cursor the client can resume from by
+// sending it in the Last-Event-ID header.
+func streamChanges(svc ChangeService, w http.ResponseWriter, r *http.Request, filterParams FilterParams, listParams service.ListParams) error {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		return errors.New("streaming is not supported")
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	for {
+		changes, nextCursor, err := svc.Wait(r.Context(), filterParams, listParams, keepAliveInterval)
+		if r.Context().Err() != nil {
+			return nil
+		}
+
+		// The response has already started, so errors can only be logged
+		if err != nil {
+			hlog.FromRequest(r).Error().Err(err).Msg("changelog: error streaming changes")
+			return nil
+		}
+
+		if len(changes) == 0 {
+			fmt.Fprint(w, ": keep-alive\n\n")
+		}
+
+		for _, change := range changes {
+			eventId, err := changeCursor(change).ToBase64String()
+			if err != nil {
+				hlog.FromRequest(r).Error().Err(err).Msg("changelog: error streaming changes")
+				return nil
+			}
+
+			data, err := json.Marshal(change)
+			if err != nil {
+				hlog.FromRequest(r).Error().Err(err).Msg("changelog: error streaming changes")
+				return nil
+			}
+
+			fmt.Fprintf(w, "id: %s\nevent: %s\ndata: %s\n\n", eventId, change.Type, data)
+		}
+
+		flusher.Flush()
+		listParams.NextCursor = nextCursor
+	}
+}
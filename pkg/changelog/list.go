@@ -0,0 +1,50 @@
+// Copyright 2024 WorkOS, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package changelog
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/pkg/errors"
+)
+
+const PrimarySortKey = "id"
+
+var supportedSortBys = []string{"id"}
+
+type ChangeListParamParser struct{}
+
+func (parser ChangeListParamParser) GetDefaultSortBy() string {
+	return "id"
+}
+
+func (parser ChangeListParamParser) GetSupportedSortBys() []string {
+	return supportedSortBys
+}
+
+func (parser ChangeListParamParser) ParseValue(val string, sortBy string) (interface{}, error) {
+	switch sortBy {
+	case "id":
+		value, err := strconv.ParseInt(val, 10, 64)
+		if err != nil {
+			return nil, errors.New("must be a valid id")
+		}
+
+		return value, nil
+	default:
+		return nil, errors.New(fmt.Sprintf("must match type of selected sortBy attribute %s", sortBy))
+	}
+}
@@ -0,0 +1,73 @@
+// Copyright 2024 WorkOS, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package changelog
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/warrant-dev/warrant/pkg/wookie"
+)
+
+type Model interface {
+	GetID() int64
+	GetWookieId() int64
+	GetType() string
+	GetData() string
+	GetCreatedAt() time.Time
+	ToChangeSpec() *ChangeSpec
+}
+
+type Change struct {
+	ID        int64     `mysql:"id"        postgres:"id"         sqlite:"id"`
+	WookieId  int64     `mysql:"wookieId"  postgres:"wookie_id"  sqlite:"wookieId"`
+	Type      string    `mysql:"type"      postgres:"type"       sqlite:"type"`
+	Data      string    `mysql:"data"      postgres:"data"       sqlite:"data"`
+	CreatedAt time.Time `mysql:"createdAt" postgres:"created_at" sqlite:"createdAt"`
+}
+
+func (change Change) GetID() int64 {
+	return change.ID
+}
+
+func (change Change) GetWookieId() int64 {
+	return change.WookieId
+}
+
+func (change Change) GetType() string {
+	return change.Type
+}
+
+func (change Change) GetData() string {
+	return change.Data
+}
+
+func (change Change) GetCreatedAt() time.Time {
+	return change.CreatedAt
+}
+
+func (change Change) ToChangeSpec() *ChangeSpec {
+	return &ChangeSpec{
+		ID:   change.ID,
+		Type: change.Type,
+		Data: json.RawMessage(change.Data),
+		WarrantToken: wookie.Token{
+			ID:        change.WookieId,
+			Version:   wookie.TokenVersion,
+			Timestamp: change.CreatedAt,
+		}.String(),
+		CreatedAt: change.CreatedAt,
+	}
+}
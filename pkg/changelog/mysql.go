@@ -0,0 +1,125 @@
+// Copyright 2024 WorkOS, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package changelog
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/pkg/errors"
+	"github.com/warrant-dev/warrant/pkg/database"
+)
+
+type MySQLRepository struct {
+	database.SQLRepository
+}
+
+func NewMySQLRepository(db *database.MySQL) *MySQLRepository {
+	return &MySQLRepository{
+		database.NewSQLRepository(&db.SQL),
+	}
+}
+
+func (repo MySQLRepository) Create(ctx context.Context, model Model) (int64, error) {
+	result, err := repo.DB.ExecContext(
+		ctx,
+		`
+			INSERT INTO changelog (
+				wookieId,
+				type,
+				data
+			) VALUES (?, ?, ?)
+		`,
+		model.GetWookieId(),
+		model.GetType(),
+		model.GetData(),
+	)
+	if err != nil {
+		return -1, errors.Wrap(err, "error creating change")
+	}
+
+	newChangeId, err := result.LastInsertId()
+	if err != nil {
+		return -1, errors.Wrap(err, "error creating change")
+	}
+
+	return newChangeId, nil
+}
+
+func (repo MySQLRepository) List(ctx context.Context, filterParams FilterParams, afterId int64, upToId int64, limit int) ([]Model, error) {
+	models := make([]Model, 0)
+	changes := make([]Change, 0)
+	// Read from the writer so that clients tailing the changelog don't fall behind replicas
+	err := repo.DB.SelectContext(
+		database.CtxWithWriterOverride(ctx),
+		&changes,
+		`
+			SELECT id, wookieId, type, data, createdAt
+			FROM changelog
+			WHERE
+				wookieId > ? AND
+				id > ? AND
+				id <= ?
+			ORDER BY id ASC
+			LIMIT ?
+		`,
+		filterParams.SinceWookieId,
+		afterId,
+		upToId,
+		limit,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models, nil
+		}
+		return nil, errors.Wrap(err, "error listing changes")
+	}
+
+	for i := range changes {
+		models = append(models, &changes[i])
+	}
+
+	return models, nil
+}
+
+func (repo MySQLRepository) ListPositions(ctx context.Context, afterId int64, limit int) ([]Model, error) {
+	models := make([]Model, 0)
+	changes := make([]Change, 0)
+	err := repo.DB.SelectContext(
+		database.CtxWithWriterOverride(ctx),
+		&changes,
+		`
+			SELECT id, createdAt
+			FROM changelog
+			WHERE id > ?
+			ORDER BY id ASC
+			LIMIT ?
+		`,
+		afterId,
+		limit,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models, nil
+		}
+		return nil, errors.Wrap(err, "error listing change positions")
+	}
+
+	for i := range changes {
+		models = append(models, &changes[i])
+	}
+
+	return models, nil
+}
@@ -0,0 +1,123 @@
+// Copyright 2024 WorkOS, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package changelog
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/pkg/errors"
+	"github.com/warrant-dev/warrant/pkg/database"
+)
+
+type PostgresRepository struct {
+	database.SQLRepository
+}
+
+func NewPostgresRepository(db *database.Postgres) *PostgresRepository {
+	return &PostgresRepository{
+		database.NewSQLRepository(&db.SQL),
+	}
+}
+
+func (repo PostgresRepository) Create(ctx context.Context, model Model) (int64, error) {
+	var newChangeId int64
+	err := repo.DB.GetContext(
+		database.CtxWithWriterOverride(ctx),
+		&newChangeId,
+		`
+			INSERT INTO changelog (
+				wookie_id,
+				type,
+				data
+			) VALUES (?, ?, ?)
+			RETURNING id
+		`,
+		model.GetWookieId(),
+		model.GetType(),
+		model.GetData(),
+	)
+	if err != nil {
+		return -1, errors.Wrap(err, "error creating change")
+	}
+
+	return newChangeId, nil
+}
+
+func (repo PostgresRepository) List(ctx context.Context, filterParams FilterParams, afterId int64, upToId int64, limit int) ([]Model, error) {
+	models := make([]Model, 0)
+	changes := make([]Change, 0)
+	// Read from the writer so that clients tailing the changelog don't fall behind replicas
+	err := repo.DB.SelectContext(
+		database.CtxWithWriterOverride(ctx),
+		&changes,
+		`
+			SELECT id, wookie_id, type, data, created_at
+			FROM changelog
+			WHERE
+				wookie_id > ? AND
+				id > ? AND
+				id <= ?
+			ORDER BY id ASC
+			LIMIT ?
+		`,
+		filterParams.SinceWookieId,
+		afterId,
+		upToId,
+		limit,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models, nil
+		}
+		return nil, errors.Wrap(err, "error listing changes")
+	}
+
+	for i := range changes {
+		models = append(models, &changes[i])
+	}
+
+	return models, nil
+}
+
+func (repo PostgresRepository) ListPositions(ctx context.Context, afterId int64, limit int) ([]Model, error) {
+	models := make([]Model, 0)
+	changes := make([]Change, 0)
+	err := repo.DB.SelectContext(
+		database.CtxWithWriterOverride(ctx),
+		&changes,
+		`
+			SELECT id, created_at
+			FROM changelog
+			WHERE id > ?
+			ORDER BY id ASC
+			LIMIT ?
+		`,
+		afterId,
+		limit,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models, nil
+		}
+		return nil, errors.Wrap(err, "error listing change positions")
+	}
+
+	for i := range changes {
+		models = append(models, &changes[i])
+	}
+
+	return models, nil
+}
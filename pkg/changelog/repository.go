@@ -0,0 +1,61 @@
+// Copyright 2024 WorkOS, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package changelog
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/pkg/errors"
+	"github.com/warrant-dev/warrant/pkg/database"
+)
+
+type ChangeRepository interface {
+	Create(ctx context.Context, change Model) (int64, error)
+	// List returns the changes with ids in (afterId, upToId] matching the
+	// filter params, ordered by id.
+	List(ctx context.Context, filterParams FilterParams, afterId int64, upToId int64, limit int) ([]Model, error)
+	// ListPositions returns the id and creation time of the changes after
+	// afterId, ordered by id and regardless of any filter params.
+	ListPositions(ctx context.Context, afterId int64, limit int) ([]Model, error)
+}
+
+func NewRepository(db database.Database) (ChangeRepository, error) {
+	switch db.Type() {
+	case database.TypeMySQL:
+		mysql, ok := db.(*database.MySQL)
+		if !ok {
+			return nil, errors.New(fmt.Sprintf("invalid %s database config", database.TypeMySQL))
+		}
+
+		return NewMySQLRepository(mysql), nil
+	case database.TypePostgres:
+		postgres, ok := db.(*database.Postgres)
+		if !ok {
+			return nil, errors.New(fmt.Sprintf("invalid %s database config", database.TypePostgres))
+		}
+
+		return NewPostgresRepository(postgres), nil
+	case database.TypeSQLite:
+		sqlite, ok := db.(*database.SQLite)
+		if !ok {
+			return nil, errors.New(fmt.Sprintf("invalid %s database config", database.TypeSQLite))
+		}
+
+		return NewSQLiteRepository(sqlite), nil
+	default:
+		return nil, errors.New(fmt.Sprintf("unsupported database type %s specified", db.Type()))
+	}
+}
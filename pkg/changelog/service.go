@@ -0,0 +1,228 @@
+// Copyright 2024 WorkOS, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package changelog
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/warrant-dev/warrant/pkg/database"
+	"github.com/warrant-dev/warrant/pkg/service"
+)
+
+// The max duration a request can wait for new changes.
+const MaxWait = time.Minute
+
+// How often waiting requests check for new changes. Writes made by this
+// instance wake them up immediately, but writes made by other instances are
+// only seen by polling.
+const pollInterval = time.Second
+
+// How long a gap in change ids is treated as a change that's still being
+// committed. Ids are handed out when a change is recorded rather than when it
+// commits, so a gap means an earlier transaction is either still in flight or
+// rolled back. Lists stop at a gap until it's filled or older than this, so
+// a rolled back transaction delays the changelog by up to this long, and a
+// change whose transaction runs longer than this can be skipped by clients
+// that listed past it.
+const inFlightTimeout = 10 * time.Second
+
+// ChangeListener is called with each recorded change within the transaction
+// recording it. Returning an error rolls back the transaction.
+type ChangeListener func(ctx context.Context, changeSpec ChangeSpec) error
+
+type ChangeService struct {
+	service.BaseService
+	repository      ChangeRepository
+	notifier        *changeNotifier
+	changeListeners []ChangeListener
+}
+
+func NewService(env service.Env, repository ChangeRepository) *ChangeService {
+	return &ChangeService{
+		BaseService: service.NewBaseService(env),
+		repository:  repository,
+		notifier:    newChangeNotifier(),
+	}
+}
+
+// RecordChange records a change in the changelog with the state of the
+// resource after the change, or before it for deletes. It must be called within
+// a consistent transaction so the change is tied to the transaction's wookie.
+func (svc ChangeService) RecordChange(ctx context.Context, changeType string, before interface{}, after interface{}) error {
+	wookieId, ok := database.WookieIdFromContext(ctx)
+	if !ok {
+		return errors.New(fmt.Sprintf("cannot record %s change outside of a consistent transaction", changeType))
+	}
+
+	data := after
+	if data == nil {
+		data = before
+	}
+	changeData, err := json.Marshal(data)
+	if err != nil {
+		return errors.Wrapf(err, "error marshaling %s change", changeType)
+	}
+
+	change := Change{
+		WookieId:  wookieId,
+		Type:      changeType,
+		Data:      string(changeData),
+		CreatedAt: time.Now().UTC(),
+	}
+	change.ID, err = svc.repository.Create(ctx, &change)
+	if err != nil {
+		return err
+	}
+
+	for _, listener := range svc.changeListeners {
+		err = listener(ctx, *change.ToChangeSpec())
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// AddChangeListener registers a ChangeListener. Listeners must be added
+// before the service's routes are created.
+func (svc *ChangeService) AddChangeListener(listener ChangeListener) {
+	svc.changeListeners = append(svc.changeListeners, listener)
+}
+
+// OnWrite wakes up requests waiting for new changes. It's registered as a
+// WriteListener on the services that record changes.
+func (svc ChangeService) OnWrite(ctx context.Context, objectTypes ...string) {
+	svc.notifier.notify()
+}
+
+// List returns changes in the order they were recorded, up to the first change
+// that may still be in flight. Unlike other lists, the next cursor is returned
+// even when there are no more changes so that clients can use it to fetch
+// changes made later.
+func (svc ChangeService) List(ctx context.Context, filterParams FilterParams, listParams service.ListParams) ([]ChangeSpec, *service.Cursor, error) {
+	var afterId int64
+	if listParams.NextCursor != nil {
+		var err error
+		afterId, err = strconv.ParseInt(listParams.NextCursor.ID(), 10, 64)
+		if err != nil {
+			return nil, nil, service.NewInvalidParameterError("nextCursor", "invalid cursor")
+		}
+	}
+
+	// Find the last change before any change that may still be in flight.
+	// Positions are read regardless of filterParams since filtered out
+	// changes would otherwise look like gaps.
+	positions, err := svc.repository.ListPositions(ctx, afterId, listParams.Limit)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	committedUpToId := afterId
+	for _, position := range positions {
+		if position.GetID() != committedUpToId+1 && time.Since(position.GetCreatedAt()) < inFlightTimeout {
+			break
+		}
+		committedUpToId = position.GetID()
+	}
+
+	nextCursor := listParams.NextCursor
+	if committedUpToId == afterId {
+		return []ChangeSpec{}, nextCursor, nil
+	}
+
+	changes, err := svc.repository.List(ctx, filterParams, afterId, committedUpToId, listParams.Limit)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	changeSpecs := make([]ChangeSpec, 0, len(changes))
+	for _, change := range changes {
+		changeSpecs = append(changeSpecs, *change.ToChangeSpec())
+	}
+
+	if len(changeSpecs) == listParams.Limit {
+		nextCursor = changeCursor(changeSpecs[len(changeSpecs)-1])
+	} else {
+		nextCursor = service.NewCursor(strconv.FormatInt(committedUpToId, 10), nil)
+	}
+
+	return changeSpecs, nextCursor, nil
+}
+
+// Wait lists changes like List, but if there are none, waits up to timeout
+// for changes to be made before returning.
+func (svc ChangeService) Wait(ctx context.Context, filterParams FilterParams, listParams service.ListParams, timeout time.Duration) ([]ChangeSpec, *service.Cursor, error) {
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+
+	for {
+		// Start listening before listing so that writes made while listing aren't missed
+		changed := svc.notifier.wait()
+		changeSpecs, nextCursor, err := svc.List(ctx, filterParams, listParams)
+		if err != nil || len(changeSpecs) > 0 {
+			return changeSpecs, nextCursor, err
+		}
+
+		// Skip past changes that didn't match filterParams
+		listParams.NextCursor = nextCursor
+
+		select {
+		case <-ctx.Done():
+			return changeSpecs, nextCursor, nil
+		case <-timer.C:
+			return changeSpecs, nextCursor, nil
+		case <-changed:
+		case <-ticker.C:
+		}
+	}
+}
+
+func changeCursor(changeSpec ChangeSpec) *service.Cursor {
+	return service.NewCursor(strconv.FormatInt(changeSpec.ID, 10), nil)
+}
+
+// Wakes up everyone waiting on the channel returned by wait() when notify() is called.
+type changeNotifier struct {
+	mutex sync.Mutex
+	c     chan struct{}
+}
+
+func newChangeNotifier() *changeNotifier {
+	return &changeNotifier{
+		c: make(chan struct{}),
+	}
+}
+
+func (n *changeNotifier) wait() <-chan struct{} {
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+	return n.c
+}
+
+func (n *changeNotifier) notify() {
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+	close(n.c)
+	n.c = make(chan struct{})
+}
@@ -0,0 +1,213 @@
+// Copyright 2024 WorkOS, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package changelog
+
+import (
+	"context"
+	"errors"
+	"math"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/warrant-dev/warrant/pkg/config"
+	"github.com/warrant-dev/warrant/pkg/database"
+	"github.com/warrant-dev/warrant/pkg/service"
+)
+
+type testEnv struct {
+	db database.Database
+}
+
+func (env testEnv) DB() database.Database {
+	return env.db
+}
+
+type testChangeRepository struct {
+	changes []Change
+}
+
+func (repo testChangeRepository) Create(ctx context.Context, model Model) (int64, error) {
+	return -1, errors.New("not implemented")
+}
+
+func (repo testChangeRepository) List(ctx context.Context, filterParams FilterParams, afterId int64, upToId int64, limit int) ([]Model, error) {
+	models := make([]Model, 0)
+	for i := range repo.changes {
+		change := repo.changes[i]
+		if change.WookieId > filterParams.SinceWookieId && change.ID > afterId && change.ID <= upToId && len(models) < limit {
+			models = append(models, &change)
+		}
+	}
+	return models, nil
+}
+
+func (repo testChangeRepository) ListPositions(ctx context.Context, afterId int64, limit int) ([]Model, error) {
+	return repo.List(ctx, FilterParams{}, afterId, math.MaxInt64, limit)
+}
+
+// Connects to the migrated MySQL or Postgres database given by the same DSN
+// env vars the server reads. SQLite is skipped since it only allows a single
+// writer, so its transactions can't commit out of order.
+func connectTestDatabase(t *testing.T) database.Database {
+	t.Helper()
+	var db database.Database
+	switch {
+	case os.Getenv("WARRANT_DATASTORE_MYSQL_DSN") != "":
+		db = database.NewMySQL(config.MySQLConfig{DSN: os.Getenv("WARRANT_DATASTORE_MYSQL_DSN")})
+	case os.Getenv("WARRANT_DATASTORE_POSTGRES_DSN") != "":
+		db = database.NewPostgres(config.PostgresConfig{DSN: os.Getenv("WARRANT_DATASTORE_POSTGRES_DSN")})
+	default:
+		t.Skip("WARRANT_DATASTORE_MYSQL_DSN or WARRANT_DATASTORE_POSTGRES_DSN must be set")
+	}
+
+	err := db.Connect(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error connecting to database: %v", err)
+	}
+	return db
+}
+
+func TestListDoesNotSkipChangesCommittedOutOfOrder(t *testing.T) {
+	db := connectTestDatabase(t)
+	repository, err := NewRepository(db)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	svc := NewService(testEnv{db: db}, repository)
+	ctx := context.Background()
+
+	// Tail the changelog up to the latest change
+	listParams := service.ListParams{Limit: 100}
+	for {
+		changes, nextCursor, err := svc.List(ctx, FilterParams{}, listParams)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		listParams.NextCursor = nextCursor
+		if len(changes) < listParams.Limit {
+			break
+		}
+	}
+
+	// Record a change in a transaction that stays open until commitFirst is closed
+	firstRecorded := make(chan struct{})
+	commitFirst := make(chan struct{})
+	firstErr := make(chan error, 1)
+	go func() {
+		_, err := db.WithinConsistentTransaction(ctx, func(txCtx context.Context) error {
+			err := svc.RecordChange(txCtx, TypeObjectCreated, nil, "first")
+			close(firstRecorded)
+			if err != nil {
+				return err
+			}
+
+			<-commitFirst
+			return nil
+		})
+		firstErr <- err
+	}()
+	<-firstRecorded
+
+	// Record a second change and try to commit it before the first one
+	secondErr := make(chan error, 1)
+	go func() {
+		_, err := db.WithinConsistentTransaction(ctx, func(txCtx context.Context) error {
+			return svc.RecordChange(txCtx, TypeObjectCreated, nil, "second")
+		})
+		secondErr <- err
+	}()
+	select {
+	case err := <-secondErr:
+		secondErr <- err
+	case <-time.After(time.Second):
+	}
+
+	// A client tailing the changelog while the first change is in flight
+	// must not move its cursor past it
+	changes, nextCursor, err := svc.List(ctx, FilterParams{}, listParams)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	listParams.NextCursor = nextCursor
+
+	close(commitFirst)
+	if err := <-firstErr; err != nil {
+		t.Fatalf("unexpected error committing first change: %v", err)
+	}
+	if err := <-secondErr; err != nil {
+		t.Fatalf("unexpected error committing second change: %v", err)
+	}
+
+	moreChanges, _, err := svc.List(ctx, FilterParams{}, listParams)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	changes = append(changes, moreChanges...)
+
+	expectedData := []string{`"first"`, `"second"`}
+	if len(changes) != len(expectedData) {
+		t.Fatalf("expected %d changes, got %d", len(expectedData), len(changes))
+	}
+	for i, change := range changes {
+		if string(change.Data) != expectedData[i] {
+			t.Errorf("expected change %d to have data %s, got %s", i, expectedData[i], change.Data)
+		}
+	}
+}
+
+func TestListStopsAtChangesInFlight(t *testing.T) {
+	t.Parallel()
+	now := time.Now()
+	svc := NewService(nil, testChangeRepository{
+		changes: []Change{
+			{ID: 1, WookieId: 1, CreatedAt: now.Add(-time.Minute)},
+			{ID: 3, WookieId: 3, CreatedAt: now.Add(-time.Minute)},
+			{ID: 4, WookieId: 4, CreatedAt: now},
+			{ID: 6, WookieId: 6, CreatedAt: now},
+		},
+	})
+
+	// The gap before 3 is older than inFlightTimeout, so it's assumed to be
+	// rolled back, but the change before 6 may still commit
+	changes, nextCursor, err := svc.List(context.Background(), FilterParams{}, service.ListParams{Limit: 10})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expectedIds := []int64{1, 3, 4}
+	if len(changes) != len(expectedIds) {
+		t.Fatalf("expected %d changes, got %d", len(expectedIds), len(changes))
+	}
+	for i, change := range changes {
+		if change.ID != expectedIds[i] {
+			t.Errorf("expected change %d to have id %d, got %d", i, expectedIds[i], change.ID)
+		}
+	}
+	if nextCursor.ID() != "4" {
+		t.Errorf("expected next cursor to be 4, got %s", nextCursor.ID())
+	}
+
+	// Changes filtered out aren't mistaken for gaps
+	changes, nextCursor, err = svc.List(context.Background(), FilterParams{SinceWookieId: 3}, service.ListParams{Limit: 10})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(changes) != 1 || changes[0].ID != 4 {
+		t.Fatalf("expected only change 4, got %v", changes)
+	}
+	if nextCursor.ID() != "4" {
+		t.Errorf("expected next cursor to be 4, got %s", nextCursor.ID())
+	}
+}
@@ -0,0 +1,53 @@
+// Copyright 2024 WorkOS, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package changelog
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/warrant-dev/warrant/pkg/service"
+)
+
+const (
+	TypeWarrantCreated    = "warrant.created"
+	TypeWarrantDeleted    = "warrant.deleted"
+	TypeObjectCreated     = "object.created"
+	TypeObjectUpdated     = "object.updated"
+	TypeObjectDeleted     = "object.deleted"
+	TypeObjectTypeCreated = "objecttype.created"
+	TypeObjectTypeUpdated = "objecttype.updated"
+	TypeObjectTypeDeleted = "objecttype.deleted"
+)
+
+type FilterParams struct {
+	// Only changes made after the write with this wookie id are returned.
+	SinceWookieId int64 `json:"-"`
+}
+
+type ChangeSpec struct {
+	// NOTE: ID is required here for internal use.
+	// However, we don't return it to the client.
+	ID           int64           `json:"-"`
+	Type         string          `json:"type"`
+	Data         json.RawMessage `json:"data"`
+	WarrantToken string          `json:"warrantToken"`
+	CreatedAt    time.Time       `json:"createdAt"`
+}
+
+type ListChangesSpec struct {
+	Results    []ChangeSpec    `json:"results"`
+	NextCursor *service.Cursor `json:"nextCursor,omitempty"`
+}
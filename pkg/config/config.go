@@ -32,6 +32,7 @@ const (
 	DefaultPostgresDatastoreMigrationSource = "github://warrant-dev/warrant/migrations/datastore/postgres"
 	DefaultSQLiteDatastoreMigrationSource   = "github://warrant-dev/warrant/migrations/datastore/sqlite"
 	DefaultAuthenticationUserIdClaim        = "sub"
+	DefaultAuthenticationJwksCacheTtl       = 1 * time.Hour
 	PrefixWarrant                           = "warrant"
 	ConfigFileName                          = "warrant.yaml"
 )
@@ -52,6 +53,16 @@ type WarrantConfig struct {
 	Datastore       *WarrantDatastoreConfig `mapstructure:"datastore"`
 	Authentication  *AuthConfig             `mapstructure:"authentication"`
 	Check           *CheckConfig            `mapstructure:"check"`
+	Sweeper         *SweeperConfig          `mapstructure:"sweeper"`
+	WarrantBatch    *WarrantBatchConfig     `mapstructure:"warrantBatch"`
+	Webhooks        *WebhooksConfig         `mapstructure:"webhooks"`
+	DecisionLog     *DecisionLogConfig      `mapstructure:"decisionLog"`
+	RateLimit       *RateLimitConfig        `mapstructure:"rateLimit"`
+	CORS            *CORSConfig             `mapstructure:"cors"`
+	Metrics         *MetricsConfig          `mapstructure:"metrics"`
+	Tracing         *TracingConfig          `mapstructure:"tracing"`
+	ServerTiming    *ServerTimingConfig     `mapstructure:"serverTiming"`
+	Shutdown        *ShutdownConfig         `mapstructure:"shutdown"`
 }
 
 func (warrantConfig WarrantConfig) GetPort() int {
@@ -82,6 +93,46 @@ func (warrantConfig WarrantConfig) GetCheck() *CheckConfig {
 	return warrantConfig.Check
 }
 
+func (warrantConfig WarrantConfig) GetSweeper() *SweeperConfig {
+	return warrantConfig.Sweeper
+}
+
+func (warrantConfig WarrantConfig) GetWarrantBatch() *WarrantBatchConfig {
+	return warrantConfig.WarrantBatch
+}
+
+func (warrantConfig WarrantConfig) GetWebhooks() *WebhooksConfig {
+	return warrantConfig.Webhooks
+}
+
+func (warrantConfig WarrantConfig) GetDecisionLog() *DecisionLogConfig {
+	return warrantConfig.DecisionLog
+}
+
+func (warrantConfig WarrantConfig) GetRateLimit() *RateLimitConfig {
+	return warrantConfig.RateLimit
+}
+
+func (warrantConfig WarrantConfig) GetCORS() *CORSConfig {
+	return warrantConfig.CORS
+}
+
+func (warrantConfig WarrantConfig) GetMetrics() *MetricsConfig {
+	return warrantConfig.Metrics
+}
+
+func (warrantConfig WarrantConfig) GetTracing() *TracingConfig {
+	return warrantConfig.Tracing
+}
+
+func (warrantConfig WarrantConfig) GetServerTiming() *ServerTimingConfig {
+	return warrantConfig.ServerTiming
+}
+
+func (warrantConfig WarrantConfig) GetShutdown() *ShutdownConfig {
+	return warrantConfig.Shutdown
+}
+
 type DatastoreConfig interface {
 	GetMySQL() *MySQLConfig
 	GetPostgres() *PostgresConfig
@@ -153,20 +204,170 @@ type SQLiteConfig struct {
 
 type AuthConfig struct {
 	ApiKey   string              `mapstructure:"apiKey"`
+	ApiKeys  []ApiKeyConfig      `mapstructure:"apiKeys"`
 	Provider *AuthProviderConfig `mapstructure:"providers"`
+	// Additional providers, only configurable via warrant.yaml
+	AdditionalProviders []AuthProviderConfig `mapstructure:"additionalProviders"`
+	Sessions            *SessionsConfig      `mapstructure:"sessions"`
+}
+
+// GetProviders returns the configured provider followed by any additional providers.
+func (authConfig AuthConfig) GetProviders() []AuthProviderConfig {
+	providers := make([]AuthProviderConfig, 0, len(authConfig.AdditionalProviders)+1)
+	if authConfig.Provider != nil && authConfig.Provider.isConfigured() {
+		providers = append(providers, *authConfig.Provider)
+	}
+
+	return append(providers, authConfig.AdditionalProviders...)
+}
+
+// ApiKeyConfig is a named API key, identified by the hex-encoded SHA-256 hash
+// of the key, that can only make requests allowed by its scopes.
+type ApiKeyConfig struct {
+	Name    string   `mapstructure:"name"`
+	KeyHash string   `mapstructure:"keyHash"`
+	Scopes  []string `mapstructure:"scopes"`
 }
 
 type AuthProviderConfig struct {
-	Name          string `mapstructure:"name"`
-	PublicKey     string `mapstructure:"publicKey"`
-	UserIdClaim   string `mapstructure:"userIdClaim"`
-	TenantIdClaim string `mapstructure:"tenantIdClaim"`
+	Name      string `mapstructure:"name"`
+	PublicKey string `mapstructure:"publicKey"`
+	// Tokens are verified against the keys served at JwksUrl instead of
+	// PublicKey if set. The keys are refreshed every JwksCacheTtl.
+	JwksUrl       string        `mapstructure:"jwksUrl"`
+	JwksCacheTtl  time.Duration `mapstructure:"jwksCacheTtl"`
+	Issuer        string        `mapstructure:"issuer"`
+	Audience      string        `mapstructure:"audience"`
+	UserIdClaim   string        `mapstructure:"userIdClaim"`
+	TenantIdClaim string        `mapstructure:"tenantIdClaim"`
+}
+
+// Defaults such as userIdClaim are always set, so a provider is only
+// considered configured if it has a name or a key to verify tokens with.
+func (provider AuthProviderConfig) isConfigured() bool {
+	return provider.Name != "" || provider.PublicKey != "" || provider.JwksUrl != ""
+}
+
+// SessionsConfig configures the session tokens Warrant issues via POST
+// /v2/sessions. Sessions are disabled unless SigningKey is set.
+type SessionsConfig struct {
+	SigningKey string        `mapstructure:"signingKey"`
+	DefaultTtl time.Duration `mapstructure:"defaultTtl"`
+	MaxTtl     time.Duration `mapstructure:"maxTtl"`
 }
 
 type CheckConfig struct {
-	Concurrency    int           `mapstructure:"concurrency"`
-	MaxConcurrency int           `mapstructure:"maxConcurrency"`
+	Concurrency    int               `mapstructure:"concurrency"`
+	MaxConcurrency int               `mapstructure:"maxConcurrency"`
+	Timeout        time.Duration     `mapstructure:"timeout"`
+	MaxDepth       int               `mapstructure:"maxDepth"`
+	Cache          *CheckCacheConfig `mapstructure:"cache"`
+}
+
+type CheckCacheConfig struct {
+	Enabled bool          `mapstructure:"enabled"`
+	Size    int           `mapstructure:"size"`
+	TTL     time.Duration `mapstructure:"ttl"`
+}
+
+type SweeperConfig struct {
+	Enabled   bool          `mapstructure:"enabled"`
+	Interval  time.Duration `mapstructure:"interval"`
+	BatchSize int           `mapstructure:"batchSize"`
+}
+
+type WarrantBatchConfig struct {
+	MaxOperations int `mapstructure:"maxOperations"`
+}
+
+type WebhooksConfig struct {
+	Enabled        bool          `mapstructure:"enabled"`
+	PollInterval   time.Duration `mapstructure:"pollInterval"`
+	BatchSize      int           `mapstructure:"batchSize"`
 	Timeout        time.Duration `mapstructure:"timeout"`
+	MaxAttempts    int           `mapstructure:"maxAttempts"`
+	InitialBackoff time.Duration `mapstructure:"initialBackoff"`
+	MaxBackoff     time.Duration `mapstructure:"maxBackoff"`
+}
+
+type DecisionLogConfig struct {
+	Enabled    bool    `mapstructure:"enabled"`
+	Sink       string  `mapstructure:"sink"`
+	SampleRate float64 `mapstructure:"sampleRate"`
+	// Sample rates by route pattern (e.g. /v2/check), overriding SampleRate
+	Routes     map[string]float64     `mapstructure:"routes"`
+	BufferSize int                    `mapstructure:"bufferSize"`
+	File       *DecisionLogFileConfig `mapstructure:"file"`
+}
+
+type DecisionLogFileConfig struct {
+	Path       string `mapstructure:"path"`
+	MaxSize    int64  `mapstructure:"maxSize"`
+	MaxBackups int    `mapstructure:"maxBackups"`
+}
+
+type RateLimitConfig struct {
+	Enabled           bool    `mapstructure:"enabled"`
+	RequestsPerSecond float64 `mapstructure:"requestsPerSecond"`
+	Burst             int     `mapstructure:"burst"`
+	// Limits by route pattern (e.g. /v2/query), overriding RequestsPerSecond and Burst
+	Routes map[string]RateLimitRouteConfig `mapstructure:"routes"`
+	// Limits requests that fail authentication by client IP
+	FailedAuthRequestsPerSecond float64 `mapstructure:"failedAuthRequestsPerSecond"`
+	FailedAuthBurst             int     `mapstructure:"failedAuthBurst"`
+	// IPs or CIDR ranges of proxies whose X-Forwarded-For header is used to
+	// find the client IP
+	TrustedProxies []string `mapstructure:"trustedProxies"`
+}
+
+type RateLimitRouteConfig struct {
+	RequestsPerSecond float64 `mapstructure:"requestsPerSecond"`
+	Burst             int     `mapstructure:"burst"`
+}
+
+type CORSConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	// Origins like https://app.example.com, https://*.example.com, or *
+	AllowedOrigins []string      `mapstructure:"allowedOrigins"`
+	AllowedMethods []string      `mapstructure:"allowedMethods"`
+	AllowedHeaders []string      `mapstructure:"allowedHeaders"`
+	MaxAge         time.Duration `mapstructure:"maxAge"`
+}
+
+// MetricsConfig configures the admin server that serves Prometheus metrics
+// at /metrics, separately from the API.
+type MetricsConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	Port    int  `mapstructure:"port"`
+}
+
+// TracingConfig configures exporting OpenTelemetry traces to a collector
+// over OTLP/HTTP.
+type TracingConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	// OTLP/HTTP traces endpoint, e.g. http://localhost:4318/v1/traces
+	Endpoint    string            `mapstructure:"endpoint"`
+	Headers     map[string]string `mapstructure:"headers"`
+	ServiceName string            `mapstructure:"serviceName"`
+	// Fraction of new traces to sample. Requests with a sampled traceparent are always traced.
+	SampleRate float64 `mapstructure:"sampleRate"`
+}
+
+// ServerTimingConfig configures adding a Server-Timing header summarizing
+// where time was spent to responses.
+type ServerTimingConfig struct {
+	// Add the header to all responses
+	Enabled bool `mapstructure:"enabled"`
+	// Add the header to responses to requests with a Warrant-Server-Timing: true header
+	AllowRequestHeader bool `mapstructure:"allowRequestHeader"`
+}
+
+// ShutdownConfig configures how the server shuts down on SIGINT or SIGTERM.
+type ShutdownConfig struct {
+	// How long to keep serving requests while failing readiness checks before shutting down
+	Delay time.Duration `mapstructure:"delay"`
+	// How long to wait for in-flight requests to complete once shutting down
+	Timeout time.Duration `mapstructure:"timeout"`
 }
 
 func NewConfig() WarrantConfig {
@@ -185,9 +386,52 @@ func NewConfig() WarrantConfig {
 	viper.SetDefault("datastore.sqlite.connMaxLifetime", 6*time.Hour)
 	viper.SetDefault("datastore.sqlite.migrationSource", DefaultSQLiteDatastoreMigrationSource)
 	viper.SetDefault("authentication.providers.userIdClaim", DefaultAuthenticationUserIdClaim)
+	viper.SetDefault("authentication.sessions.defaultTtl", 1*time.Hour)
+	viper.SetDefault("authentication.sessions.maxTtl", 24*time.Hour)
 	viper.SetDefault("check.concurrency", 4)
 	viper.SetDefault("check.maxConcurrency", 1000)
 	viper.SetDefault("check.timeout", 1*time.Minute)
+	viper.SetDefault("check.maxDepth", 50)
+	viper.SetDefault("check.cache.enabled", false)
+	viper.SetDefault("check.cache.size", 10000)
+	viper.SetDefault("check.cache.ttl", 1*time.Minute)
+	viper.SetDefault("sweeper.enabled", true)
+	viper.SetDefault("sweeper.interval", 1*time.Minute)
+	viper.SetDefault("sweeper.batchSize", 1000)
+	viper.SetDefault("warrantBatch.maxOperations", 1000)
+	viper.SetDefault("webhooks.enabled", false)
+	viper.SetDefault("webhooks.pollInterval", 1*time.Second)
+	viper.SetDefault("webhooks.batchSize", 100)
+	viper.SetDefault("webhooks.timeout", 10*time.Second)
+	viper.SetDefault("webhooks.maxAttempts", 10)
+	viper.SetDefault("webhooks.initialBackoff", 1*time.Second)
+	viper.SetDefault("webhooks.maxBackoff", 1*time.Hour)
+	viper.SetDefault("decisionLog.enabled", false)
+	viper.SetDefault("decisionLog.sink", "log")
+	viper.SetDefault("decisionLog.sampleRate", 1.0)
+	viper.SetDefault("decisionLog.bufferSize", 10000)
+	viper.SetDefault("decisionLog.file.path", "decisions.log")
+	viper.SetDefault("decisionLog.file.maxSize", 100*1024*1024)
+	viper.SetDefault("decisionLog.file.maxBackups", 5)
+	viper.SetDefault("rateLimit.enabled", false)
+	viper.SetDefault("rateLimit.requestsPerSecond", 100)
+	viper.SetDefault("rateLimit.burst", 200)
+	viper.SetDefault("rateLimit.failedAuthRequestsPerSecond", 1)
+	viper.SetDefault("rateLimit.failedAuthBurst", 10)
+	viper.SetDefault("cors.enabled", false)
+	viper.SetDefault("cors.allowedMethods", []string{"GET", "POST", "PUT", "DELETE"})
+	viper.SetDefault("cors.allowedHeaders", []string{"Authorization", "Content-Type", "Warrant-Token", "Warrant-Server-Timing"})
+	viper.SetDefault("cors.maxAge", 10*time.Minute)
+	viper.SetDefault("metrics.enabled", false)
+	viper.SetDefault("metrics.port", 9090)
+	viper.SetDefault("tracing.enabled", false)
+	viper.SetDefault("tracing.endpoint", "http://localhost:4318/v1/traces")
+	viper.SetDefault("tracing.serviceName", "warrant")
+	viper.SetDefault("tracing.sampleRate", 1.0)
+	viper.SetDefault("serverTiming.enabled", false)
+	viper.SetDefault("serverTiming.allowRequestHeader", true)
+	viper.SetDefault("shutdown.delay", 5*time.Second)
+	viper.SetDefault("shutdown.timeout", 30*time.Second)
 
 	// If config file exists, use it
 	_, err := os.ReadFile(ConfigFileName)
@@ -226,7 +470,7 @@ func NewConfig() WarrantConfig {
 		log.Logger = log.Output(zerolog.ConsoleWriter{Out: os.Stderr})
 	}
 
-	if config.GetAuthentication() == nil || config.GetAuthentication().ApiKey == "" {
+	if config.GetAuthentication() == nil || (config.GetAuthentication().ApiKey == "" && len(config.GetAuthentication().ApiKeys) == 0) {
 		log.Fatal().Msg("init: must provide an API key to authenticate incoming requests to Warrant.")
 	}
 
@@ -14,7 +14,11 @@
 
 package database
 
-import "context"
+import (
+	"context"
+
+	"github.com/warrant-dev/warrant/pkg/wookie"
+)
 
 const (
 	TypeMySQL    = "mysql"
@@ -27,5 +31,7 @@ type Database interface {
 	Connect(ctx context.Context) error
 	Migrate(ctx context.Context, toVersion uint) error
 	Ping(ctx context.Context) error
+	MigrationVersion(ctx context.Context) (uint, bool, error)
 	WithinTransaction(ctx context.Context, txCallback func(ctx context.Context) error) error
+	WithinConsistentTransaction(ctx context.Context, txCallback func(ctx context.Context) error) (*wookie.Token, error)
 }
@@ -28,6 +28,7 @@ import (
 	_ "github.com/golang-migrate/migrate/v4/source/file"
 	_ "github.com/golang-migrate/migrate/v4/source/github"
 	"github.com/warrant-dev/warrant/pkg/config"
+	"github.com/warrant-dev/warrant/pkg/wookie"
 )
 
 type MySQL struct {
@@ -172,3 +173,20 @@ func (ds *MySQL) Ping(ctx context.Context) error {
 	}
 	return nil
 }
+
+func (ds *MySQL) WithinConsistentTransaction(ctx context.Context, txFunc func(txCtx context.Context) error) (*wookie.Token, error) {
+	return ds.withinConsistentTransaction(ctx, txFunc, func(txCtx context.Context) (int64, error) {
+		result, err := ds.ExecContext(
+			txCtx,
+			`
+				INSERT INTO wookie (ver) VALUES (?)
+			`,
+			wookie.TokenVersion,
+		)
+		if err != nil {
+			return -1, err
+		}
+
+		return result.LastInsertId()
+	})
+}
@@ -29,6 +29,7 @@ import (
 	_ "github.com/golang-migrate/migrate/v4/source/file"
 	_ "github.com/golang-migrate/migrate/v4/source/github"
 	"github.com/warrant-dev/warrant/pkg/config"
+	"github.com/warrant-dev/warrant/pkg/wookie"
 )
 
 type Postgres struct {
@@ -176,3 +177,23 @@ func (ds *Postgres) Ping(ctx context.Context) error {
 	}
 	return nil
 }
+
+func (ds *Postgres) WithinConsistentTransaction(ctx context.Context, txFunc func(txCtx context.Context) error) (*wookie.Token, error) {
+	return ds.withinConsistentTransaction(ctx, txFunc, func(txCtx context.Context) (int64, error) {
+		var newWookieId int64
+		err := ds.GetContext(
+			txCtx,
+			&newWookieId,
+			`
+				INSERT INTO wookie (ver) VALUES (?)
+				RETURNING id
+			`,
+			wookie.TokenVersion,
+		)
+		if err != nil {
+			return -1, err
+		}
+
+		return newWookieId, nil
+	})
+}
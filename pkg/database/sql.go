@@ -24,7 +24,10 @@ import (
 	"github.com/pkg/errors"
 	"github.com/rs/zerolog/log"
 	"github.com/warrant-dev/warrant/pkg/stats"
+	"github.com/warrant-dev/warrant/pkg/tracing"
 	"github.com/warrant-dev/warrant/pkg/wookie"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
 type SqlQueryable interface {
@@ -149,39 +152,44 @@ func NewSQL(writer *sqlx.DB, reader *sqlx.DB, writerHostname string, readerHostn
 }
 
 // Execute txFunc() within the context of a single write transaction
-func (ds SQL) WithinTransaction(ctx context.Context, txFunc func(txCtx context.Context) error) error {
+func (ds SQL) WithinTransaction(ctx context.Context, txFunc func(txCtx context.Context) error) (err error) {
 	// If transaction already started for this database, re-use it and
 	// let the top-level WithinTransaction call manage rollback/commit
 	if _, ok := ctx.Value(newTxKey(ds.DatabaseName)).(*SqlTx); ok {
 		return txFunc(ctx)
 	}
 
+	ctx, span := tracing.StartSpan(ctx, "sql.Transaction", attribute.String("db.name", ds.DatabaseName))
+	defer span.End()
+
 	tx, err := ds.Writer.BeginTxx(ctx, nil)
 	if err != nil {
+		tracing.SetError(span, err)
 		return errors.Wrap(err, "Error beginning sql transaction")
 	}
 
 	defer func() {
 		if p := recover(); p != nil {
-			err = tx.Rollback()
-			if err != nil {
-				err = errors.Wrap(err, "error rolling back sql transaction")
+			rollbackErr := tx.Rollback()
+			if rollbackErr != nil {
+				log.Ctx(ctx).Error().Err(rollbackErr).Msg("error rolling back sql transaction")
 			}
 
 			panic(p)
 		} else if errors.Is(err, context.Canceled) {
 			err = errors.Wrap(err, "sql transaction rolled back")
 		} else if err != nil {
-			err = tx.Rollback()
-			if err != nil {
-				err = errors.Wrap(err, "error rolling back sql transaction")
+			rollbackErr := tx.Rollback()
+			if rollbackErr != nil {
+				err = errors.Wrap(rollbackErr, "error rolling back sql transaction")
 			}
 		} else {
-			err = tx.Commit()
-			if err != nil {
-				err = errors.Wrap(err, "error committing sql transaction")
+			commitErr := tx.Commit()
+			if commitErr != nil {
+				err = errors.Wrap(commitErr, "error committing sql transaction")
 			}
 		}
+		tracing.SetError(span, err)
 	}()
 
 	// Add the newly created transaction for this database to txCtx
@@ -194,10 +202,67 @@ func (ds SQL) WithinTransaction(ctx context.Context, txFunc func(txCtx context.C
 	return err
 }
 
+type wookieIdKey struct{}
+
+// Returns the id of the wookie created by the consistent transaction ctx is
+// within, if any.
+func WookieIdFromContext(ctx context.Context) (int64, bool) {
+	wookieId, ok := ctx.Value(wookieIdKey{}).(int64)
+	return wookieId, ok
+}
+
+// Execute txFunc() within the context of a single write transaction and
+// record a new wookie in that same transaction using insertWookie(). The
+// returned token can be presented by subsequent reads to guarantee they
+// observe the writes made by txFunc().
+func (ds SQL) withinConsistentTransaction(ctx context.Context, txFunc func(txCtx context.Context) error, insertWookie func(txCtx context.Context) (int64, error)) (*wookie.Token, error) {
+	// Nested consistent transactions share the wookie of the outermost one
+	if wookieId, ok := WookieIdFromContext(ctx); ok {
+		err := ds.WithinTransaction(ctx, txFunc)
+		if err != nil {
+			return nil, err
+		}
+
+		return &wookie.Token{
+			ID:        wookieId,
+			Version:   wookie.TokenVersion,
+			Timestamp: time.Now().UTC(),
+		}, nil
+	}
+
+	var token *wookie.Token
+	err := ds.WithinTransaction(ctx, func(txCtx context.Context) error {
+		// Create the wookie first so that writes made by txFunc can reference it
+		wookieId, err := insertWookie(txCtx)
+		if err != nil {
+			return errors.Wrap(err, "error creating wookie")
+		}
+
+		err = txFunc(context.WithValue(txCtx, wookieIdKey{}, wookieId))
+		if err != nil {
+			return err
+		}
+
+		token = &wookie.Token{
+			ID:        wookieId,
+			Version:   wookie.TokenVersion,
+			Timestamp: time.Now().UTC(),
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return token, nil
+}
+
 func (ds SQL) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
 	curr := time.Now()
 	query = ds.Writer.Rebind(query)
 	queryable := ds.getQueryableFromContext(ctx, true)
+	ctx, span := ds.startQuerySpan(ctx, queryable, "ExecContext", query)
+	defer span.End()
 
 	defer ds.recordQueryStat(ctx, queryable, "ExecContext", curr)
 
@@ -207,6 +272,7 @@ func (ds SQL) ExecContext(ctx context.Context, query string, args ...interface{}
 		case errors.Is(err, sql.ErrNoRows):
 			return result, err
 		default:
+			tracing.SetError(span, err)
 			return result, errors.Wrap(err, "Error when calling sql ExecContext")
 		}
 	}
@@ -217,6 +283,8 @@ func (ds SQL) GetContext(ctx context.Context, dest interface{}, query string, ar
 	curr := time.Now()
 	query = ds.Writer.Rebind(query)
 	queryable := ds.getQueryableFromContext(ctx, false)
+	ctx, span := ds.startQuerySpan(ctx, queryable, "GetContext", query)
+	defer span.End()
 
 	defer ds.recordQueryStat(ctx, queryable, "GetContext", curr)
 
@@ -226,6 +294,7 @@ func (ds SQL) GetContext(ctx context.Context, dest interface{}, query string, ar
 		case errors.Is(err, sql.ErrNoRows):
 			return err
 		default:
+			tracing.SetError(span, err)
 			return errors.Wrap(err, "Error when calling sql GetContext")
 		}
 	}
@@ -236,6 +305,8 @@ func (ds SQL) NamedExecContext(ctx context.Context, query string, arg interface{
 	curr := time.Now()
 	query = ds.Writer.Rebind(query)
 	queryable := ds.getQueryableFromContext(ctx, true)
+	ctx, span := ds.startQuerySpan(ctx, queryable, "NamedExecContext", query)
+	defer span.End()
 
 	defer ds.recordQueryStat(ctx, queryable, "NamedExecContext", curr)
 
@@ -245,6 +316,7 @@ func (ds SQL) NamedExecContext(ctx context.Context, query string, arg interface{
 		case errors.Is(err, sql.ErrNoRows):
 			return result, err
 		default:
+			tracing.SetError(span, err)
 			return result, errors.Wrap(err, "Error when calling sql NamedExecContext")
 		}
 	}
@@ -263,6 +335,8 @@ func (ds SQL) QueryRowContext(ctx context.Context, query string, args ...interfa
 	curr := time.Now()
 	query = ds.Writer.Rebind(query)
 	queryable := ds.getQueryableFromContext(ctx, false)
+	ctx, span := ds.startQuerySpan(ctx, queryable, "QueryRowContext", query)
+	defer span.End()
 
 	defer ds.recordQueryStat(ctx, queryable, "QueryRowContext", curr)
 
@@ -273,6 +347,8 @@ func (ds SQL) SelectContext(ctx context.Context, dest interface{}, query string,
 	curr := time.Now()
 	query = ds.Writer.Rebind(query)
 	queryable := ds.getQueryableFromContext(ctx, false)
+	ctx, span := ds.startQuerySpan(ctx, queryable, "SelectContext", query)
+	defer span.End()
 
 	defer ds.recordQueryStat(ctx, queryable, "SelectContext", curr)
 
@@ -282,12 +358,29 @@ func (ds SQL) SelectContext(ctx context.Context, dest interface{}, query string,
 		case errors.Is(err, sql.ErrNoRows):
 			return err
 		default:
+			tracing.SetError(span, err)
 			return errors.Wrap(err, "Error when calling sql SelectContext")
 		}
 	}
 	return err
 }
 
+// Returns the version of the last migration applied to the writer and
+// whether it failed part way through (leaving the database dirty).
+func (ds SQL) MigrationVersion(ctx context.Context) (uint, bool, error) {
+	var version uint
+	var dirty bool
+	err := ds.Writer.QueryRowContext(ctx, "SELECT version, dirty FROM schema_migrations LIMIT 1").Scan(&version, &dirty)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, false, nil
+		}
+		return 0, false, errors.Wrap(err, "Error getting migration version")
+	}
+
+	return version, dirty, nil
+}
+
 // Get main db pool (writer), open tx, or the reader pool (if configured).
 func (ds SQL) getQueryableFromContext(ctx context.Context, isWriteOp bool) SqlQueryable {
 	// If a writer tx is already open, use it
@@ -300,6 +393,7 @@ func (ds SQL) getQueryableFromContext(ctx context.Context, isWriteOp bool) SqlQu
 	// 2. There is no reader
 	// 3. ctx contains 'latest' wookie
 	// 4. ctx contains 'writer' db override
+	// 5. ctx contains a wookie the reader has not caught up to yet
 	if isWriteOp || ds.Reader == nil || wookie.ContainsLatest(ctx) {
 		return ds.Writer
 	}
@@ -308,11 +402,52 @@ func (ds SQL) getQueryableFromContext(ctx context.Context, isWriteOp bool) SqlQu
 			return ds.Writer
 		}
 	}
+	if token, ok := wookie.GetTokenFromContext(ctx); ok && !ds.readerCaughtUpTo(ctx, token.ID) {
+		return ds.Writer
+	}
 
 	// Otherwise, use reader
 	return ds.Reader
 }
 
+// Returns true if the wookie with the given id has been replicated to the reader, false otherwise.
+// Wookie ids are allocated when inserted but can be committed out of order, so
+// the reader is only caught up once it has the wookie itself, not just a later one.
+func (ds SQL) readerCaughtUpTo(ctx context.Context, wookieId int64) bool {
+	start := time.Now()
+	var exists int
+	err := ds.Reader.GetContext(ctx, &exists, ds.Reader.Rebind("SELECT 1 FROM wookie WHERE id = ?"), wookieId)
+	ds.recordQueryStat(ctx, ds.Reader, "GetContext", start)
+	if err != nil {
+		if !errors.Is(err, sql.ErrNoRows) {
+			log.Ctx(ctx).Warn().Err(err).Msg("database: unable to get wookie from reader, falling back to writer")
+		}
+		return false
+	}
+
+	return true
+}
+
+// Start a span for a query run on queryable.
+func (ds SQL) startQuerySpan(ctx context.Context, queryable SqlQueryable, op string, query string) (context.Context, trace.Span) {
+	hostname := ds.ReaderHostname
+	switch q := queryable.(type) {
+	case *SqlTx:
+		hostname = q.Hostname
+	case *sqlx.DB:
+		if q == ds.Writer {
+			hostname = ds.WriterHostname
+		}
+	}
+
+	return tracing.StartSpan(ctx, fmt.Sprintf("sql.%s", op),
+		attribute.String("db.name", ds.DatabaseName),
+		attribute.String("db.operation", op),
+		attribute.String("db.statement", query),
+		attribute.String("server.address", hostname),
+	)
+}
+
 func (ds SQL) recordQueryStat(ctx context.Context, queryable SqlQueryable, query string, start time.Time) {
 	sqlType := "sql.reader"
 	hostname := ds.ReaderHostname
@@ -328,7 +463,7 @@ func (ds SQL) recordQueryStat(ctx context.Context, queryable SqlQueryable, query
 		db = ds.DatabaseName
 	}
 
-	stats.RecordStat(ctx, fmt.Sprintf("%s/%s", hostname, db), fmt.Sprintf("%s.%s", sqlType, query), start)
+	stats.RecordQueryStat(ctx, fmt.Sprintf("%s/%s", hostname, db), fmt.Sprintf("%s.%s", sqlType, query), start)
 }
 
 type SQLRepository struct {
@@ -22,6 +22,7 @@ import (
 
 	"github.com/pkg/errors"
 	"github.com/warrant-dev/warrant/pkg/config"
+	"github.com/warrant-dev/warrant/pkg/wookie"
 )
 
 type SQLite struct {
@@ -48,3 +49,7 @@ func (ds SQLite) Migrate(ctx context.Context, toVersion uint) error {
 func (ds SQLite) Ping(ctx context.Context) error {
 	return errors.New("sqlite not supported")
 }
+
+func (ds SQLite) WithinConsistentTransaction(ctx context.Context, txFunc func(txCtx context.Context) error) (*wookie.Token, error) {
+	return nil, errors.New("sqlite not supported")
+}
@@ -32,6 +32,7 @@ import (
 	"github.com/pkg/errors"
 	"github.com/rs/zerolog/log"
 	"github.com/warrant-dev/warrant/pkg/config"
+	"github.com/warrant-dev/warrant/pkg/wookie"
 )
 
 type SQLite struct {
@@ -139,3 +140,20 @@ func (ds *SQLite) Migrate(ctx context.Context, toVersion uint) error {
 func (ds *SQLite) Ping(ctx context.Context) error {
 	return ds.Writer.PingContext(ctx)
 }
+
+func (ds *SQLite) WithinConsistentTransaction(ctx context.Context, txFunc func(txCtx context.Context) error) (*wookie.Token, error) {
+	return ds.withinConsistentTransaction(ctx, txFunc, func(txCtx context.Context) (int64, error) {
+		result, err := ds.ExecContext(
+			txCtx,
+			`
+				INSERT INTO wookie (ver) VALUES (?)
+			`,
+			wookie.TokenVersion,
+		)
+		if err != nil {
+			return -1, err
+		}
+
+		return result.LastInsertId()
+	})
+}
@@ -0,0 +1,129 @@
+// Copyright 2024 WorkOS, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package decisionlog
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// FileSink writes entries to a file as JSON lines. Once the file reaches
+// maxSize bytes, it's renamed to <path>.1 (shifting older files to <path>.2 and
+// so on, keeping at most maxBackups of them) and a new file is started.
+type FileSink struct {
+	mutex      sync.Mutex
+	path       string
+	maxSize    int64
+	maxBackups int
+	file       *os.File
+	size       int64
+}
+
+func NewFileSink(path string, maxSize int64, maxBackups int) (*FileSink, error) {
+	sink := &FileSink{
+		path:       path,
+		maxSize:    maxSize,
+		maxBackups: maxBackups,
+	}
+
+	err := sink.open()
+	if err != nil {
+		return nil, err
+	}
+
+	return sink, nil
+}
+
+func (sink *FileSink) Write(ctx context.Context, entry Entry) error {
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return errors.Wrap(err, "error marshaling decision log")
+	}
+	line = append(line, '\n')
+
+	sink.mutex.Lock()
+	defer sink.mutex.Unlock()
+	if sink.maxSize > 0 && sink.size > 0 && sink.size+int64(len(line)) > sink.maxSize {
+		err = sink.rotate()
+		if err != nil {
+			return err
+		}
+	}
+
+	n, err := sink.file.Write(line)
+	sink.size += int64(n)
+	if err != nil {
+		return errors.Wrap(err, "error writing decision log")
+	}
+
+	return nil
+}
+
+func (sink *FileSink) Close() error {
+	sink.mutex.Lock()
+	defer sink.mutex.Unlock()
+	return sink.file.Close()
+}
+
+func (sink *FileSink) open() error {
+	//nolint:gosec
+	file, err := os.OpenFile(sink.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return errors.Wrapf(err, "error opening decision log file %s", sink.path)
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return errors.Wrapf(err, "error opening decision log file %s", sink.path)
+	}
+
+	sink.file = file
+	sink.size = info.Size()
+	return nil
+}
+
+func (sink *FileSink) rotate() error {
+	err := sink.file.Close()
+	if err != nil {
+		return errors.Wrapf(err, "error rotating decision log file %s", sink.path)
+	}
+
+	if sink.maxBackups > 0 {
+		for i := sink.maxBackups - 1; i > 0; i-- {
+			err = os.Rename(sink.backupPath(i), sink.backupPath(i+1))
+			if err != nil && !os.IsNotExist(err) {
+				return errors.Wrapf(err, "error rotating decision log file %s", sink.path)
+			}
+		}
+		err = os.Rename(sink.path, sink.backupPath(1))
+	} else {
+		err = os.Remove(sink.path)
+	}
+	if err != nil && !os.IsNotExist(err) {
+		return errors.Wrapf(err, "error rotating decision log file %s", sink.path)
+	}
+
+	return sink.open()
+}
+
+func (sink *FileSink) backupPath(i int) string {
+	return fmt.Sprintf("%s.%d", sink.path, i)
+}
@@ -0,0 +1,102 @@
+// Copyright 2024 WorkOS, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package decisionlog
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFileSinkRotatesFiles(t *testing.T) {
+	t.Parallel()
+	path := filepath.Join(t.TempDir(), "decisions.log")
+	sink, err := NewFileSink(path, 100, 2)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer sink.Close()
+
+	// Each entry is bigger than maxSize, so each write after the first rotates the file
+	for _, subject := range []string{"user:a", "user:b", "user:c", "user:d"} {
+		err = sink.Write(context.Background(), Entry{Type: TypeCheck, Subject: subject, Result: "Authorized"})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	expectedSubjects := map[string]string{
+		path:        "user:d",
+		path + ".1": "user:c",
+		path + ".2": "user:b",
+	}
+	for filePath, expectedSubject := range expectedSubjects {
+		entries := readEntries(t, filePath)
+		if len(entries) != 1 || entries[0].Subject != expectedSubject {
+			t.Fatalf("expected %s to contain only %s, got %v", filePath, expectedSubject, entries)
+		}
+	}
+
+	if _, err := os.Stat(path + ".3"); !os.IsNotExist(err) {
+		t.Fatalf("expected at most 2 backups to be kept")
+	}
+}
+
+func TestFileSinkAppendsToExistingFile(t *testing.T) {
+	t.Parallel()
+	path := filepath.Join(t.TempDir(), "decisions.log")
+	for _, subject := range []string{"user:a", "user:b"} {
+		sink, err := NewFileSink(path, 0, 0)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		err = sink.Write(context.Background(), Entry{Type: TypeQuery, Subject: subject})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		sink.Close()
+	}
+
+	entries := readEntries(t, path)
+	if len(entries) != 2 || entries[0].Subject != "user:a" || entries[1].Subject != "user:b" {
+		t.Fatalf("expected entries for user:a and user:b, got %v", entries)
+	}
+}
+
+func readEntries(t *testing.T, path string) []Entry {
+	t.Helper()
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer file.Close()
+
+	entries := make([]Entry, 0)
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var entry Entry
+		err = json.Unmarshal(scanner.Bytes(), &entry)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries
+}
@@ -0,0 +1,96 @@
+// Copyright 2024 WorkOS, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package decisionlog
+
+import (
+	"context"
+	"math/rand"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/warrant-dev/warrant/pkg/config"
+	"github.com/warrant-dev/warrant/pkg/service"
+	"github.com/warrant-dev/warrant/pkg/wookie"
+)
+
+// DecisionLogger samples decisions and writes them to a Sink in the
+// background so that logging doesn't add latency to checks and queries.
+type DecisionLogger struct {
+	config  *config.DecisionLogConfig
+	sink    Sink
+	entries chan Entry
+}
+
+func NewDecisionLogger(cfg *config.DecisionLogConfig, sink Sink) *DecisionLogger {
+	logger := &DecisionLogger{
+		config:  cfg,
+		sink:    sink,
+		entries: make(chan Entry, cfg.BufferSize),
+	}
+
+	go logger.run()
+	return logger
+}
+
+// Log queues entry to be written if it's sampled, filling in the request id,
+// route, and warrant token of the request in ctx. Entries logged while the
+// buffer is full are dropped.
+func (logger *DecisionLogger) Log(ctx context.Context, entry Entry) {
+	requestInfo, _ := service.GetRequestInfoFromContext(ctx)
+	if !logger.sample(requestInfo.Path) {
+		return
+	}
+
+	entry.RequestId = requestInfo.RequestId
+	entry.Route = requestInfo.Path
+	if wookie.ContainsLatest(ctx) {
+		entry.WarrantToken = wookie.Latest
+	} else if token, ok := wookie.GetTokenFromContext(ctx); ok {
+		entry.WarrantToken = token.String()
+	}
+	entry.CreatedAt = time.Now().UTC()
+
+	select {
+	case logger.entries <- entry:
+	default:
+		log.Warn().Msg("decisionlog: buffer full, dropping decision")
+	}
+}
+
+func (logger *DecisionLogger) sample(route string) bool {
+	sampleRate := logger.config.SampleRate
+	if routeSampleRate, ok := logger.config.Routes[route]; ok {
+		sampleRate = routeSampleRate
+	}
+
+	switch {
+	case sampleRate >= 1:
+		return true
+	case sampleRate <= 0:
+		return false
+	default:
+		//nolint:gosec
+		return rand.Float64() < sampleRate
+	}
+}
+
+func (logger *DecisionLogger) run() {
+	for entry := range logger.entries {
+		err := logger.sink.Write(context.Background(), entry)
+		if err != nil {
+			log.Error().Err(err).Msg("decisionlog: error writing decision")
+		}
+	}
+}
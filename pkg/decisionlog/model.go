@@ -0,0 +1,133 @@
+// Copyright 2024 WorkOS, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package decisionlog
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+type Model interface {
+	GetID() int64
+	GetType() string
+	GetRequestId() string
+	GetRoute() string
+	GetSubject() string
+	GetRequest() string
+	GetResult() string
+	GetNumResults() *int64
+	GetIsImplicit() bool
+	GetLatencyMs() float64
+	GetWarrantToken() string
+	GetError() string
+	GetCreatedAt() time.Time
+}
+
+type DecisionLog struct {
+	ID           int64     `mysql:"id"           postgres:"id"            sqlite:"id"`
+	Type         string    `mysql:"type"         postgres:"type"          sqlite:"type"`
+	RequestId    string    `mysql:"requestId"    postgres:"request_id"    sqlite:"requestId"`
+	Route        string    `mysql:"route"        postgres:"route"         sqlite:"route"`
+	Subject      string    `mysql:"subject"      postgres:"subject"       sqlite:"subject"`
+	Request      string    `mysql:"request"      postgres:"request"       sqlite:"request"`
+	Result       string    `mysql:"result"       postgres:"result"        sqlite:"result"`
+	NumResults   *int64    `mysql:"numResults"   postgres:"num_results"   sqlite:"numResults"`
+	IsImplicit   bool      `mysql:"isImplicit"   postgres:"is_implicit"   sqlite:"isImplicit"`
+	LatencyMs    float64   `mysql:"latencyMs"    postgres:"latency_ms"    sqlite:"latencyMs"`
+	WarrantToken string    `mysql:"warrantToken" postgres:"warrant_token" sqlite:"warrantToken"`
+	Error        string    `mysql:"error"        postgres:"error"         sqlite:"error"`
+	CreatedAt    time.Time `mysql:"createdAt"    postgres:"created_at"    sqlite:"createdAt"`
+}
+
+func (decisionLog DecisionLog) GetID() int64 {
+	return decisionLog.ID
+}
+
+func (decisionLog DecisionLog) GetType() string {
+	return decisionLog.Type
+}
+
+func (decisionLog DecisionLog) GetRequestId() string {
+	return decisionLog.RequestId
+}
+
+func (decisionLog DecisionLog) GetRoute() string {
+	return decisionLog.Route
+}
+
+func (decisionLog DecisionLog) GetSubject() string {
+	return decisionLog.Subject
+}
+
+func (decisionLog DecisionLog) GetRequest() string {
+	return decisionLog.Request
+}
+
+func (decisionLog DecisionLog) GetResult() string {
+	return decisionLog.Result
+}
+
+func (decisionLog DecisionLog) GetNumResults() *int64 {
+	return decisionLog.NumResults
+}
+
+func (decisionLog DecisionLog) GetIsImplicit() bool {
+	return decisionLog.IsImplicit
+}
+
+func (decisionLog DecisionLog) GetLatencyMs() float64 {
+	return decisionLog.LatencyMs
+}
+
+func (decisionLog DecisionLog) GetWarrantToken() string {
+	return decisionLog.WarrantToken
+}
+
+func (decisionLog DecisionLog) GetError() string {
+	return decisionLog.Error
+}
+
+func (decisionLog DecisionLog) GetCreatedAt() time.Time {
+	return decisionLog.CreatedAt
+}
+
+func NewDecisionLogFromEntry(entry Entry) (*DecisionLog, error) {
+	request, err := json.Marshal(entry.Request)
+	if err != nil {
+		return nil, errors.Wrap(err, "error marshaling decision log request")
+	}
+
+	decisionLog := DecisionLog{
+		Type:         entry.Type,
+		RequestId:    entry.RequestId,
+		Route:        entry.Route,
+		Subject:      entry.Subject,
+		Request:      string(request),
+		Result:       entry.Result,
+		IsImplicit:   entry.IsImplicit,
+		LatencyMs:    entry.LatencyMs,
+		WarrantToken: entry.WarrantToken,
+		Error:        entry.Error,
+		CreatedAt:    entry.CreatedAt,
+	}
+	if entry.NumResults != nil {
+		numResults := int64(*entry.NumResults)
+		decisionLog.NumResults = &numResults
+	}
+
+	return &decisionLog, nil
+}
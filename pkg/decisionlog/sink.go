@@ -0,0 +1,101 @@
+// Copyright 2024 WorkOS, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package decisionlog
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+	"github.com/warrant-dev/warrant/pkg/config"
+	"github.com/warrant-dev/warrant/pkg/database"
+)
+
+// Sink writes decision log entries to a destination.
+type Sink interface {
+	Write(ctx context.Context, entry Entry) error
+}
+
+// NewSink creates the Sink configured by cfg.Sink.
+func NewSink(cfg *config.DecisionLogConfig, db database.Database) (Sink, error) {
+	switch cfg.Sink {
+	case SinkLog:
+		return LogSink{}, nil
+	case SinkFile:
+		if cfg.File == nil || cfg.File.Path == "" {
+			return nil, errors.New("decisionLog.file.path must be set to use the file sink")
+		}
+
+		return NewFileSink(cfg.File.Path, cfg.File.MaxSize, cfg.File.MaxBackups)
+	case SinkDatabase:
+		repository, err := NewRepository(db)
+		if err != nil {
+			return nil, err
+		}
+
+		return DatabaseSink{repository: repository}, nil
+	default:
+		return nil, errors.New(fmt.Sprintf("unsupported decision log sink %s specified", cfg.Sink))
+	}
+}
+
+// LogSink writes entries to the server log.
+type LogSink struct{}
+
+func (sink LogSink) Write(ctx context.Context, entry Entry) error {
+	logEvent := log.Info().
+		Str("type", entry.Type).
+		Str("requestId", entry.RequestId).
+		Str("route", entry.Route).
+		Str("subject", entry.Subject).
+		Interface("request", entry.Request).
+		Bool("isImplicit", entry.IsImplicit).
+		Float64("latencyMs", entry.LatencyMs)
+
+	if entry.WarrantToken != "" {
+		logEvent = logEvent.Str("warrantToken", entry.WarrantToken)
+	}
+
+	if entry.Result != "" {
+		logEvent = logEvent.Str("result", entry.Result)
+	}
+
+	if entry.NumResults != nil {
+		logEvent = logEvent.Int("numResults", *entry.NumResults)
+	}
+
+	if entry.Error != "" {
+		logEvent = logEvent.Str("error", entry.Error)
+	}
+
+	logEvent.Msg("decision log")
+	return nil
+}
+
+// DatabaseSink writes entries to the decisionLog table.
+type DatabaseSink struct {
+	repository DecisionLogRepository
+}
+
+func (sink DatabaseSink) Write(ctx context.Context, entry Entry) error {
+	decisionLog, err := NewDecisionLogFromEntry(entry)
+	if err != nil {
+		return err
+	}
+
+	_, err = sink.repository.Create(ctx, decisionLog)
+	return err
+}
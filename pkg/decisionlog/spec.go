@@ -0,0 +1,46 @@
+// Copyright 2024 WorkOS, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package decisionlog
+
+import "time"
+
+const (
+	TypeCheck = "check"
+	TypeQuery = "query"
+)
+
+const (
+	SinkLog      = "log"
+	SinkFile     = "file"
+	SinkDatabase = "database"
+)
+
+// Entry is a record of an authorization decision made by a check or query.
+type Entry struct {
+	Type      string `json:"type"`
+	RequestId string `json:"requestId,omitempty"`
+	Route     string `json:"route,omitempty"`
+	Subject   string `json:"subject,omitempty"`
+	// The check or query that was evaluated.
+	Request interface{} `json:"request"`
+	// The result of a check, or the number of results returned by a query.
+	Result       string    `json:"result,omitempty"`
+	NumResults   *int      `json:"numResults,omitempty"`
+	IsImplicit   bool      `json:"isImplicit"`
+	LatencyMs    float64   `json:"latencyMs"`
+	WarrantToken string    `json:"warrantToken,omitempty"`
+	Error        string    `json:"error,omitempty"`
+	CreatedAt    time.Time `json:"createdAt"`
+}
@@ -0,0 +1,74 @@
+// Copyright 2024 WorkOS, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package decisionlog
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"github.com/warrant-dev/warrant/pkg/database"
+)
+
+type SQLiteRepository struct {
+	database.SQLRepository
+}
+
+func NewSQLiteRepository(db *database.SQLite) *SQLiteRepository {
+	return &SQLiteRepository{
+		database.NewSQLRepository(&db.SQL),
+	}
+}
+
+func (repo SQLiteRepository) Create(ctx context.Context, model Model) (int64, error) {
+	var newDecisionLogId int64
+	err := repo.DB.GetContext(
+		ctx,
+		&newDecisionLogId,
+		`
+			INSERT INTO decisionLog (
+				type,
+				requestId,
+				route,
+				subject,
+				request,
+				result,
+				numResults,
+				isImplicit,
+				latencyMs,
+				warrantToken,
+				error,
+				createdAt
+			) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+			RETURNING id
+		`,
+		model.GetType(),
+		model.GetRequestId(),
+		model.GetRoute(),
+		model.GetSubject(),
+		model.GetRequest(),
+		model.GetResult(),
+		model.GetNumResults(),
+		model.GetIsImplicit(),
+		model.GetLatencyMs(),
+		model.GetWarrantToken(),
+		model.GetError(),
+		model.GetCreatedAt(),
+	)
+	if err != nil {
+		return -1, errors.Wrap(err, "error creating decision log")
+	}
+
+	return newDecisionLogId, nil
+}
@@ -0,0 +1,60 @@
+// Copyright 2024 WorkOS, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package health
+
+import (
+	"net/http"
+
+	"github.com/warrant-dev/warrant/pkg/service"
+)
+
+func (svc HealthService) Routes() ([]service.Route, error) {
+	return []service.Route{
+		// liveness
+		service.WarrantRoute{
+			Pattern:                    "/healthz",
+			Method:                     "GET",
+			Handler:                    service.NewRouteHandler(svc, healthHandler),
+			OverrideAuthMiddlewareFunc: service.PassthroughAuthMiddleware,
+		},
+
+		// readiness
+		service.WarrantRoute{
+			Pattern:                    "/readyz",
+			Method:                     "GET",
+			Handler:                    service.NewRouteHandler(svc, readyHandler),
+			OverrideAuthMiddlewareFunc: service.PassthroughAuthMiddleware,
+		},
+	}, nil
+}
+
+func healthHandler(svc HealthService, w http.ResponseWriter, r *http.Request) error {
+	service.SendJSONResponse(w, HealthSpec{
+		Status: StatusOK,
+	})
+	return nil
+}
+
+func readyHandler(svc HealthService, w http.ResponseWriter, r *http.Request) error {
+	err := svc.CheckReady(r.Context())
+	if err != nil {
+		return err
+	}
+
+	service.SendJSONResponse(w, HealthSpec{
+		Status: StatusReady,
+	})
+	return nil
+}
@@ -0,0 +1,98 @@
+// Copyright 2024 WorkOS, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package health
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/warrant-dev/warrant/pkg/service"
+)
+
+const readinessTimeout = 5 * time.Second
+
+const (
+	stateStarting int32 = iota
+	stateReady
+	stateShuttingDown
+)
+
+// HealthService reports whether the server is up and whether it is ready to
+// serve requests. The server is not ready until it has finished starting up
+// (including migrating the database) or once it has started shutting down.
+type HealthService struct {
+	service.BaseService
+	// Expected migration version by database type
+	migrationVersions map[string]uint
+	state             *atomic.Int32
+}
+
+func NewService(env service.Env, migrationVersions map[string]uint) *HealthService {
+	return &HealthService{
+		BaseService:       service.NewBaseService(env),
+		migrationVersions: migrationVersions,
+		state:             &atomic.Int32{},
+	}
+}
+
+// Mark the server as started. Until then, it is not ready.
+func (svc HealthService) SetReady() {
+	svc.state.CompareAndSwap(stateStarting, stateReady)
+}
+
+// Mark the server as shutting down. It is no longer ready from then on.
+func (svc HealthService) SetShuttingDown() {
+	svc.state.Store(stateShuttingDown)
+}
+
+// Returns an error if the server isn't ready to serve requests, either because
+// it is starting up or shutting down or because the database is unreachable or
+// not migrated to the expected version.
+func (svc HealthService) CheckReady(ctx context.Context) error {
+	switch svc.state.Load() {
+	case stateStarting:
+		return service.NewServiceUnavailableError("Warrant is starting up.")
+	case stateShuttingDown:
+		return service.NewServiceUnavailableError("Warrant is shutting down.")
+	}
+
+	ctx, cancelFunc := context.WithTimeout(ctx, readinessTimeout)
+	defer cancelFunc()
+
+	db := svc.Env().DB()
+	err := db.Ping(ctx)
+	if err != nil {
+		// The underlying error may reveal database hosts, so only log it
+		log.Ctx(ctx).Warn().Err(err).Msg("health: database ping failed")
+		return service.NewServiceUnavailableError("Database is unreachable.")
+	}
+
+	version, dirty, err := db.MigrationVersion(ctx)
+	if err != nil {
+		log.Ctx(ctx).Warn().Err(err).Msg("health: could not get database migration version")
+		return service.NewServiceUnavailableError("Database migration version is unknown.")
+	}
+	if dirty {
+		return service.NewServiceUnavailableError(fmt.Sprintf("Database migration %d did not complete.", version))
+	}
+	if expectedVersion := svc.migrationVersions[db.Type()]; version != expectedVersion {
+		return service.NewServiceUnavailableError(fmt.Sprintf("Database is at migration %d, expected %d.", version, expectedVersion))
+	}
+
+	return nil
+}
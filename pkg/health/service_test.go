@@ -0,0 +1,91 @@
+// Copyright 2024 WorkOS, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package health
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/warrant-dev/warrant/pkg/database"
+	"github.com/warrant-dev/warrant/pkg/service"
+	"github.com/warrant-dev/warrant/pkg/wookie"
+)
+
+type testDatabase struct {
+	pingErr          error
+	migrationVersion uint
+	dirty            bool
+}
+
+func (db *testDatabase) Type() string                                      { return database.TypeSQLite }
+func (db *testDatabase) Connect(ctx context.Context) error                 { return nil }
+func (db *testDatabase) Migrate(ctx context.Context, toVersion uint) error { return nil }
+func (db *testDatabase) Ping(ctx context.Context) error                    { return db.pingErr }
+func (db *testDatabase) MigrationVersion(ctx context.Context) (uint, bool, error) {
+	return db.migrationVersion, db.dirty, nil
+}
+func (db *testDatabase) WithinTransaction(ctx context.Context, txCallback func(ctx context.Context) error) error {
+	return txCallback(ctx)
+}
+func (db *testDatabase) WithinConsistentTransaction(ctx context.Context, txCallback func(ctx context.Context) error) (*wookie.Token, error) {
+	return nil, txCallback(ctx)
+}
+
+type testEnv struct {
+	db *testDatabase
+}
+
+func (env testEnv) DB() database.Database {
+	return env.db
+}
+
+func assertUnavailable(t *testing.T, err error) {
+	t.Helper()
+	var apiError service.Error
+	if !errors.As(err, &apiError) || apiError.GetStatus() != http.StatusServiceUnavailable {
+		t.Fatalf("expected service unavailable error, got %v", err)
+	}
+}
+
+func TestCheckReady(t *testing.T) {
+	t.Parallel()
+	db := &testDatabase{migrationVersion: 13}
+	svc := NewService(testEnv{db: db}, map[string]uint{database.TypeSQLite: 13})
+	assertUnavailable(t, svc.CheckReady(context.Background()))
+
+	svc.SetReady()
+	if err := svc.CheckReady(context.Background()); err != nil {
+		t.Fatalf("expected ready, got %v", err)
+	}
+
+	db.pingErr = errors.New("connection refused")
+	assertUnavailable(t, svc.CheckReady(context.Background()))
+
+	db.pingErr = nil
+	db.migrationVersion = 12
+	assertUnavailable(t, svc.CheckReady(context.Background()))
+
+	db.migrationVersion = 13
+	db.dirty = true
+	assertUnavailable(t, svc.CheckReady(context.Background()))
+
+	db.dirty = false
+	svc.SetShuttingDown()
+	assertUnavailable(t, svc.CheckReady(context.Background()))
+	svc.SetReady()
+	assertUnavailable(t, svc.CheckReady(context.Background()))
+}
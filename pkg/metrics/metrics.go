@@ -0,0 +1,131 @@
+// Copyright 2024 WorkOS, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metrics
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "warrant"
+
+const (
+	CheckResultAuthorized    = "authorized"
+	CheckResultNotAuthorized = "not_authorized"
+	CheckResultError         = "error"
+	QueryResultSuccess       = "success"
+	QueryResultError         = "error"
+	SemaphoreService         = "service"
+	SemaphoreSubtask         = "subtask"
+)
+
+var (
+	httpRequestsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "Number of HTTP requests by route, method, and status code.",
+	}, []string{"route", "method", "status"})
+
+	httpRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "Latency of HTTP requests by route and method.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"route", "method"})
+
+	storeQueryDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "store_query_duration_seconds",
+		Help:      "Latency of database and cache queries by store and tag.",
+		Buckets:   []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5},
+	}, []string{"store", "tag"})
+
+	checkDepth = promauto.NewHistogram(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "check_depth",
+		Help:      "Depth of the steps evaluated by checks.",
+		Buckets:   []float64{1, 2, 3, 4, 5, 8, 10, 15, 20, 30, 50},
+	})
+
+	checkSubtasksTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "check_subtasks_total",
+		Help:      "Number of check subtasks by whether they ran on a new goroutine or inline because the subtask semaphore was full.",
+	}, []string{"mode"})
+
+	checkSemaphoreSaturatedTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "check_semaphore_saturated_total",
+		Help:      "Number of times a check found a semaphore full.",
+	}, []string{"semaphore"})
+
+	checkResultsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "check_results_total",
+		Help:      "Number of checks by result.",
+	}, []string{"result"})
+
+	queryResultsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "query_results_total",
+		Help:      "Number of queries by result.",
+	}, []string{"result"})
+)
+
+// Handler serves the metrics in the Prometheus text format.
+func Handler() http.Handler {
+	return promhttp.Handler()
+}
+
+func ObserveHTTPRequest(route string, method string, status string, duration time.Duration) {
+	httpRequestsTotal.WithLabelValues(route, method, status).Inc()
+	httpRequestDuration.WithLabelValues(route, method).Observe(duration.Seconds())
+}
+
+func ObserveStoreQuery(store string, tag string, duration time.Duration) {
+	storeQueryDuration.WithLabelValues(store, tag).Observe(duration.Seconds())
+}
+
+func ObserveCheckDepth(depth int) {
+	checkDepth.Observe(float64(depth))
+}
+
+// IncCheckSubtasks counts a check subtask, which ran inline if it couldn't
+// get its own goroutine.
+func IncCheckSubtasks(inline bool) {
+	if inline {
+		checkSubtasksTotal.WithLabelValues("inline").Inc()
+		checkSemaphoreSaturatedTotal.WithLabelValues(SemaphoreSubtask).Inc()
+		return
+	}
+
+	checkSubtasksTotal.WithLabelValues("goroutine").Inc()
+}
+
+func IncCheckSemaphoreSaturated(semaphore string) {
+	checkSemaphoreSaturatedTotal.WithLabelValues(semaphore).Inc()
+}
+
+func IncCheckResults(result string) {
+	checkResultsTotal.WithLabelValues(result).Inc()
+}
+
+func IncQueryResults(result string) {
+	queryResultsTotal.WithLabelValues(result).Inc()
+}
@@ -20,6 +20,7 @@ import (
 
 	"github.com/gorilla/mux"
 	"github.com/warrant-dev/warrant/pkg/service"
+	"github.com/warrant-dev/warrant/pkg/wookie"
 )
 
 func (svc ObjectService) Routes() ([]service.Route, error) {
@@ -191,11 +192,12 @@ func updateHandler(svc ObjectService, w http.ResponseWriter, r *http.Request) er
 func deleteHandler(svc ObjectService, w http.ResponseWriter, r *http.Request) error {
 	objectType := mux.Vars(r)["objectType"]
 	objectId := mux.Vars(r)["objectId"]
-	_, err := svc.DeleteByObjectTypeAndId(r.Context(), objectType, objectId)
+	newWookie, err := svc.DeleteByObjectTypeAndId(r.Context(), objectType, objectId)
 	if err != nil {
 		return err
 	}
 
+	wookie.AddAsResponseHeader(w, newWookie)
 	w.Header().Set("Content-type", "application/json")
 	w.WriteHeader(http.StatusOK)
 	return nil
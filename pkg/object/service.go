@@ -21,6 +21,7 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/pkg/errors"
+	"github.com/warrant-dev/warrant/pkg/changelog"
 	"github.com/warrant-dev/warrant/pkg/service"
 )
 
@@ -56,7 +57,7 @@ func (svc ObjectService) Create(ctx context.Context, objectSpec CreateObjectSpec
 	}
 
 	var createdObject Model
-	err := svc.Env().DB().WithinTransaction(ctx, func(txCtx context.Context) error {
+	_, err := svc.Env().DB().WithinConsistentTransaction(ctx, func(txCtx context.Context) error {
 		newObject, err := objectSpec.ToObject()
 		if err != nil {
 			return err
@@ -72,7 +73,12 @@ func (svc ObjectService) Create(ctx context.Context, objectSpec CreateObjectSpec
 			return err
 		}
 
-		return nil
+		createdObjectSpec, err := createdObject.ToObjectSpec()
+		if err != nil {
+			return err
+		}
+
+		return svc.RecordChange(txCtx, changelog.TypeObjectCreated, nil, createdObjectSpec)
 	})
 
 	if err != nil {
@@ -131,12 +137,17 @@ func (svc ObjectService) List(ctx context.Context, filterOptions *FilterOptions,
 
 func (svc ObjectService) UpdateByObjectTypeAndId(ctx context.Context, objectType string, objectId string, updateSpec UpdateObjectSpec) (*ObjectSpec, error) {
 	var updatedObject Model
-	err := svc.Env().DB().WithinTransaction(ctx, func(txCtx context.Context) error {
+	_, err := svc.Env().DB().WithinConsistentTransaction(ctx, func(txCtx context.Context) error {
 		currentObject, err := svc.repository.GetByObjectTypeAndId(txCtx, objectType, objectId)
 		if err != nil {
 			return err
 		}
 
+		currentObjectSpec, err := currentObject.ToObjectSpec()
+		if err != nil {
+			return err
+		}
+
 		err = currentObject.SetMeta(updateSpec.Meta)
 		if err != nil {
 			return err
@@ -152,7 +163,12 @@ func (svc ObjectService) UpdateByObjectTypeAndId(ctx context.Context, objectType
 			return err
 		}
 
-		return nil
+		updatedObjectSpec, err := updatedObject.ToObjectSpec()
+		if err != nil {
+			return err
+		}
+
+		return svc.RecordChange(txCtx, changelog.TypeObjectUpdated, currentObjectSpec, updatedObjectSpec)
 	})
 
 	if err != nil {
@@ -163,8 +179,8 @@ func (svc ObjectService) UpdateByObjectTypeAndId(ctx context.Context, objectType
 }
 
 func (svc ObjectService) DeleteByObjectTypeAndId(ctx context.Context, objectType string, objectId string) (*wookie.Token, error) {
-	err := svc.Env().DB().WithinTransaction(ctx, func(txCtx context.Context) error {
-		_, err := svc.GetByObjectTypeAndId(txCtx, objectType, objectId)
+	newWookie, err := svc.Env().DB().WithinConsistentTransaction(ctx, func(txCtx context.Context) error {
+		deletedObject, err := svc.GetByObjectTypeAndId(txCtx, objectType, objectId)
 		if err != nil {
 			return err
 		}
@@ -186,13 +202,15 @@ func (svc ObjectService) DeleteByObjectTypeAndId(ctx context.Context, objectType
 			return err
 		}
 
-		return nil
+		// Warrants deleted along with the object are covered by this change
+		return svc.RecordChange(txCtx, changelog.TypeObjectDeleted, deletedObject, nil)
 	})
 
 	if err != nil {
 		return nil, err
 	}
 
-	//nolint:nilnil
-	return nil, nil
+	// Warrants of any object type may have been deleted where this object is the subject
+	svc.NotifyWrite(ctx)
+	return newWookie, nil
 }
@@ -16,22 +16,19 @@ package service
 
 import (
 	"context"
-	"crypto/rsa"
+	"crypto/sha256"
 	"crypto/subtle"
-	"encoding/json"
+	"encoding/hex"
 	"errors"
 	"fmt"
-	"io"
 	"net/http"
+	"slices"
 	"strings"
 
-	jwt "github.com/golang-jwt/jwt/v5"
 	"github.com/rs/zerolog/hlog"
 	"github.com/warrant-dev/warrant/pkg/config"
 )
 
-const FirebasePublicKeyUrl = "https://www.googleapis.com/robot/v1/metadata/x509/securetoken@system.gserviceaccount.com"
-
 type key int
 
 const (
@@ -43,9 +40,54 @@ const (
 	AuthTypeBearer = "Bearer"
 )
 
+// The id of the API key configured via authentication.apiKey.
+const DefaultApiKeyId = "default"
+
+const (
+	ScopeAdmin            = "admin"
+	ScopeReadOnly         = "read-only"
+	ScopeCheckOnly        = "check-only"
+	ScopeWarrantsWrite    = "warrants:write"
+	ScopeObjectTypesAdmin = "object-types:admin"
+)
+
+// Requests to these paths are checks or queries, which are reads even when made with POST.
+var checkAndQueryPaths = []string{"/v1/authorize", "/v2/authorize", "/v2/check", "/v1/query", "/v2/query"}
+
+// Requests to these paths, including reads, require the admin scope.
+var adminOnlyPaths = []string{"/v2/api-keys", "/v2/audit-logs", "/v2/webhooks"}
+
 type AuthInfo struct {
 	UserId   string
 	TenantId string
+	ApiKeyId string
+	Scopes   []string
+}
+
+// ApiKeyResolver looks up API keys that aren't set in the server's config,
+// like those stored in the database.
+type ApiKeyResolver interface {
+	// ResolveApiKey returns the AuthInfo of the API key with the given hash,
+	// or nil if there is no such key.
+	ResolveApiKey(ctx context.Context, keyHash string) (*AuthInfo, error)
+}
+
+type apiKeyResolverKey struct{}
+
+// ApiKeyResolverMiddleware makes resolver available to the auth middlewares
+// of the requests it wraps. It must be added as a router middleware.
+func ApiKeyResolverMiddleware(resolver ApiKeyResolver) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), apiKeyResolverKey{}, resolver)))
+		})
+	}
+}
+
+// HashApiKey returns the hex-encoded SHA-256 hash API keys are stored by.
+func HashApiKey(apiKey string) string {
+	hash := sha256.Sum256([]byte(apiKey))
+	return hex.EncodeToString(hash[:])
 }
 
 type AuthMiddlewareFunc func(config config.Config, next http.Handler) (http.Handler, error)
@@ -63,12 +105,13 @@ func ApiKeyAuthMiddleware(cfg config.Config, next http.Handler) (http.Handler, e
 			return
 		}
 
-		if !secureCompareEqual(tokenString, warrantCfg.GetAuthentication().ApiKey) {
-			SendErrorResponse(w, NewUnauthorizedError("Invalid API key"))
+		authInfo, err := authenticateApiKey(r, warrantCfg.GetAuthentication(), tokenString)
+		if err != nil {
+			SendErrorResponse(w, err)
 			return
 		}
 
-		newContext := context.WithValue(r.Context(), authInfoKey, &AuthInfo{})
+		newContext := context.WithValue(r.Context(), authInfoKey, *authInfo)
 		next.ServeHTTP(w, r.WithContext(newContext))
 	}), nil
 }
@@ -79,6 +122,11 @@ func ApiKeyAndSessionAuthMiddleware(cfg config.Config, next http.Handler) (http.
 		return nil, errors.New("cfg parameter on DefaultAuthMiddleware must be a WarrantConfig")
 	}
 
+	tokenVerifiers, err := getTokenVerifiers(warrantCfg.GetAuthentication())
+	if err != nil {
+		return nil, err
+	}
+
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		logger := hlog.FromRequest(r)
 		tokenType, tokenString, err := parseAuthTokenFromRequest(r, []string{AuthTypeApiKey, AuthTypeBearer})
@@ -90,105 +138,23 @@ func ApiKeyAndSessionAuthMiddleware(cfg config.Config, next http.Handler) (http.
 		var authInfo *AuthInfo
 		switch tokenType {
 		case AuthTypeApiKey:
-			if !secureCompareEqual(tokenString, warrantCfg.GetAuthentication().ApiKey) {
-				SendErrorResponse(w, NewUnauthorizedError("Invalid API key"))
+			authInfo, err = authenticateApiKey(r, warrantCfg.GetAuthentication(), tokenString)
+			if err != nil {
+				SendErrorResponse(w, err)
 				return
 			}
-
-			authInfo = &AuthInfo{}
 		case AuthTypeBearer:
-			if warrantCfg.GetAuthentication().Provider == nil {
+			if len(tokenVerifiers) == 0 {
 				SendErrorResponse(w, NewInternalError("Error validating token"))
 				logger.Err(fmt.Errorf("invalid authentication provider configuration")).Msg("auth: must configure an authentication provider to allow requests that use third party auth tokens.")
 				return
 			}
 
-			var publicKey *rsa.PublicKey
-			var publicKeys map[string]string
-			var err error
-			switch warrantCfg.GetAuthentication().Provider.Name {
-			case "firebase":
-				// Retrieve Firebase public keys
-				response, err := http.Get(FirebasePublicKeyUrl)
-				if err != nil {
-					SendErrorResponse(w, NewInternalError("Error validating token"))
-					logger.Err(err).Msg("auth: error fetching Firebase public keys")
-					return
-				}
-
-				defer response.Body.Close()
-
-				contents, err := io.ReadAll(response.Body)
-				if err != nil {
-					SendErrorResponse(w, NewInternalError("Error validating token"))
-					logger.Err(err).Msg("auth: error reading Firebase public keys")
-					return
-				}
-
-				err = json.Unmarshal(contents, &publicKeys)
-				if err != nil {
-					SendErrorResponse(w, NewInternalError("Error validating token"))
-					logger.Err(err).Msg("auth: error unmarshalling Firebase public keys")
-					return
-				}
-			default:
-				publicKey, err = jwt.ParseRSAPublicKeyFromPEM([]byte(warrantCfg.GetAuthentication().Provider.PublicKey))
-				if err != nil {
-					SendErrorResponse(w, NewInternalError("Error validating token"))
-					logger.Err(fmt.Errorf("invalid authentication provider configuration")).Msg("auth: invalid public key for configured authentication provider")
-					return
-				}
-			}
-
-			checkedToken, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-				if _, ok := token.Method.(*jwt.SigningMethodRSA); !ok {
-					return nil, NewUnauthorizedError(fmt.Sprintf("Invalid %s token: unexpected signing method %v", tokenType, token.Header["alg"]))
-				}
-
-				if warrantCfg.GetAuthentication().Provider.Name == "firebase" {
-					publicKey, err = jwt.ParseRSAPublicKeyFromPEM([]byte(publicKeys[token.Header["kid"].(string)]))
-					if err != nil {
-						return nil, NewUnauthorizedError("Invalid token")
-					}
-				}
-
-				return publicKey, nil
-			})
-
+			authInfo, err = authenticateToken(r, tokenVerifiers, tokenString)
 			if err != nil {
-				if errors.Is(err, jwt.ErrTokenExpired) {
-					SendErrorResponse(w, NewTokenExpiredError())
-					return
-				}
-
-				SendErrorResponse(w, NewUnauthorizedError("Invalid token"))
-				return
-			}
-
-			if !checkedToken.Valid {
-				SendErrorResponse(w, NewUnauthorizedError("Invalid token"))
-				return
-			}
-
-			// Get claims
-			tokenClaims := checkedToken.Claims.(jwt.MapClaims)
-			if _, ok := tokenClaims[warrantCfg.GetAuthentication().Provider.UserIdClaim]; !ok {
-				SendErrorResponse(w, NewUnauthorizedError("Invalid token"))
-				logger.Warn().Msgf("auth: unable to retrieve user id from token with given identifier: %s", warrantCfg.GetAuthentication().Provider.UserIdClaim)
+				SendErrorResponse(w, err)
 				return
 			}
-
-			authInfo = &AuthInfo{
-				UserId: tokenClaims[warrantCfg.GetAuthentication().Provider.UserIdClaim].(string),
-			}
-
-			if warrantCfg.GetAuthentication().Provider.TenantIdClaim != "" {
-				if _, ok := tokenClaims[warrantCfg.GetAuthentication().Provider.TenantIdClaim]; !ok {
-					SendErrorResponse(w, NewUnauthorizedError("Invalid token"))
-					logger.Warn().Msgf("auth: unable to retrieve tenant id from token with given identifier: %s", warrantCfg.GetAuthentication().Provider.TenantIdClaim)
-				}
-				authInfo.TenantId = tokenClaims[warrantCfg.GetAuthentication().Provider.TenantIdClaim].(string)
-			}
 		}
 
 		newContext := context.WithValue(r.Context(), authInfoKey, *authInfo)
@@ -240,6 +206,100 @@ func parseAuthTokenFromRequest(r *http.Request, validTokenTypes []string) (strin
 	return authTokenType, authToken, nil
 }
 
+// Returns the AuthInfo of the given API key if it's valid and its scopes allow the request.
+func authenticateApiKey(r *http.Request, authConfig *config.AuthConfig, apiKey string) (*AuthInfo, error) {
+	authInfo, err := resolveApiKey(r, authConfig, apiKey)
+	if err != nil {
+		return nil, err
+	}
+
+	// Match the route's pattern so that the router's path prefix is ignored
+	routePattern, ok := getRoutePatternFromContext(r.Context())
+	if !ok {
+		routePattern = r.URL.Path
+	}
+	if !scopesAllowRequest(authInfo.Scopes, r.Method, routePattern) {
+		return nil, NewForbiddenError(fmt.Sprintf("API key %s does not have a scope that allows this request", authInfo.ApiKeyId))
+	}
+
+	return authInfo, nil
+}
+
+func resolveApiKey(r *http.Request, authConfig *config.AuthConfig, apiKey string) (*AuthInfo, error) {
+	if authConfig.ApiKey != "" && secureCompareEqual(apiKey, authConfig.ApiKey) {
+		return &AuthInfo{
+			ApiKeyId: DefaultApiKeyId,
+			Scopes:   []string{ScopeAdmin},
+		}, nil
+	}
+
+	keyHash := HashApiKey(apiKey)
+	for _, apiKeyConfig := range authConfig.ApiKeys {
+		if secureCompareEqual(keyHash, strings.ToLower(apiKeyConfig.KeyHash)) {
+			return &AuthInfo{
+				ApiKeyId: apiKeyConfig.Name,
+				Scopes:   apiKeyConfig.Scopes,
+			}, nil
+		}
+	}
+
+	if resolver, ok := r.Context().Value(apiKeyResolverKey{}).(ApiKeyResolver); ok {
+		authInfo, err := resolver.ResolveApiKey(r.Context(), keyHash)
+		if err != nil {
+			hlog.FromRequest(r).Err(err).Msg("auth: error resolving API key")
+			return nil, NewInternalError("Error validating API key")
+		}
+
+		if authInfo != nil {
+			return authInfo, nil
+		}
+	}
+
+	return nil, NewUnauthorizedError("Invalid API key")
+}
+
+// Returns true if any of the given scopes allows a request with the given
+// method to the given path or route pattern.
+func scopesAllowRequest(scopes []string, method string, path string) bool {
+	if slices.Contains(scopes, ScopeAdmin) {
+		return true
+	}
+
+	for _, adminOnlyPath := range adminOnlyPaths {
+		if hasPathPrefix(path, adminOnlyPath) {
+			return false
+		}
+	}
+
+	isCheckOrQuery := slices.Contains(checkAndQueryPaths, path)
+	for _, scope := range scopes {
+		switch scope {
+		case ScopeReadOnly:
+			if method == http.MethodGet || isCheckOrQuery {
+				return true
+			}
+		case ScopeCheckOnly:
+			if isCheckOrQuery {
+				return true
+			}
+		case ScopeWarrantsWrite:
+			if hasPathPrefix(path, "/v1/warrants") || hasPathPrefix(path, "/v2/warrants") {
+				return true
+			}
+		case ScopeObjectTypesAdmin:
+			if hasPathPrefix(path, "/v1/object-types") || hasPathPrefix(path, "/v2/object-types") {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func hasPathPrefix(path string, prefix string) bool {
+	return path == prefix || strings.HasPrefix(path, prefix+"/")
+}
+
 func secureCompareEqual(given string, actual string) bool {
 	if subtle.ConstantTimeEq(int32(len(given)), int32(len(actual))) == 1 {
 		return subtle.ConstantTimeCompare([]byte(given), []byte(actual)) == 1
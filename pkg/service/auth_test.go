@@ -0,0 +1,114 @@
+// Copyright 2024 WorkOS, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/warrant-dev/warrant/pkg/config"
+)
+
+func TestScopesAllowRequest(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		scopes  []string
+		method  string
+		path    string
+		allowed bool
+	}{
+		{[]string{ScopeAdmin}, http.MethodPost, "/v2/api-keys", true},
+		{[]string{ScopeAdmin}, http.MethodDelete, "/v2/object-types/user", true},
+		{[]string{ScopeReadOnly}, http.MethodGet, "/v2/warrants", true},
+		{[]string{ScopeReadOnly}, http.MethodPost, "/v2/check", true},
+		{[]string{ScopeReadOnly}, http.MethodPost, "/v2/warrants", false},
+		{[]string{ScopeReadOnly}, http.MethodGet, "/v2/api-keys", false},
+		{[]string{ScopeReadOnly}, http.MethodGet, "/v2/audit-logs", false},
+		{[]string{ScopeReadOnly}, http.MethodGet, "/v2/webhooks/{webhookId}", false},
+		{[]string{ScopeReadOnly}, http.MethodGet, "/v2/changes", true},
+		{[]string{ScopeReadOnly}, http.MethodGet, "/v2/objects/{objectType}/{objectId}", true},
+		{[]string{ScopeCheckOnly}, http.MethodPost, "/v2/check", true},
+		{[]string{ScopeCheckOnly}, http.MethodGet, "/v2/query", true},
+		{[]string{ScopeCheckOnly}, http.MethodGet, "/v2/warrants", false},
+		{[]string{ScopeWarrantsWrite}, http.MethodPost, "/v2/warrants", true},
+		{[]string{ScopeWarrantsWrite}, http.MethodDelete, "/v1/warrants", true},
+		{[]string{ScopeWarrantsWrite}, http.MethodPost, "/v2/warrantsx", false},
+		{[]string{ScopeWarrantsWrite}, http.MethodPost, "/v2/objects", false},
+		{[]string{ScopeObjectTypesAdmin}, http.MethodPut, "/v2/object-types/user", true},
+		{[]string{ScopeObjectTypesAdmin}, http.MethodPut, "/v2/object-types/{type}", true},
+		{[]string{ScopeObjectTypesAdmin}, http.MethodPost, "/v2/warrants", false},
+		{[]string{ScopeCheckOnly, ScopeWarrantsWrite}, http.MethodPost, "/v2/warrants", true},
+		{[]string{}, http.MethodGet, "/v2/warrants", false},
+	}
+
+	for _, test := range tests {
+		if allowed := scopesAllowRequest(test.scopes, test.method, test.path); allowed != test.allowed {
+			t.Errorf("scopesAllowRequest(%v, %s, %s) = %t, expected %t", test.scopes, test.method, test.path, allowed, test.allowed)
+		}
+	}
+}
+
+func TestScopesMatchRoutesRegardlessOfPathPrefix(t *testing.T) {
+	t.Parallel()
+	cfg := config.WarrantConfig{
+		LogLevel: 1,
+		Authentication: &config.AuthConfig{
+			ApiKeys: []config.ApiKeyConfig{
+				{
+					Name:    "reader",
+					KeyHash: HashApiKey("reader-key"),
+					Scopes:  []string{ScopeReadOnly},
+				},
+			},
+		},
+	}
+	okHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	routes := []Route{
+		WarrantRoute{Pattern: "/v2/objects/{objectType}", Method: http.MethodGet, Handler: okHandler},
+		WarrantRoute{Pattern: "/v2/audit-logs", Method: http.MethodGet, Handler: okHandler},
+	}
+	router, err := NewRouter(cfg, "/api", routes, ApiKeyAuthMiddleware, nil, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		path     string
+		expected int
+	}{
+		{"/api/v2/objects/user", http.StatusOK},
+		{"/api/v2/audit-logs", http.StatusForbidden},
+	}
+	for _, test := range tests {
+		req := httptest.NewRequest(http.MethodGet, test.path, nil)
+		req.Header.Set("Authorization", "ApiKey reader-key")
+		res := httptest.NewRecorder()
+		router.ServeHTTP(res, req)
+		if res.Code != test.expected {
+			t.Errorf("expected GET %s with a read-only key to get %d, got %d", test.path, test.expected, res.Code)
+		}
+	}
+}
+
+func TestHashApiKey(t *testing.T) {
+	t.Parallel()
+	expected := "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824"
+	if hash := HashApiKey("hello"); hash != expected {
+		t.Errorf("HashApiKey(hello) = %s, expected %s", hash, expected)
+	}
+}
@@ -0,0 +1,82 @@
+// Copyright 2024 WorkOS, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/warrant-dev/warrant/pkg/config"
+)
+
+// CORSMiddleware allows browsers on the configured origins to make requests
+// and responds to preflight requests for all routes. Requests from other
+// origins are rejected with an UnknownOriginError. It must be added as a
+// router middleware so that it also runs for OPTIONS requests.
+func CORSMiddleware(cfg *config.CORSConfig) Middleware {
+	allowedMethods := strings.Join(cfg.AllowedMethods, ", ")
+	allowedHeaders := strings.Join(cfg.AllowedHeaders, ", ")
+	maxAge := strconv.Itoa(int(cfg.MaxAge.Seconds()))
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			origin := r.Header.Get("Origin")
+			if origin == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			w.Header().Add("Vary", "Origin")
+			if !isAllowedOrigin(cfg.AllowedOrigins, origin) {
+				SendErrorResponse(w, NewUnknownOriginError(origin))
+				return
+			}
+
+			w.Header().Set("Access-Control-Allow-Origin", origin)
+			// Lets the browser expose the Server-Timing header to the origin
+			w.Header().Set("Timing-Allow-Origin", origin)
+			if r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != "" {
+				w.Header().Set("Access-Control-Allow-Methods", allowedMethods)
+				w.Header().Set("Access-Control-Allow-Headers", allowedHeaders)
+				w.Header().Set("Access-Control-Max-Age", maxAge)
+				w.WriteHeader(http.StatusNoContent)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// Returns true if origin matches one of allowedOrigins. An allowed origin of
+// * matches all origins, and one like https://*.example.com matches all
+// subdomains of example.com.
+func isAllowedOrigin(allowedOrigins []string, origin string) bool {
+	for _, allowedOrigin := range allowedOrigins {
+		if allowedOrigin == "*" || strings.EqualFold(allowedOrigin, origin) {
+			return true
+		}
+
+		prefix, suffix, isWildcard := strings.Cut(allowedOrigin, "*.")
+		if isWildcard && len(origin) > len(prefix)+len(suffix)+1 &&
+			strings.HasPrefix(strings.ToLower(origin), strings.ToLower(prefix)) &&
+			strings.HasSuffix(strings.ToLower(origin), "."+strings.ToLower(suffix)) {
+			return true
+		}
+	}
+
+	return false
+}
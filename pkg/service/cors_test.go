@@ -0,0 +1,47 @@
+// Copyright 2024 WorkOS, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import "testing"
+
+func TestIsAllowedOrigin(t *testing.T) {
+	t.Parallel()
+	allowedOrigins := []string{"https://app.example.com", "https://*.example.org"}
+	tests := []struct {
+		origin  string
+		allowed bool
+	}{
+		{"https://app.example.com", true},
+		{"https://APP.example.com", true},
+		{"http://app.example.com", false},
+		{"https://other.example.com", false},
+		{"https://app.example.org", true},
+		{"https://a.b.example.org", true},
+		{"https://example.org", false},
+		{"https://.example.org", false},
+		{"https://evilexample.org", false},
+		{"http://app.example.org", false},
+	}
+
+	for _, test := range tests {
+		if allowed := isAllowedOrigin(allowedOrigins, test.origin); allowed != test.allowed {
+			t.Errorf("isAllowedOrigin(%s) = %t, expected %t", test.origin, allowed, test.allowed)
+		}
+	}
+
+	if !isAllowedOrigin([]string{"*"}, "https://anything.example.net") {
+		t.Errorf("expected * to allow all origins")
+	}
+}
@@ -20,13 +20,17 @@ import (
 )
 
 const (
+	ErrorConflict                 = "conflict"
 	ErrorDuplicateRecord          = "duplicate_record"
 	ErrorForbidden                = "forbidden"
 	ErrorInternalError            = "internal_error"
 	ErrorInvalidRequest           = "invalid_request"
 	ErrorInvalidParameter         = "invalid_parameter"
+	ErrorMaxDepthExceeded         = "max_depth_exceeded"
 	ErrorMissingRequiredParameter = "missing_required_parameter"
 	ErrorNotFound                 = "not_found"
+	ErrorPreconditionFailed       = "precondition_failed"
+	ErrorServiceUnavailable       = "service_unavailable"
 	ErrorTokenExpired             = "token_expired"
 	ErrorTooManyRequests          = "too_many_requests"
 	ErrorUnauthorized             = "unauthorized"
@@ -120,6 +124,24 @@ func (err *InvalidParameterError) Error() string {
 	return fmt.Sprintf("%s: Invalid parameter %s, %s", err.GetTag(), err.Parameter, err.Message)
 }
 
+// MaxDepthExceededError type
+type MaxDepthExceededError struct {
+	*GenericError
+	MaxDepth int `json:"maxDepth"`
+}
+
+func NewMaxDepthExceededError(maxDepth int) *MaxDepthExceededError {
+	return &MaxDepthExceededError{
+		GenericError: NewGenericError(
+			"MaxDepthExceededError",
+			ErrorMaxDepthExceeded,
+			http.StatusBadRequest,
+			fmt.Sprintf("Evaluation exceeded the max depth of %d.", maxDepth),
+		),
+		MaxDepth: maxDepth,
+	}
+}
+
 // MissingRequiredParameterError type
 type MissingRequiredParameterError struct {
 	*GenericError
@@ -138,6 +160,24 @@ func NewMissingRequiredParameterError(parameterName string) *MissingRequiredPara
 	}
 }
 
+// PreconditionFailedError type
+type PreconditionFailedError struct {
+	*GenericError
+	Key interface{} `json:"key"`
+}
+
+func NewPreconditionFailedError(key interface{}, reason string) *PreconditionFailedError {
+	return &PreconditionFailedError{
+		GenericError: NewGenericError(
+			"PreconditionFailedError",
+			ErrorPreconditionFailed,
+			http.StatusPreconditionFailed,
+			fmt.Sprintf("Precondition failed, %v %s", key, reason),
+		),
+		Key: key,
+	}
+}
+
 // RecordNotFoundError type
 type RecordNotFoundError struct {
 	*GenericError
@@ -158,6 +198,22 @@ func NewRecordNotFoundError(recordType string, recordKey interface{}) *RecordNot
 	}
 }
 
+// ConflictError type
+type ConflictError struct {
+	*GenericError
+}
+
+func NewConflictError(msg string) *ConflictError {
+	return &ConflictError{
+		NewGenericError(
+			"ConflictError",
+			ErrorConflict,
+			http.StatusConflict,
+			msg,
+		),
+	}
+}
+
 // DuplicateRecordError type
 type DuplicateRecordError struct {
 	*GenericError
@@ -183,6 +239,22 @@ func NewDuplicateRecordError(recordType string, recordKey interface{}, reason st
 	}
 }
 
+// ServiceUnavailableError type
+type ServiceUnavailableError struct {
+	*GenericError
+}
+
+func NewServiceUnavailableError(msg string) *ServiceUnavailableError {
+	return &ServiceUnavailableError{
+		NewGenericError(
+			"ServiceUnavailableError",
+			ErrorServiceUnavailable,
+			http.StatusServiceUnavailable,
+			msg,
+		),
+	}
+}
+
 // TokenExpiredError type
 type TokenExpiredError struct {
 	*GenericError
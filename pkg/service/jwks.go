@@ -0,0 +1,266 @@
+// Copyright 2024 WorkOS, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// Keys are refetched at most this often when a token references an unknown kid.
+const minJwksRefreshInterval = 10 * time.Second
+
+var errJwksUnavailable = errors.New("jwks unavailable")
+
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Crv string `json:"crv"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+type jsonWebKeySet struct {
+	Keys []jsonWebKey `json:"keys"`
+}
+
+// A set of public keys fetched from a JWKS endpoint. Keys are refreshed in the
+// background every ttl and on demand when a token is signed by an unknown key,
+// so that keys rotated by the provider are picked up without a restart.
+type jwksKeySet struct {
+	url          string
+	ttl          time.Duration
+	client       *http.Client
+	mutex        sync.RWMutex
+	keys         map[string]interface{}
+	fetchedAt    time.Time
+	refreshMutex sync.Mutex
+	refreshedAt  time.Time
+}
+
+func newJwksKeySet(url string, ttl time.Duration) *jwksKeySet {
+	return &jwksKeySet{
+		url: url,
+		ttl: ttl,
+		client: &http.Client{
+			Timeout: 10 * time.Second,
+		},
+		keys: make(map[string]interface{}),
+	}
+}
+
+// Refreshes the keys every ttl until ctx is done.
+func (keySet *jwksKeySet) start(ctx context.Context) {
+	ticker := time.NewTicker(keySet.ttl)
+	defer ticker.Stop()
+
+	for {
+		err := keySet.refresh(ctx, 0)
+		if err != nil {
+			log.Error().Err(err).Msgf("auth: error refreshing JWKS from %s", keySet.url)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Returns the key with the given kid. If kid is empty, the key set must
+// contain exactly one key.
+func (keySet *jwksKeySet) getKey(ctx context.Context, kid string) (interface{}, error) {
+	key, found, stale := keySet.lookup(kid)
+	if found && !stale {
+		return key, nil
+	}
+
+	err := keySet.refresh(ctx, minJwksRefreshInterval)
+	if err != nil {
+		if found {
+			// Prefer a stale key to failing every request while the endpoint is down
+			log.Warn().Err(err).Msgf("auth: error refreshing JWKS from %s, using cached keys", keySet.url)
+			return key, nil
+		}
+		return nil, fmt.Errorf("%w: %w", errJwksUnavailable, err)
+	}
+
+	key, found, _ = keySet.lookup(kid)
+	if !found {
+		return nil, fmt.Errorf("no key found for kid %q", kid)
+	}
+
+	return key, nil
+}
+
+func (keySet *jwksKeySet) lookup(kid string) (interface{}, bool, bool) {
+	keySet.mutex.RLock()
+	defer keySet.mutex.RUnlock()
+	stale := time.Since(keySet.fetchedAt) > keySet.ttl
+	if kid == "" {
+		if len(keySet.keys) != 1 {
+			return nil, false, stale
+		}
+		for _, key := range keySet.keys {
+			return key, true, stale
+		}
+	}
+
+	key, ok := keySet.keys[kid]
+	return key, ok, stale
+}
+
+// Fetches the keys unless they were fetched less than minInterval ago.
+func (keySet *jwksKeySet) refresh(ctx context.Context, minInterval time.Duration) error {
+	keySet.refreshMutex.Lock()
+	defer keySet.refreshMutex.Unlock()
+	if minInterval > 0 && time.Since(keySet.refreshedAt) < minInterval {
+		return nil
+	}
+
+	keySet.refreshedAt = time.Now()
+	keys, err := keySet.fetch(ctx)
+	if err != nil {
+		return err
+	}
+
+	keySet.mutex.Lock()
+	defer keySet.mutex.Unlock()
+	keySet.keys = keys
+	keySet.fetchedAt = time.Now()
+	return nil
+}
+
+func (keySet *jwksKeySet) fetch(ctx context.Context) (map[string]interface{}, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, keySet.url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := keySet.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+
+	var jwks jsonWebKeySet
+	err = json.NewDecoder(resp.Body).Decode(&jwks)
+	if err != nil {
+		return nil, fmt.Errorf("error decoding JWKS: %w", err)
+	}
+
+	keys := make(map[string]interface{})
+	for _, jwk := range jwks.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+
+		key, err := jwk.publicKey()
+		if err != nil {
+			log.Warn().Err(err).Msgf("auth: skipping key %s from JWKS %s", jwk.Kid, keySet.url)
+			continue
+		}
+
+		keys[jwk.Kid] = key
+	}
+
+	return keys, nil
+}
+
+func (jwk jsonWebKey) publicKey() (interface{}, error) {
+	switch jwk.Kty {
+	case "RSA":
+		n, err := decodeJwkParam(jwk.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeJwkParam(jwk.E)
+		if err != nil {
+			return nil, err
+		}
+		if !e.IsInt64() {
+			return nil, errors.New("invalid RSA exponent")
+		}
+
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch jwk.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve %s", jwk.Crv)
+		}
+		x, err := decodeJwkParam(jwk.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeJwkParam(jwk.Y)
+		if err != nil {
+			return nil, err
+		}
+
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	case "OKP":
+		if jwk.Crv != "Ed25519" {
+			return nil, fmt.Errorf("unsupported curve %s", jwk.Crv)
+		}
+		x, err := base64.RawURLEncoding.DecodeString(jwk.X)
+		if err != nil {
+			return nil, err
+		}
+		if len(x) != ed25519.PublicKeySize {
+			return nil, errors.New("invalid Ed25519 key size")
+		}
+
+		return ed25519.PublicKey(x), nil
+	default:
+		return nil, fmt.Errorf("unsupported key type %s", jwk.Kty)
+	}
+}
+
+func decodeJwkParam(param string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(param)
+	if err != nil {
+		return nil, err
+	}
+
+	return new(big.Int).SetBytes(b), nil
+}
@@ -0,0 +1,342 @@
+// Copyright 2024 WorkOS, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/warrant-dev/warrant/pkg/config"
+)
+
+// Idle buckets are evicted at most this often.
+const rateLimitEvictionInterval = time.Minute
+
+type rateLimit struct {
+	rate  float64
+	burst float64
+}
+
+type tokenBucket struct {
+	limit     rateLimit
+	tokens    float64
+	updatedAt time.Time
+}
+
+// Refills the bucket for the time elapsed since it was last updated.
+func (bucket *tokenBucket) refill(now time.Time) {
+	elapsed := now.Sub(bucket.updatedAt).Seconds()
+	if elapsed > 0 {
+		bucket.tokens = math.Min(bucket.limit.burst, bucket.tokens+elapsed*bucket.limit.rate)
+		bucket.updatedAt = now
+	}
+}
+
+// Token bucket rate limiter keyed by caller. Buckets are created full and
+// evicted once they've been idle long enough to refill.
+type rateLimiter struct {
+	mutex         sync.Mutex
+	buckets       map[string]*tokenBucket
+	lastEvictedAt time.Time
+}
+
+func newRateLimiter() *rateLimiter {
+	return &rateLimiter{
+		buckets:       make(map[string]*tokenBucket),
+		lastEvictedAt: time.Now(),
+	}
+}
+
+// Takes a token from the bucket for key. If the bucket is empty, returns false
+// and how long until a token is available.
+func (limiter *rateLimiter) allow(key string, limit rateLimit, now time.Time) (bool, time.Duration) {
+	limiter.mutex.Lock()
+	defer limiter.mutex.Unlock()
+	if now.Sub(limiter.lastEvictedAt) > rateLimitEvictionInterval {
+		limiter.evictIdle(now)
+	}
+
+	bucket, ok := limiter.buckets[key]
+	if !ok {
+		bucket = &tokenBucket{
+			limit:     limit,
+			tokens:    limit.burst,
+			updatedAt: now,
+		}
+		limiter.buckets[key] = bucket
+	}
+
+	bucket.refill(now)
+	if bucket.tokens >= 1 {
+		bucket.tokens--
+		return true, 0
+	}
+
+	return false, time.Duration((1 - bucket.tokens) / limit.rate * float64(time.Second))
+}
+
+// Returns true and how long until a token is available if the bucket for key
+// is empty. Unlike allow, no token is taken.
+func (limiter *rateLimiter) limited(key string, now time.Time) (bool, time.Duration) {
+	limiter.mutex.Lock()
+	defer limiter.mutex.Unlock()
+	bucket, ok := limiter.buckets[key]
+	if !ok {
+		return false, 0
+	}
+
+	bucket.refill(now)
+	if bucket.tokens >= 1 {
+		return false, 0
+	}
+
+	return true, time.Duration((1 - bucket.tokens) / bucket.limit.rate * float64(time.Second))
+}
+
+func (limiter *rateLimiter) evictIdle(now time.Time) {
+	for key, bucket := range limiter.buckets {
+		bucket.refill(now)
+		if bucket.tokens >= bucket.limit.burst {
+			delete(limiter.buckets, key)
+		}
+	}
+	limiter.lastEvictedAt = now
+}
+
+// RateLimitMiddleware limits the rate of requests made by each API key,
+// session user, or (for unauthenticated requests) client IP. Routes with their
+// own limit in cfg.Routes are limited separately from all other routes. It
+// must be added as a request middleware so that it runs after authentication.
+func RateLimitMiddleware(cfg *config.RateLimitConfig) (Middleware, error) {
+	trustedProxies, err := parseTrustedProxies(cfg.TrustedProxies)
+	if err != nil {
+		return nil, err
+	}
+
+	limiter := newRateLimiter()
+	defaultLimit := newRateLimit(cfg.RequestsPerSecond, cfg.Burst)
+	routeLimits := make(map[string]rateLimit)
+	for route, routeCfg := range cfg.Routes {
+		routeLimits[route] = newRateLimit(routeCfg.RequestsPerSecond, routeCfg.Burst)
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			limit := defaultLimit
+			key := rateLimitCallerKey(r, trustedProxies)
+			if route := mux.CurrentRoute(r); route != nil {
+				if pathTemplate, err := route.GetPathTemplate(); err == nil {
+					if routeLimit, ok := routeLimits[pathTemplate]; ok {
+						limit = routeLimit
+						key = pathTemplate + "|" + key
+					}
+				}
+			}
+
+			// A rate of 0 disables rate limiting
+			if limit.rate <= 0 {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			allowed, retryAfter := limiter.allow(key, limit, time.Now())
+			if !allowed {
+				sendTooManyRequestsResponse(w, retryAfter)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}, nil
+}
+
+// FailedAuthRateLimitMiddleware limits the rate of requests from each client
+// IP that fail authentication. Once a client is over the limit, its requests
+// are rejected before their credentials are checked, which slows down attempts
+// to guess API keys and session tokens. It must be added as a router
+// middleware so that it runs before authentication.
+func FailedAuthRateLimitMiddleware(cfg *config.RateLimitConfig) (Middleware, error) {
+	trustedProxies, err := parseTrustedProxies(cfg.TrustedProxies)
+	if err != nil {
+		return nil, err
+	}
+
+	limiter := newRateLimiter()
+	limit := newRateLimit(cfg.FailedAuthRequestsPerSecond, cfg.FailedAuthBurst)
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			// A rate of 0 disables rate limiting
+			if limit.rate <= 0 {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			key := "ip:" + rateLimitClientIp(r, trustedProxies)
+			if limited, retryAfter := limiter.limited(key, time.Now()); limited {
+				sendTooManyRequestsResponse(w, retryAfter)
+				return
+			}
+
+			statusWriter := &statusResponseWriter{ResponseWriter: w}
+			next.ServeHTTP(statusWriter, r)
+			if statusWriter.statusCode == http.StatusUnauthorized {
+				limiter.allow(key, limit, time.Now())
+			}
+		})
+	}, nil
+}
+
+func sendTooManyRequestsResponse(w http.ResponseWriter, retryAfter time.Duration) {
+	w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+	SendErrorResponse(w, NewTooManyRequestsError())
+}
+
+// Records the status code of the response written through it.
+type statusResponseWriter struct {
+	http.ResponseWriter
+	statusCode int
+}
+
+func (w *statusResponseWriter) WriteHeader(statusCode int) {
+	if w.statusCode == 0 {
+		w.statusCode = statusCode
+	}
+	w.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (w *statusResponseWriter) Write(b []byte) (int, error) {
+	if w.statusCode == 0 {
+		w.statusCode = http.StatusOK
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+// Streamed responses (e.g. server-sent events) need to be flushed.
+func (w *statusResponseWriter) Flush() {
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+func (w *statusResponseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+func newRateLimit(requestsPerSecond float64, burst int) rateLimit {
+	limit := rateLimit{
+		rate:  requestsPerSecond,
+		burst: float64(burst),
+	}
+	if limit.burst < 1 {
+		limit.burst = math.Max(1, math.Ceil(requestsPerSecond))
+	}
+
+	return limit
+}
+
+func rateLimitCallerKey(r *http.Request, trustedProxies []*net.IPNet) string {
+	if authInfo, err := GetAuthInfoFromRequestContext(r.Context()); err == nil {
+		if authInfo.UserId != "" {
+			return "user:" + authInfo.UserId
+		}
+		if authInfo.ApiKeyId != "" {
+			return "apiKey:" + authInfo.ApiKeyId
+		}
+	}
+
+	return "ip:" + rateLimitClientIp(r, trustedProxies)
+}
+
+// Returns the IP of the client that made r. X-Forwarded-For is only used for
+// requests from trusted proxies, in which case the client is the right-most
+// address not belonging to a trusted proxy. Any addresses to its left were
+// sent by the client itself and can't be trusted.
+func rateLimitClientIp(r *http.Request, trustedProxies []*net.IPNet) string {
+	clientIp := r.RemoteAddr
+	if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+		clientIp = host
+	}
+	if !isTrustedProxy(clientIp, trustedProxies) {
+		return clientIp
+	}
+
+	forwardedFor := strings.Split(strings.Join(r.Header.Values("X-Forwarded-For"), ","), ",")
+	for i := len(forwardedFor) - 1; i >= 0; i-- {
+		hop := strings.TrimSpace(forwardedFor[i])
+		if hop == "" {
+			continue
+		}
+
+		clientIp = hop
+		if !isTrustedProxy(hop, trustedProxies) {
+			break
+		}
+	}
+
+	return clientIp
+}
+
+func isTrustedProxy(ip string, trustedProxies []*net.IPNet) bool {
+	parsedIp := net.ParseIP(ip)
+	if parsedIp == nil {
+		return false
+	}
+
+	for _, trustedProxy := range trustedProxies {
+		if trustedProxy.Contains(parsedIp) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Parses trusted proxies given as IP addresses or CIDR ranges.
+func parseTrustedProxies(trustedProxies []string) ([]*net.IPNet, error) {
+	ipNets := make([]*net.IPNet, 0, len(trustedProxies))
+	for _, trustedProxy := range trustedProxies {
+		trustedProxy = strings.TrimSpace(trustedProxy)
+		if !strings.Contains(trustedProxy, "/") {
+			ip := net.ParseIP(trustedProxy)
+			if ip == nil {
+				return nil, fmt.Errorf("invalid trusted proxy %s, must be an IP address or CIDR range", trustedProxy)
+			}
+
+			bits := 8 * net.IPv6len
+			if ip.To4() != nil {
+				ip = ip.To4()
+				bits = 8 * net.IPv4len
+			}
+			ipNets = append(ipNets, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+
+		_, ipNet, err := net.ParseCIDR(trustedProxy)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trusted proxy %s, must be an IP address or CIDR range", trustedProxy)
+		}
+
+		ipNets = append(ipNets, ipNet)
+	}
+
+	return ipNets, nil
+}
@@ -0,0 +1,155 @@
+// Copyright 2024 WorkOS, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/warrant-dev/warrant/pkg/config"
+)
+
+func TestRateLimiterAllowsBurstThenRefills(t *testing.T) {
+	t.Parallel()
+	limiter := newRateLimiter()
+	limit := newRateLimit(2, 3)
+	now := time.Now()
+	for i := 0; i < 3; i++ {
+		if allowed, _ := limiter.allow("apiKey:a", limit, now); !allowed {
+			t.Fatalf("expected request %d to be allowed", i)
+		}
+	}
+
+	allowed, retryAfter := limiter.allow("apiKey:a", limit, now)
+	if allowed {
+		t.Fatalf("expected request over burst to be limited")
+	}
+	if retryAfter != 500*time.Millisecond {
+		t.Fatalf("expected retry after 500ms, got %s", retryAfter)
+	}
+
+	if allowed, _ := limiter.allow("apiKey:b", limit, now); !allowed {
+		t.Fatalf("expected request with another key to be allowed")
+	}
+
+	if allowed, _ := limiter.allow("apiKey:a", limit, now.Add(500*time.Millisecond)); !allowed {
+		t.Fatalf("expected request to be allowed after refill")
+	}
+}
+
+func TestRateLimiterEvictsIdleBuckets(t *testing.T) {
+	t.Parallel()
+	limiter := newRateLimiter()
+	limit := newRateLimit(1, 1)
+	now := time.Now()
+	limiter.allow("apiKey:a", limit, now)
+	limiter.allow("apiKey:b", limit, now.Add(rateLimitEvictionInterval))
+	limiter.allow("apiKey:c", limit, now.Add(rateLimitEvictionInterval+time.Second))
+	if _, ok := limiter.buckets["apiKey:a"]; ok {
+		t.Fatalf("expected idle bucket to be evicted")
+	}
+	if _, ok := limiter.buckets["apiKey:c"]; !ok {
+		t.Fatalf("expected active bucket to be kept")
+	}
+}
+
+func TestFailedAuthRateLimitMiddlewareLimitsFailedAuthByIP(t *testing.T) {
+	t.Parallel()
+	middleware, err := FailedAuthRateLimitMiddleware(&config.RateLimitConfig{
+		FailedAuthRequestsPerSecond: 1,
+		FailedAuthBurst:             2,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	handler := middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "ApiKey valid" {
+			SendErrorResponse(w, NewUnauthorizedError("Invalid API key"))
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	request := func(ip string, authorization string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/v2/objects", nil)
+		req.RemoteAddr = ip + ":1234"
+		req.Header.Set("Authorization", authorization)
+		res := httptest.NewRecorder()
+		handler.ServeHTTP(res, req)
+		return res
+	}
+
+	for i := 0; i < 3; i++ {
+		if res := request("10.0.0.1", "ApiKey valid"); res.Code != http.StatusOK {
+			t.Fatalf("expected authenticated request %d to not be limited, got %d", i, res.Code)
+		}
+	}
+	for i := 0; i < 2; i++ {
+		if res := request("10.0.0.1", "ApiKey guess"); res.Code != http.StatusUnauthorized {
+			t.Fatalf("expected failed auth %d within burst to get %d, got %d", i, http.StatusUnauthorized, res.Code)
+		}
+	}
+
+	res := request("10.0.0.1", "ApiKey valid")
+	if res.Code != http.StatusTooManyRequests {
+		t.Fatalf("expected request from IP over failed auth limit to get %d, got %d", http.StatusTooManyRequests, res.Code)
+	}
+	if res.Header().Get("Retry-After") != "1" {
+		t.Fatalf("expected Retry-After of 1, got %s", res.Header().Get("Retry-After"))
+	}
+
+	if res := request("10.0.0.2", "ApiKey guess"); res.Code != http.StatusUnauthorized {
+		t.Fatalf("expected failed auth from another IP to get %d, got %d", http.StatusUnauthorized, res.Code)
+	}
+}
+
+func TestRateLimitClientIpOnlyTrustsForwardedForFromTrustedProxies(t *testing.T) {
+	t.Parallel()
+	trustedProxies, err := parseTrustedProxies([]string{"10.0.0.0/8", "192.168.1.1"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	testCases := []struct {
+		remoteAddr   string
+		forwardedFor []string
+		expected     string
+	}{
+		{"203.0.113.5:1234", nil, "203.0.113.5"},
+		{"203.0.113.5:1234", []string{"198.51.100.1"}, "203.0.113.5"},
+		{"10.0.0.1:1234", nil, "10.0.0.1"},
+		{"10.0.0.1:1234", []string{"198.51.100.1"}, "198.51.100.1"},
+		{"10.0.0.1:1234", []string{"1.1.1.1, 198.51.100.1, 192.168.1.1"}, "198.51.100.1"},
+		{"10.0.0.1:1234", []string{"1.1.1.1", "198.51.100.1, 10.0.0.2"}, "198.51.100.1"},
+		{"10.0.0.1:1234", []string{"10.0.0.3, 10.0.0.2"}, "10.0.0.3"},
+		{"[2001:db8::1]:1234", []string{"198.51.100.1"}, "2001:db8::1"},
+	}
+	for _, testCase := range testCases {
+		req := httptest.NewRequest(http.MethodGet, "/v2/objects", nil)
+		req.RemoteAddr = testCase.remoteAddr
+		for _, forwardedFor := range testCase.forwardedFor {
+			req.Header.Add("X-Forwarded-For", forwardedFor)
+		}
+
+		if clientIp := rateLimitClientIp(req, trustedProxies); clientIp != testCase.expected {
+			t.Errorf("expected client IP of request from %s forwarded for %v to be %s, got %s", testCase.remoteAddr, testCase.forwardedFor, testCase.expected, clientIp)
+		}
+	}
+
+	if _, err := parseTrustedProxies([]string{"not-an-ip"}); err == nil {
+		t.Fatalf("expected error parsing invalid trusted proxy")
+	}
+}
@@ -15,9 +15,11 @@
 package service
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"os"
+	"strconv"
 	"strings"
 	"time"
 
@@ -26,8 +28,14 @@ import (
 	"github.com/rs/zerolog/hlog"
 	"github.com/rs/zerolog/log"
 	"github.com/warrant-dev/warrant/pkg/config"
+	"github.com/warrant-dev/warrant/pkg/metrics"
 	"github.com/warrant-dev/warrant/pkg/stats"
+	"github.com/warrant-dev/warrant/pkg/tracing"
 	"github.com/warrant-dev/warrant/pkg/wookie"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
 )
 
 type RouteHandler[T Service] struct {
@@ -74,12 +82,15 @@ func NewRouter(config config.Config, pathPrefix string, routes []Route, authMidd
 		logger = log.Output(zerolog.ConsoleWriter{Out: os.Stderr})
 	}
 	router.Use(hlog.NewHandler(logger))
+	router.Use(requestTracingMiddleware)
 	router.Use(stats.RequestStatsMiddleware)
+	router.Use(requestMetricsMiddleware)
 	router.Use(wookie.WarrantTokenMiddleware)
 	if config.GetEnableAccessLog() {
 		router.Use(accessLogMiddleware)
 	}
 	router.Use(hlog.RequestIDHandler("requestId", "Warrant-Request-Id"))
+	router.Use(requestInfoMiddleware)
 	router.Use(hlog.URLHandler("uri"))
 	router.Use(hlog.MethodHandler("method"))
 	router.Use(hlog.ProtoHandler("protocol"))
@@ -104,6 +115,7 @@ func NewRouter(config config.Config, pathPrefix string, routes []Route, authMidd
 		if err != nil {
 			return nil, err
 		}
+		middlewareWrappedHandler = routePatternMiddleware(route.GetPattern(), middlewareWrappedHandler)
 
 		router.Handle(routePattern, middlewareWrappedHandler).Methods(route.GetMethod())
 	}
@@ -140,6 +152,97 @@ func accessLogMiddleware(next http.Handler) http.Handler {
 	})(next)
 }
 
+// Records the latency and status of each request by route pattern.
+func requestMetricsMiddleware(next http.Handler) http.Handler {
+	return hlog.AccessHandler(func(r *http.Request, status, size int, duration time.Duration) {
+		route := ""
+		if currentRoute := mux.CurrentRoute(r); currentRoute != nil {
+			route, _ = currentRoute.GetPathTemplate()
+		}
+
+		metrics.ObserveHTTPRequest(route, r.Method, strconv.Itoa(status), duration)
+	})(next)
+}
+
+// Traces each request as a span named by its route pattern, continuing the
+// trace in the request's traceparent header if present.
+func requestTracingMiddleware(next http.Handler) http.Handler {
+	endSpan := hlog.AccessHandler(func(r *http.Request, status, size int, duration time.Duration) {
+		span := trace.SpanFromContext(r.Context())
+		span.SetAttributes(attribute.Int("http.response.status_code", status))
+		if status >= http.StatusInternalServerError {
+			span.SetStatus(codes.Error, http.StatusText(status))
+		}
+		span.End()
+	})(next)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		route := r.URL.Path
+		if currentRoute := mux.CurrentRoute(r); currentRoute != nil {
+			if pathTemplate, err := currentRoute.GetPathTemplate(); err == nil {
+				route = pathTemplate
+			}
+		}
+
+		ctx := tracing.Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+		ctx, _ = tracing.StartSpan(ctx, fmt.Sprintf("%s %s", r.Method, route),
+			attribute.String("http.request.method", r.Method),
+			attribute.String("http.route", route),
+			attribute.String("url.path", r.URL.Path),
+		)
+		endSpan.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+type requestInfoKey struct{}
+
+// RequestInfo describes the request a context was created for.
+type RequestInfo struct {
+	RequestId string
+	ClientIp  string
+	Method    string
+	Path      string
+}
+
+func requestInfoMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestInfo := RequestInfo{
+			ClientIp: GetClientIpAddress(r),
+			Method:   r.Method,
+			Path:     r.URL.Path,
+		}
+		if requestId, ok := hlog.IDFromRequest(r); ok {
+			requestInfo.RequestId = requestId.String()
+		}
+
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), requestInfoKey{}, requestInfo)))
+	})
+}
+
+// GetRequestInfoFromContext returns the RequestInfo of the request ctx was
+// created for. It returns false for contexts not created for a request.
+func GetRequestInfoFromContext(ctx context.Context) (RequestInfo, bool) {
+	requestInfo, ok := ctx.Value(requestInfoKey{}).(RequestInfo)
+	return requestInfo, ok
+}
+
+type routePatternKey struct{}
+
+// Adds the pattern of the route handling the request, without the router's
+// path prefix, to the request context.
+func routePatternMiddleware(routePattern string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), routePatternKey{}, routePattern)))
+	})
+}
+
+// Returns the pattern (e.g. /v2/objects/{objectType}) of the route handling
+// the request ctx was created for, without the router's path prefix.
+func getRoutePatternFromContext(ctx context.Context) (string, bool) {
+	routePattern, ok := ctx.Value(routePatternKey{}).(string)
+	return routePattern, ok
+}
+
 func GetClientIpAddress(r *http.Request) string {
 	clientIpAddress := r.Header.Get("X-Forwarded-For")
 	if clientIpAddress == "" {
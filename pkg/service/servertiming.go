@@ -0,0 +1,117 @@
+// Copyright 2024 WorkOS, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/warrant-dev/warrant/pkg/config"
+	"github.com/warrant-dev/warrant/pkg/stats"
+)
+
+const HeaderServerTiming = "Warrant-Server-Timing"
+
+// ServerTimingMiddleware adds a Server-Timing header to responses summarizing
+// the time spent on database queries, the number of queries and check
+// subtasks run, and the total time taken to process the request. The header is
+// added to all responses if enabled in cfg, otherwise only to responses to
+// requests with a Warrant-Server-Timing: true header if cfg allows it.
+func ServerTimingMiddleware(cfg *config.ServerTimingConfig) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			reqStats := stats.GetRequestStatsFromContext(r.Context())
+			if reqStats == nil || !serverTimingRequested(cfg, r) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			next.ServeHTTP(&serverTimingResponseWriter{
+				ResponseWriter: w,
+				reqStats:       reqStats,
+				start:          time.Now(),
+			}, r)
+		})
+	}
+}
+
+func serverTimingRequested(cfg *config.ServerTimingConfig, r *http.Request) bool {
+	if cfg.Enabled {
+		return true
+	}
+	if !cfg.AllowRequestHeader {
+		return false
+	}
+
+	requested, err := strconv.ParseBool(r.Header.Get(HeaderServerTiming))
+	return err == nil && requested
+}
+
+// Adds the Server-Timing header just before the response headers are written,
+// once the request has been processed.
+type serverTimingResponseWriter struct {
+	http.ResponseWriter
+	reqStats    *stats.RequestStats
+	start       time.Time
+	wroteHeader bool
+}
+
+func (w *serverTimingResponseWriter) WriteHeader(statusCode int) {
+	if !w.wroteHeader {
+		w.wroteHeader = true
+		w.Header().Set("Server-Timing", formatServerTiming(w.reqStats.Summary(), time.Since(w.start)))
+	}
+	w.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (w *serverTimingResponseWriter) Write(b []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+// Streamed responses (e.g. server-sent events) need to be flushed.
+func (w *serverTimingResponseWriter) Flush() {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+func (w *serverTimingResponseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+func formatServerTiming(summary stats.Summary, total time.Duration) string {
+	metrics := []string{
+		fmt.Sprintf("db;dur=%s;desc=\"%d queries\"", formatServerTimingDuration(summary.QueryDuration), summary.NumQueries),
+	}
+	if summary.NumCheckSubtasks > 0 {
+		metrics = append(metrics, fmt.Sprintf("check;desc=\"%d subtasks\"", summary.NumCheckSubtasks))
+	}
+	metrics = append(metrics, fmt.Sprintf("total;dur=%s", formatServerTimingDuration(total)))
+	return strings.Join(metrics, ", ")
+}
+
+// Server-Timing durations are in milliseconds.
+func formatServerTimingDuration(d time.Duration) string {
+	return strconv.FormatFloat(float64(d.Microseconds())/1000, 'f', 3, 64)
+}
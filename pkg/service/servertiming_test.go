@@ -0,0 +1,70 @@
+// Copyright 2024 WorkOS, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/warrant-dev/warrant/pkg/config"
+	"github.com/warrant-dev/warrant/pkg/stats"
+)
+
+func serveWithServerTiming(cfg *config.ServerTimingConfig, req *http.Request) *httptest.ResponseRecorder {
+	handler := stats.RequestStatsMiddleware(ServerTimingMiddleware(cfg)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		stats.RecordQueryStat(r.Context(), "localhost/warrant", "sql.reader.GetContext", time.Now().Add(-2*time.Millisecond))
+		stats.RecordQueryStat(r.Context(), "localhost/warrant", "sql.reader.SelectContext", time.Now().Add(-time.Millisecond))
+		stats.RecordStat(r.Context(), "checkCache", "result.miss", time.Now())
+		stats.RecordCheckSubtask(r.Context())
+		SendJSONResponse(w, map[string]string{})
+	})))
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, req)
+	return recorder
+}
+
+func TestServerTimingMiddlewareSummarizesRequestStats(t *testing.T) {
+	t.Parallel()
+	req := httptest.NewRequest(http.MethodGet, "/v2/check", nil)
+	recorder := serveWithServerTiming(&config.ServerTimingConfig{Enabled: true}, req)
+
+	serverTiming := recorder.Header().Get("Server-Timing")
+	expected := regexp.MustCompile(`^db;dur=(\d+\.\d{3});desc="2 queries", check;desc="1 subtasks", total;dur=\d+\.\d{3}$`)
+	if !expected.MatchString(serverTiming) {
+		t.Fatalf("unexpected Server-Timing header %q", serverTiming)
+	}
+}
+
+func TestServerTimingMiddlewareRequiresOptIn(t *testing.T) {
+	t.Parallel()
+	cfg := &config.ServerTimingConfig{AllowRequestHeader: true}
+	req := httptest.NewRequest(http.MethodGet, "/v2/check", nil)
+	if serverTiming := serveWithServerTiming(cfg, req).Header().Get("Server-Timing"); serverTiming != "" {
+		t.Fatalf("expected no Server-Timing header, got %q", serverTiming)
+	}
+
+	req.Header.Set(HeaderServerTiming, "true")
+	if serverTiming := serveWithServerTiming(cfg, req).Header().Get("Server-Timing"); serverTiming == "" {
+		t.Fatalf("expected Server-Timing header")
+	}
+
+	cfg.AllowRequestHeader = false
+	if serverTiming := serveWithServerTiming(cfg, req).Header().Get("Server-Timing"); serverTiming != "" {
+		t.Fatalf("expected no Server-Timing header, got %q", serverTiming)
+	}
+}
@@ -14,7 +14,11 @@
 
 package service
 
-import "github.com/warrant-dev/warrant/pkg/database"
+import (
+	"context"
+
+	"github.com/warrant-dev/warrant/pkg/database"
+)
 
 type Env interface {
 	DB() database.Database
@@ -25,14 +29,57 @@ type Service interface {
 	Env() Env
 }
 
+// WriteListener is called after a service commits a write affecting the given
+// object types. No object types means any object type may have been affected.
+type WriteListener func(ctx context.Context, objectTypes ...string)
+
+// ChangeRecorder records a change made by a service along with the state of the
+// changed resource before and after the change (nil if it didn't exist). It is
+// called within the transaction making the change so that only committed
+// changes are recorded.
+type ChangeRecorder interface {
+	RecordChange(ctx context.Context, changeType string, before interface{}, after interface{}) error
+}
+
 type BaseService struct {
-	env Env
+	env             Env
+	writeListeners  []WriteListener
+	changeRecorders []ChangeRecorder
 }
 
 func (svc BaseService) Env() Env {
 	return svc.env
 }
 
+// AddWriteListener registers a WriteListener to be notified of writes made by the service.
+func (svc *BaseService) AddWriteListener(listener WriteListener) {
+	svc.writeListeners = append(svc.writeListeners, listener)
+}
+
+// NotifyWrite calls each registered WriteListener with the given object types.
+func (svc BaseService) NotifyWrite(ctx context.Context, objectTypes ...string) {
+	for _, listener := range svc.writeListeners {
+		listener(ctx, objectTypes...)
+	}
+}
+
+// AddChangeRecorder registers a ChangeRecorder to record changes made by the service.
+func (svc *BaseService) AddChangeRecorder(recorder ChangeRecorder) {
+	svc.changeRecorders = append(svc.changeRecorders, recorder)
+}
+
+// RecordChange records a change with each registered ChangeRecorder.
+func (svc BaseService) RecordChange(ctx context.Context, changeType string, before interface{}, after interface{}) error {
+	for _, recorder := range svc.changeRecorders {
+		err := recorder.RecordChange(ctx, changeType, before, after)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 func NewBaseService(env Env) BaseService {
 	return BaseService{
 		env: env,
@@ -0,0 +1,70 @@
+// Copyright 2024 WorkOS, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"fmt"
+	"time"
+
+	jwt "github.com/golang-jwt/jwt/v5"
+	"github.com/warrant-dev/warrant/pkg/config"
+)
+
+const (
+	SessionTokenIssuer        = "warrant"
+	SessionTokenTenantIdClaim = "tenantId"
+)
+
+// Session signing keys shorter than this are rejected.
+const minSessionSigningKeyLength = 32
+
+// NewSessionToken returns a session token for the given user, signed with
+// signingKey. Session tokens can only be used to make checks as that user.
+func NewSessionToken(signingKey string, userId string, tenantId string, expiresAt time.Time) (string, error) {
+	claims := jwt.MapClaims{
+		"iss": SessionTokenIssuer,
+		"sub": userId,
+		"iat": time.Now().Unix(),
+		"exp": expiresAt.Unix(),
+	}
+	if tenantId != "" {
+		claims[SessionTokenTenantIdClaim] = tenantId
+	}
+
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(signingKey))
+	if err != nil {
+		return "", fmt.Errorf("error signing session token: %w", err)
+	}
+
+	return token, nil
+}
+
+func newSessionTokenVerifier(sessionsConfig *config.SessionsConfig) (*tokenVerifier, error) {
+	if len(sessionsConfig.SigningKey) < minSessionSigningKeyLength {
+		return nil, fmt.Errorf("authentication.sessions.signingKey must be at least %d characters", minSessionSigningKeyLength)
+	}
+
+	return &tokenVerifier{
+		provider: config.AuthProviderConfig{
+			Name:          SessionTokenIssuer,
+			Issuer:        SessionTokenIssuer,
+			UserIdClaim:   config.DefaultAuthenticationUserIdClaim,
+			TenantIdClaim: SessionTokenTenantIdClaim,
+		},
+		key:              []byte(sessionsConfig.SigningKey),
+		signingMethods:   []string{jwt.SigningMethodHS256.Alg()},
+		tenantIdOptional: true,
+	}, nil
+}
@@ -0,0 +1,220 @@
+// Copyright 2024 WorkOS, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+
+	jwt "github.com/golang-jwt/jwt/v5"
+	"github.com/rs/zerolog/hlog"
+	"github.com/warrant-dev/warrant/pkg/config"
+)
+
+const FirebaseJwksUrl = "https://www.googleapis.com/service_accounts/v1/jwk/securetoken@system.gserviceaccount.com"
+
+var validSigningMethods = []string{"RS256", "RS384", "RS512", "PS256", "PS384", "PS512", "ES256", "ES384", "ES512", "EdDSA"}
+
+// Verifiers are shared by all routes, so each JWKS is only fetched and refreshed once.
+var (
+	tokenVerifiersMutex    sync.Mutex
+	tokenVerifiersByConfig = make(map[*config.AuthConfig][]*tokenVerifier)
+)
+
+// Verifies tokens issued by a single auth provider, using either a static
+// key or the keys served by the provider's JWKS endpoint.
+type tokenVerifier struct {
+	provider         config.AuthProviderConfig
+	key              interface{}
+	keySet           *jwksKeySet
+	signingMethods   []string
+	tenantIdOptional bool
+}
+
+func newTokenVerifier(provider config.AuthProviderConfig) (*tokenVerifier, error) {
+	if provider.UserIdClaim == "" {
+		provider.UserIdClaim = config.DefaultAuthenticationUserIdClaim
+	}
+	if provider.JwksCacheTtl <= 0 {
+		provider.JwksCacheTtl = config.DefaultAuthenticationJwksCacheTtl
+	}
+	if provider.JwksUrl == "" && provider.PublicKey == "" && provider.Name == "firebase" {
+		provider.JwksUrl = FirebaseJwksUrl
+	}
+
+	verifier := &tokenVerifier{
+		provider:       provider,
+		signingMethods: validSigningMethods,
+	}
+	if provider.JwksUrl != "" {
+		verifier.keySet = newJwksKeySet(provider.JwksUrl, provider.JwksCacheTtl)
+		go verifier.keySet.start(context.Background())
+		return verifier, nil
+	}
+
+	publicKey, err := parsePublicKeyFromPEM([]byte(provider.PublicKey))
+	if err != nil {
+		return nil, fmt.Errorf("invalid public key for authentication provider %s: %w", provider.Name, err)
+	}
+
+	verifier.key = publicKey
+	return verifier, nil
+}
+
+// Returns the token's claims if it's signed by the provider and has the
+// provider's issuer and audience.
+func (verifier *tokenVerifier) verify(ctx context.Context, tokenString string) (jwt.MapClaims, error) {
+	parserOptions := []jwt.ParserOption{jwt.WithValidMethods(verifier.signingMethods)}
+	if verifier.provider.Issuer != "" {
+		parserOptions = append(parserOptions, jwt.WithIssuer(verifier.provider.Issuer))
+	}
+	if verifier.provider.Audience != "" {
+		parserOptions = append(parserOptions, jwt.WithAudience(verifier.provider.Audience))
+	}
+
+	claims := jwt.MapClaims{}
+	_, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
+		if verifier.keySet == nil {
+			return verifier.key, nil
+		}
+
+		kid, _ := token.Header["kid"].(string)
+		return verifier.keySet.getKey(ctx, kid)
+	}, parserOptions...)
+	if err != nil {
+		return nil, err
+	}
+
+	return claims, nil
+}
+
+// Returns the AuthInfo of the user the token was issued to.
+func (verifier *tokenVerifier) authInfo(claims jwt.MapClaims) (*AuthInfo, error) {
+	userId, ok := claims[verifier.provider.UserIdClaim].(string)
+	if !ok || userId == "" {
+		return nil, fmt.Errorf("unable to retrieve user id from token with given identifier: %s", verifier.provider.UserIdClaim)
+	}
+
+	authInfo := &AuthInfo{
+		UserId: userId,
+	}
+	if verifier.provider.TenantIdClaim != "" {
+		tenantId, ok := claims[verifier.provider.TenantIdClaim].(string)
+		if !ok && !verifier.tenantIdOptional {
+			return nil, fmt.Errorf("unable to retrieve tenant id from token with given identifier: %s", verifier.provider.TenantIdClaim)
+		}
+
+		authInfo.TenantId = tenantId
+	}
+
+	return authInfo, nil
+}
+
+// Returns the verifiers for the providers in authConfig, creating them on first use.
+func getTokenVerifiers(authConfig *config.AuthConfig) ([]*tokenVerifier, error) {
+	tokenVerifiersMutex.Lock()
+	defer tokenVerifiersMutex.Unlock()
+	if verifiers, ok := tokenVerifiersByConfig[authConfig]; ok {
+		return verifiers, nil
+	}
+
+	verifiers := make([]*tokenVerifier, 0)
+	for _, provider := range authConfig.GetProviders() {
+		verifier, err := newTokenVerifier(provider)
+		if err != nil {
+			return nil, err
+		}
+
+		verifiers = append(verifiers, verifier)
+	}
+
+	if authConfig.Sessions != nil && authConfig.Sessions.SigningKey != "" {
+		verifier, err := newSessionTokenVerifier(authConfig.Sessions)
+		if err != nil {
+			return nil, err
+		}
+
+		verifiers = append(verifiers, verifier)
+	}
+
+	tokenVerifiersByConfig[authConfig] = verifiers
+	return verifiers, nil
+}
+
+// Verifies the token with the providers that could have issued it. Providers
+// configured with an issuer are only tried for tokens with that issuer.
+func authenticateToken(r *http.Request, verifiers []*tokenVerifier, tokenString string) (*AuthInfo, error) {
+	unverifiedClaims := jwt.MapClaims{}
+	_, _, err := jwt.NewParser().ParseUnverified(tokenString, unverifiedClaims)
+	if err != nil {
+		return nil, NewUnauthorizedError("Invalid token")
+	}
+
+	issuer, _ := unverifiedClaims.GetIssuer()
+	candidates := make([]*tokenVerifier, 0, len(verifiers))
+	for _, verifier := range verifiers {
+		if verifier.provider.Issuer == issuer {
+			candidates = append(candidates, verifier)
+		}
+	}
+	if len(candidates) == 0 {
+		for _, verifier := range verifiers {
+			if verifier.provider.Issuer == "" {
+				candidates = append(candidates, verifier)
+			}
+		}
+	}
+
+	var verifyErr error
+	for _, verifier := range candidates {
+		claims, err := verifier.verify(r.Context(), tokenString)
+		if err != nil {
+			verifyErr = err
+			continue
+		}
+
+		authInfo, err := verifier.authInfo(claims)
+		if err != nil {
+			hlog.FromRequest(r).Warn().Err(err).Msg("auth: invalid token claims")
+			return nil, NewUnauthorizedError("Invalid token")
+		}
+
+		return authInfo, nil
+	}
+
+	switch {
+	case errors.Is(verifyErr, jwt.ErrTokenExpired):
+		return nil, NewTokenExpiredError()
+	case errors.Is(verifyErr, errJwksUnavailable):
+		hlog.FromRequest(r).Err(verifyErr).Msg("auth: error fetching keys to validate token")
+		return nil, NewInternalError("Error validating token")
+	default:
+		return nil, NewUnauthorizedError("Invalid token")
+	}
+}
+
+func parsePublicKeyFromPEM(key []byte) (interface{}, error) {
+	if rsaKey, err := jwt.ParseRSAPublicKeyFromPEM(key); err == nil {
+		return rsaKey, nil
+	}
+	if ecdsaKey, err := jwt.ParseECPublicKeyFromPEM(key); err == nil {
+		return ecdsaKey, nil
+	}
+
+	return jwt.ParseEdPublicKeyFromPEM(key)
+}
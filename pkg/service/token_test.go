@@ -0,0 +1,272 @@
+// Copyright 2024 WorkOS, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	jwt "github.com/golang-jwt/jwt/v5"
+	"github.com/warrant-dev/warrant/pkg/config"
+)
+
+type testJwksServer struct {
+	mutex sync.Mutex
+	keys  []jsonWebKey
+}
+
+func (s *testJwksServer) setKeys(keys ...jsonWebKey) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.keys = keys
+}
+
+func (s *testJwksServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	_ = json.NewEncoder(w).Encode(jsonWebKeySet{Keys: s.keys})
+}
+
+func ecdsaJwk(t *testing.T, kid string) (*ecdsa.PrivateKey, jsonWebKey) {
+	t.Helper()
+	privateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return privateKey, jsonWebKey{
+		Kty: "EC",
+		Kid: kid,
+		Crv: "P-256",
+		X:   base64.RawURLEncoding.EncodeToString(privateKey.X.FillBytes(make([]byte, 32))),
+		Y:   base64.RawURLEncoding.EncodeToString(privateKey.Y.FillBytes(make([]byte, 32))),
+	}
+}
+
+func ed25519Jwk(t *testing.T, kid string) (ed25519.PrivateKey, jsonWebKey) {
+	t.Helper()
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return privateKey, jsonWebKey{
+		Kty: "OKP",
+		Kid: kid,
+		Crv: "Ed25519",
+		X:   base64.RawURLEncoding.EncodeToString(publicKey),
+	}
+}
+
+func signToken(t *testing.T, method jwt.SigningMethod, kid string, key interface{}, claims jwt.MapClaims) string {
+	t.Helper()
+	token := jwt.NewWithClaims(method, claims)
+	token.Header["kid"] = kid
+	tokenString, err := token.SignedString(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return tokenString
+}
+
+func TestAuthenticateTokenWithJwks(t *testing.T) {
+	t.Parallel()
+	ecdsaKey, ecdsaKeyJwk := ecdsaJwk(t, "key-1")
+	ed25519Key, ed25519KeyJwk := ed25519Jwk(t, "key-2")
+	jwksServer := &testJwksServer{}
+	jwksServer.setKeys(ecdsaKeyJwk)
+	server := httptest.NewServer(jwksServer)
+	defer server.Close()
+
+	verifier, err := newTokenVerifier(config.AuthProviderConfig{
+		Name:          "oidc",
+		JwksUrl:       server.URL,
+		Issuer:        "https://issuer.example.com",
+		Audience:      "warrant",
+		TenantIdClaim: "org",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	verifiers := []*tokenVerifier{verifier}
+	claims := jwt.MapClaims{
+		"iss": "https://issuer.example.com",
+		"aud": "warrant",
+		"sub": "user-a",
+		"org": "tenant-a",
+		"exp": time.Now().Add(time.Hour).Unix(),
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/v2/check", nil)
+	authInfo, err := authenticateToken(req, verifiers, signToken(t, jwt.SigningMethodES256, "key-1", ecdsaKey, claims))
+	if err != nil {
+		t.Fatalf("expected ES256 token to be valid, got %v", err)
+	}
+	if authInfo.UserId != "user-a" || authInfo.TenantId != "tenant-a" {
+		t.Fatalf("unexpected auth info %+v", authInfo)
+	}
+
+	// A token signed by a key added to the JWKS after it was fetched
+	verifier.keySet.refreshMutex.Lock()
+	verifier.keySet.refreshedAt = time.Time{}
+	verifier.keySet.refreshMutex.Unlock()
+	jwksServer.setKeys(ecdsaKeyJwk, ed25519KeyJwk)
+	_, err = authenticateToken(req, verifiers, signToken(t, jwt.SigningMethodEdDSA, "key-2", ed25519Key, claims))
+	if err != nil {
+		t.Fatalf("expected EdDSA token signed by rotated key to be valid, got %v", err)
+	}
+
+	wrongAudienceClaims := jwt.MapClaims{}
+	for k, v := range claims {
+		wrongAudienceClaims[k] = v
+	}
+	wrongAudienceClaims["aud"] = "other"
+	_, err = authenticateToken(req, verifiers, signToken(t, jwt.SigningMethodES256, "key-1", ecdsaKey, wrongAudienceClaims))
+	var unauthorizedErr *UnauthorizedError
+	if !errors.As(err, &unauthorizedErr) {
+		t.Fatalf("expected token with wrong audience to be unauthorized, got %v", err)
+	}
+
+	expiredClaims := jwt.MapClaims{}
+	for k, v := range claims {
+		expiredClaims[k] = v
+	}
+	expiredClaims["exp"] = time.Now().Add(-time.Hour).Unix()
+	_, err = authenticateToken(req, verifiers, signToken(t, jwt.SigningMethodES256, "key-1", ecdsaKey, expiredClaims))
+	var tokenExpiredErr *TokenExpiredError
+	if !errors.As(err, &tokenExpiredErr) {
+		t.Fatalf("expected expired token to be rejected as expired, got %v", err)
+	}
+
+	forgedKey, _ := ecdsaJwk(t, "key-1")
+	_, err = authenticateToken(req, verifiers, signToken(t, jwt.SigningMethodES256, "key-1", forgedKey, claims))
+	if !errors.As(err, &unauthorizedErr) {
+		t.Fatalf("expected token signed by unknown key to be unauthorized, got %v", err)
+	}
+}
+
+func TestAuthenticateTokenWithMultipleProviders(t *testing.T) {
+	t.Parallel()
+	keyA, jwkA := ecdsaJwk(t, "a")
+	keyB, jwkB := ed25519Jwk(t, "b")
+	jwksServerA := &testJwksServer{}
+	jwksServerA.setKeys(jwkA)
+	serverA := httptest.NewServer(jwksServerA)
+	defer serverA.Close()
+	jwksServerB := &testJwksServer{}
+	jwksServerB.setKeys(jwkB)
+	serverB := httptest.NewServer(jwksServerB)
+	defer serverB.Close()
+
+	verifiers, err := getTokenVerifiers(&config.AuthConfig{
+		Provider: &config.AuthProviderConfig{
+			Name:    "a",
+			JwksUrl: serverA.URL,
+			Issuer:  "https://a.example.com",
+		},
+		AdditionalProviders: []config.AuthProviderConfig{
+			{
+				Name:        "b",
+				JwksUrl:     serverB.URL,
+				Issuer:      "https://b.example.com",
+				UserIdClaim: "uid",
+			},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/v2/check", nil)
+	authInfo, err := authenticateToken(req, verifiers, signToken(t, jwt.SigningMethodES256, "a", keyA, jwt.MapClaims{"iss": "https://a.example.com", "sub": "user-a"}))
+	if err != nil || authInfo.UserId != "user-a" {
+		t.Fatalf("expected token from provider a to be valid, got %+v, %v", authInfo, err)
+	}
+
+	authInfo, err = authenticateToken(req, verifiers, signToken(t, jwt.SigningMethodEdDSA, "b", keyB, jwt.MapClaims{"iss": "https://b.example.com", "uid": "user-b"}))
+	if err != nil || authInfo.UserId != "user-b" {
+		t.Fatalf("expected token from provider b to be valid, got %+v, %v", authInfo, err)
+	}
+
+	// Provider a's key isn't trusted for tokens claiming to be from provider b
+	_, err = authenticateToken(req, verifiers, signToken(t, jwt.SigningMethodES256, "a", keyA, jwt.MapClaims{"iss": "https://b.example.com", "uid": "user-b"}))
+	var unauthorizedErr *UnauthorizedError
+	if !errors.As(err, &unauthorizedErr) {
+		t.Fatalf("expected token with mismatched issuer to be unauthorized, got %v", err)
+	}
+}
+
+func TestAuthenticateSessionToken(t *testing.T) {
+	t.Parallel()
+	signingKey := "0123456789abcdef0123456789abcdef"
+	verifiers, err := getTokenVerifiers(&config.AuthConfig{
+		Sessions: &config.SessionsConfig{
+			SigningKey: signingKey,
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/v2/check", nil)
+	token, err := NewSessionToken(signingKey, "user-a", "", time.Now().Add(time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	authInfo, err := authenticateToken(req, verifiers, token)
+	if err != nil || authInfo.UserId != "user-a" || authInfo.TenantId != "" {
+		t.Fatalf("expected session token to be valid, got %+v, %v", authInfo, err)
+	}
+
+	token, err = NewSessionToken(signingKey, "user-a", "tenant-a", time.Now().Add(time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	authInfo, err = authenticateToken(req, verifiers, token)
+	if err != nil || authInfo.TenantId != "tenant-a" {
+		t.Fatalf("expected session token with tenant to be valid, got %+v, %v", authInfo, err)
+	}
+
+	token, err = NewSessionToken("fedcba9876543210fedcba9876543210", "user-a", "", time.Now().Add(time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = authenticateToken(req, verifiers, token)
+	var unauthorizedErr *UnauthorizedError
+	if !errors.As(err, &unauthorizedErr) {
+		t.Fatalf("expected session token signed with another key to be unauthorized, got %v", err)
+	}
+
+	token, err = NewSessionToken(signingKey, "user-a", "", time.Now().Add(-time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = authenticateToken(req, verifiers, token)
+	var tokenExpiredErr *TokenExpiredError
+	if !errors.As(err, &tokenExpiredErr) {
+		t.Fatalf("expected expired session token to be rejected as expired, got %v", err)
+	}
+}
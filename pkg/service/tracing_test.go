@@ -0,0 +1,112 @@
+// Copyright 2024 WorkOS, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"context"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/warrant-dev/warrant/pkg/config"
+	"github.com/warrant-dev/warrant/pkg/tracing"
+	collectortrace "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	tracepb "go.opentelemetry.io/proto/otlp/trace/v1"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestRequestTracingMiddlewareExportsSpansInIncomingTrace(t *testing.T) {
+	var mutex sync.Mutex
+	var exportedSpans []*tracepb.Span
+	collector := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			t.Errorf("error reading export request: %v", err)
+			return
+		}
+		var exportRequest collectortrace.ExportTraceServiceRequest
+		if err := proto.Unmarshal(body, &exportRequest); err != nil {
+			t.Errorf("error unmarshaling export request: %v", err)
+			return
+		}
+
+		mutex.Lock()
+		defer mutex.Unlock()
+		for _, resourceSpans := range exportRequest.GetResourceSpans() {
+			for _, scopeSpans := range resourceSpans.GetScopeSpans() {
+				exportedSpans = append(exportedSpans, scopeSpans.GetSpans()...)
+			}
+		}
+		w.Header().Set("Content-Type", "application/x-protobuf")
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer collector.Close()
+
+	stopTracing, err := tracing.Start(context.Background(), &config.TracingConfig{
+		Enabled:     true,
+		Endpoint:    collector.URL + "/v1/traces",
+		ServiceName: "warrant",
+		SampleRate:  1,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error starting tracing: %v", err)
+	}
+
+	router := mux.NewRouter()
+	router.Use(requestTracingMiddleware)
+	router.HandleFunc("/v2/objects/{objectType}", func(w http.ResponseWriter, r *http.Request) {
+		_, span := tracing.StartSpan(r.Context(), "handler")
+		span.End()
+		w.WriteHeader(http.StatusOK)
+	})
+
+	traceId := "4bf92f3577b34da6a3ce929d0e0e4736"
+	parentSpanId := "00f067aa0ba902b7"
+	req := httptest.NewRequest(http.MethodGet, "/v2/objects/user", nil)
+	req.Header.Set("traceparent", "00-"+traceId+"-"+parentSpanId+"-01")
+	router.ServeHTTP(httptest.NewRecorder(), req)
+
+	if err := stopTracing(context.Background()); err != nil {
+		t.Fatalf("unexpected error stopping tracing: %v", err)
+	}
+
+	mutex.Lock()
+	defer mutex.Unlock()
+	spansByName := make(map[string]*tracepb.Span)
+	for _, span := range exportedSpans {
+		spansByName[span.GetName()] = span
+	}
+	requestSpan, ok := spansByName["GET /v2/objects/{objectType}"]
+	if !ok {
+		t.Fatalf("expected request span to be exported, got %d spans", len(exportedSpans))
+	}
+	if hex.EncodeToString(requestSpan.GetTraceId()) != traceId {
+		t.Fatalf("expected request span in trace %s, got %s", traceId, hex.EncodeToString(requestSpan.GetTraceId()))
+	}
+	if hex.EncodeToString(requestSpan.GetParentSpanId()) != parentSpanId {
+		t.Fatalf("expected request span to be a child of %s, got %s", parentSpanId, hex.EncodeToString(requestSpan.GetParentSpanId()))
+	}
+	handlerSpan, ok := spansByName["handler"]
+	if !ok {
+		t.Fatalf("expected handler span to be exported")
+	}
+	if string(handlerSpan.GetParentSpanId()) != string(requestSpan.GetSpanId()) {
+		t.Fatalf("expected handler span to be a child of the request span")
+	}
+}
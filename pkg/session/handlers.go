@@ -0,0 +1,48 @@
+// Copyright 2024 WorkOS, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package session
+
+import (
+	"net/http"
+
+	"github.com/warrant-dev/warrant/pkg/service"
+)
+
+func (svc SessionService) Routes() ([]service.Route, error) {
+	return []service.Route{
+		// create
+		service.WarrantRoute{
+			Pattern: "/v2/sessions",
+			Method:  "POST",
+			Handler: service.NewRouteHandler(svc, createHandler),
+		},
+	}, nil
+}
+
+func createHandler(svc SessionService, w http.ResponseWriter, r *http.Request) error {
+	var spec CreateSessionSpec
+	err := service.ParseJSONBody(r.Context(), r.Body, &spec)
+	if err != nil {
+		return err
+	}
+
+	createdSession, err := svc.Create(r.Context(), spec)
+	if err != nil {
+		return err
+	}
+
+	service.SendJSONResponse(w, createdSession)
+	return nil
+}
@@ -0,0 +1,71 @@
+// Copyright 2024 WorkOS, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package session
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/warrant-dev/warrant/pkg/config"
+	"github.com/warrant-dev/warrant/pkg/service"
+)
+
+type SessionService struct {
+	service.BaseService
+	config *config.SessionsConfig
+}
+
+func NewService(env service.Env, cfg *config.SessionsConfig) *SessionService {
+	return &SessionService{
+		BaseService: service.NewBaseService(env),
+		config:      cfg,
+	}
+}
+
+func (svc SessionService) Create(ctx context.Context, spec CreateSessionSpec) (*SessionSpec, error) {
+	if svc.config == nil || svc.config.SigningKey == "" {
+		return nil, service.NewInvalidRequestError("Sessions are not enabled. Set authentication.sessions.signingKey to enable them.")
+	}
+
+	ttl := svc.config.DefaultTtl
+	if spec.TTL > 0 {
+		ttl = time.Duration(spec.TTL) * time.Second
+	}
+	if svc.config.MaxTtl > 0 && ttl > svc.config.MaxTtl {
+		return nil, service.NewInvalidParameterError("ttl", fmt.Sprintf("must be at most %d", int64(svc.config.MaxTtl.Seconds())))
+	}
+
+	expiresAt := time.Now().UTC().Add(ttl).Truncate(time.Second)
+	token, err := service.NewSessionToken(svc.config.SigningKey, spec.UserId, spec.TenantId, expiresAt)
+	if err != nil {
+		return nil, err
+	}
+
+	sessionSpec := SessionSpec{
+		UserId:    spec.UserId,
+		TenantId:  spec.TenantId,
+		ExpiresAt: expiresAt,
+	}
+	// Sessions aren't stored, so this only records that the session was
+	// created. The token itself is left out of the record.
+	err = svc.RecordChange(ctx, ChangeTypeSessionCreated, nil, sessionSpec)
+	if err != nil {
+		return nil, err
+	}
+
+	sessionSpec.Token = token
+	return &sessionSpec, nil
+}
@@ -0,0 +1,33 @@
+// Copyright 2024 WorkOS, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package session
+
+import "time"
+
+const ChangeTypeSessionCreated = "session.created"
+
+type CreateSessionSpec struct {
+	UserId   string `json:"userId"   validate:"required,valid_object_id"`
+	TenantId string `json:"tenantId" validate:"omitempty,valid_object_id"`
+	// The number of seconds until the session expires
+	TTL int64 `json:"ttl" validate:"omitempty,min=1"`
+}
+
+type SessionSpec struct {
+	Token     string    `json:"token,omitempty"`
+	UserId    string    `json:"userId"`
+	TenantId  string    `json:"tenantId,omitempty"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
@@ -21,12 +21,15 @@ import (
 	"time"
 
 	"github.com/rs/zerolog"
+	"github.com/warrant-dev/warrant/pkg/metrics"
 )
 
 type Stat struct {
 	Store    string
 	Tag      string
 	Duration time.Duration
+	// Set if the stat is for a database query
+	IsQuery bool
 }
 
 func (s Stat) MarshalZerologObject(e *zerolog.Event) {
@@ -34,8 +37,16 @@ func (s Stat) MarshalZerologObject(e *zerolog.Event) {
 }
 
 type RequestStats struct {
-	mutex sync.Mutex
-	stats []Stat
+	mutex            sync.Mutex
+	stats            []Stat
+	numCheckSubtasks int
+}
+
+// Totals of the stats recorded for a request.
+type Summary struct {
+	NumQueries       int
+	QueryDuration    time.Duration
+	NumCheckSubtasks int
 }
 
 func (s *RequestStats) RecordStat(stat Stat) {
@@ -51,6 +62,27 @@ func (s *RequestStats) NumStats() int {
 	return numStats
 }
 
+func (s *RequestStats) RecordCheckSubtask() {
+	s.mutex.Lock()
+	s.numCheckSubtasks++
+	s.mutex.Unlock()
+}
+
+func (s *RequestStats) Summary() Summary {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	summary := Summary{
+		NumCheckSubtasks: s.numCheckSubtasks,
+	}
+	for _, stat := range s.stats {
+		if stat.IsQuery {
+			summary.NumQueries++
+			summary.QueryDuration += stat.Duration
+		}
+	}
+	return summary
+}
+
 func (s *RequestStats) MarshalZerologObject(e *zerolog.Event) {
 	arr := zerolog.Arr()
 	s.mutex.Lock()
@@ -92,8 +124,19 @@ func BlankContextWithRequestStats(parent context.Context) context.Context {
 	return context.Background()
 }
 
-// Append a new Stat to the RequestStats obj in provided context, if present.
+// Append a new Stat to the RequestStats obj in provided context, if present. The stat is also exported as a metric.
 func RecordStat(ctx context.Context, store string, tag string, start time.Time) {
+	recordStat(ctx, store, tag, start, false)
+}
+
+// RecordStat for a database query.
+func RecordQueryStat(ctx context.Context, store string, tag string, start time.Time) {
+	recordStat(ctx, store, tag, start, true)
+}
+
+func recordStat(ctx context.Context, store string, tag string, start time.Time, isQuery bool) {
+	duration := time.Since(start)
+	metrics.ObserveStoreQuery(store, tag, duration)
 	if reqStats, ok := ctx.Value(requestStatsKey{}).(*RequestStats); ok {
 		if tagPrefix, ctxHasTag := ctx.Value(statTagKey{}).(string); ctxHasTag {
 			tag = tagPrefix + "." + tag
@@ -101,11 +144,19 @@ func RecordStat(ctx context.Context, store string, tag string, start time.Time)
 		reqStats.RecordStat(Stat{
 			Store:    store,
 			Tag:      tag,
-			Duration: time.Since(start),
+			Duration: duration,
+			IsQuery:  isQuery,
 		})
 	}
 }
 
+// Count a check subtask in the RequestStats obj in provided context, if present.
+func RecordCheckSubtask(ctx context.Context) {
+	if reqStats, ok := ctx.Value(requestStatsKey{}).(*RequestStats); ok {
+		reqStats.RecordCheckSubtask()
+	}
+}
+
 // Returns a new context with given crumb appended to existing tag, if present. Otherwise, tracks the new tag in returned context. Useful for adding breadcrumbs to a Stat prior to a recording it.
 func ContextWithTagCrumb(ctx context.Context, crumb string) context.Context {
 	if tag, ok := ctx.Value(statTagKey{}).(string); ok {
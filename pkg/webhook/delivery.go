@@ -0,0 +1,160 @@
+// Copyright 2024 WorkOS, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	HeaderEventId   = "Warrant-Event-Id"
+	HeaderEventType = "Warrant-Event-Type"
+	HeaderSignature = "Warrant-Signature"
+)
+
+// Sign returns the signature of a payload sent to a webhook at the given unix
+// timestamp: the hex encoded HMAC-SHA256 of "<timestamp>.<payload>" keyed by
+// the webhook's secret.
+func Sign(secret string, timestamp int64, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// DeliverDue attempts up to batchSize deliveries that are due and returns the
+// number attempted. Deliveries are attempted at least once, so endpoints may
+// receive the same event more than once and should dedupe by event id.
+func (svc WebhookService) DeliverDue(ctx context.Context) (int, error) {
+	now := time.Now().UTC()
+	deliveries, err := svc.repository.ListDueDeliveries(ctx, now, svc.config.BatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	var wg sync.WaitGroup
+	for _, delivery := range deliveries {
+		// Push back the next attempt while this one is in flight so that
+		// other instances don't pick up the same delivery
+		claimed, err := svc.repository.ClaimDelivery(ctx, delivery.ID, delivery.Attempts, now.Add(2*svc.config.Timeout))
+		if err != nil {
+			return 0, err
+		}
+
+		if !claimed {
+			continue
+		}
+
+		delivery.Attempts++
+		wg.Add(1)
+		go func(delivery Delivery) {
+			defer wg.Done()
+			err := svc.completeDelivery(ctx, delivery, svc.send(ctx, delivery))
+			if err != nil {
+				log.Error().Err(err).Msgf("webhooks: error completing delivery %d", delivery.ID)
+			}
+		}(delivery)
+	}
+
+	wg.Wait()
+	return len(deliveries), nil
+}
+
+func (svc WebhookService) send(ctx context.Context, delivery Delivery) error {
+	ctx, cancel := context.WithTimeout(ctx, svc.config.Timeout)
+	defer cancel()
+
+	payload := []byte(delivery.Payload)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, delivery.URL, bytes.NewReader(payload))
+	if err != nil {
+		return errors.Wrap(err, "error creating request")
+	}
+
+	timestamp := time.Now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderEventId, strconv.FormatInt(delivery.ChangeId, 10))
+	req.Header.Set(HeaderEventType, delivery.Type)
+	req.Header.Set(HeaderSignature, fmt.Sprintf("t=%d,v1=%s", timestamp, Sign(delivery.Secret, timestamp, payload)))
+	resp, err := svc.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return errors.New(fmt.Sprintf("endpoint responded with status code %d", resp.StatusCode))
+	}
+
+	return nil
+}
+
+// Removes a successful delivery. A failed delivery is retried with backoff
+// until it has been attempted maxAttempts times, then moved to dead letters.
+func (svc WebhookService) completeDelivery(ctx context.Context, delivery Delivery, deliveryErr error) error {
+	if deliveryErr == nil {
+		return svc.repository.DeleteDelivery(ctx, delivery.ID)
+	}
+
+	lastError := deliveryErr.Error()
+	if delivery.Attempts < svc.config.MaxAttempts {
+		nextAttemptAt := time.Now().UTC().Add(backoff(delivery.Attempts, svc.config.InitialBackoff, svc.config.MaxBackoff))
+		return svc.repository.RescheduleDelivery(ctx, delivery.ID, nextAttemptAt, lastError)
+	}
+
+	return svc.Env().DB().WithinTransaction(ctx, func(txCtx context.Context) error {
+		_, err := svc.repository.CreateDeadLetter(txCtx, DeadLetter{
+			WebhookId: delivery.WebhookId,
+			ChangeId:  delivery.ChangeId,
+			Type:      delivery.Type,
+			Payload:   delivery.Payload,
+			Attempts:  delivery.Attempts,
+			LastError: &lastError,
+		})
+		if err != nil {
+			return err
+		}
+
+		return svc.repository.DeleteDelivery(txCtx, delivery.ID)
+	})
+}
+
+// Returns how long to wait before retrying a delivery that has failed the
+// given number of attempts. The wait doubles after each attempt up to maxBackoff.
+func backoff(attempts int, initialBackoff time.Duration, maxBackoff time.Duration) time.Duration {
+	wait := initialBackoff
+	for i := 1; i < attempts && wait < maxBackoff; i++ {
+		wait *= 2
+	}
+
+	if wait > maxBackoff {
+		return maxBackoff
+	}
+	return wait
+}
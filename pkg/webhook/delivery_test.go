@@ -0,0 +1,51 @@
+// Copyright 2024 WorkOS, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webhook
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSign(t *testing.T) {
+	t.Parallel()
+	// Expected value computed with: printf '1700000000.{"type":"warrant.created"}' | openssl dgst -sha256 -hmac whsec_test
+	expected := "16791c52ae58377479287ccc835edbb715c34af144fedd5a3c79a08defaf6f78"
+	actual := Sign("whsec_test", 1700000000, []byte(`{"type":"warrant.created"}`))
+	if actual != expected {
+		t.Fatalf("expected signature %s, got %s", expected, actual)
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		attempts int
+		expected time.Duration
+	}{
+		{1, time.Second},
+		{2, 2 * time.Second},
+		{3, 4 * time.Second},
+		{10, 512 * time.Second},
+		{13, time.Hour},
+		{100, time.Hour},
+	}
+	for _, test := range tests {
+		actual := backoff(test.attempts, time.Second, time.Hour)
+		if actual != test.expected {
+			t.Fatalf("expected backoff after %d attempts to be %s, got %s", test.attempts, test.expected, actual)
+		}
+	}
+}
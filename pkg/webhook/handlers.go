@@ -0,0 +1,137 @@
+// Copyright 2024 WorkOS, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webhook
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/warrant-dev/warrant/pkg/service"
+)
+
+func (svc WebhookService) Routes() ([]service.Route, error) {
+	return []service.Route{
+		// create
+		service.WarrantRoute{
+			Pattern: "/v2/webhooks",
+			Method:  "POST",
+			Handler: service.NewRouteHandler(svc, createHandler),
+		},
+
+		// list
+		service.WarrantRoute{
+			Pattern: "/v2/webhooks",
+			Method:  "GET",
+			Handler: service.NewRouteHandler(svc, listHandler),
+		},
+
+		// get
+		service.WarrantRoute{
+			Pattern: "/v2/webhooks/{webhookId}",
+			Method:  "GET",
+			Handler: service.NewRouteHandler(svc, getHandler),
+		},
+
+		// delete
+		service.WarrantRoute{
+			Pattern: "/v2/webhooks/{webhookId}",
+			Method:  "DELETE",
+			Handler: service.NewRouteHandler(svc, deleteHandler),
+		},
+
+		// dead letters
+		service.WarrantRoute{
+			Pattern: "/v2/webhooks/{webhookId}/dead-letters",
+			Method:  "GET",
+			Handler: service.NewRouteHandler(svc, listDeadLettersHandler),
+		},
+		service.WarrantRoute{
+			Pattern: "/v2/webhooks/{webhookId}/replay",
+			Method:  "POST",
+			Handler: service.NewRouteHandler(svc, replayHandler),
+		},
+	}, nil
+}
+
+func createHandler(svc WebhookService, w http.ResponseWriter, r *http.Request) error {
+	var spec CreateWebhookSpec
+	err := service.ParseJSONBody(r.Context(), r.Body, &spec)
+	if err != nil {
+		return err
+	}
+
+	createdWebhook, err := svc.Create(r.Context(), spec)
+	if err != nil {
+		return err
+	}
+
+	service.SendJSONResponse(w, createdWebhook)
+	return nil
+}
+
+func listHandler(svc WebhookService, w http.ResponseWriter, r *http.Request) error {
+	webhooks, err := svc.List(r.Context())
+	if err != nil {
+		return err
+	}
+
+	service.SendJSONResponse(w, webhooks)
+	return nil
+}
+
+func getHandler(svc WebhookService, w http.ResponseWriter, r *http.Request) error {
+	webhookId := mux.Vars(r)["webhookId"]
+	webhook, err := svc.GetByWebhookId(r.Context(), webhookId)
+	if err != nil {
+		return err
+	}
+
+	service.SendJSONResponse(w, webhook)
+	return nil
+}
+
+func deleteHandler(svc WebhookService, w http.ResponseWriter, r *http.Request) error {
+	webhookId := mux.Vars(r)["webhookId"]
+	err := svc.DeleteByWebhookId(r.Context(), webhookId)
+	if err != nil {
+		return err
+	}
+
+	w.Header().Set("Content-type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	return nil
+}
+
+func listDeadLettersHandler(svc WebhookService, w http.ResponseWriter, r *http.Request) error {
+	webhookId := mux.Vars(r)["webhookId"]
+	deadLetters, err := svc.ListDeadLetters(r.Context(), webhookId)
+	if err != nil {
+		return err
+	}
+
+	service.SendJSONResponse(w, deadLetters)
+	return nil
+}
+
+func replayHandler(svc WebhookService, w http.ResponseWriter, r *http.Request) error {
+	webhookId := mux.Vars(r)["webhookId"]
+	result, err := svc.Replay(r.Context(), webhookId)
+	if err != nil {
+		return err
+	}
+
+	service.SendJSONResponse(w, result)
+	return nil
+}
@@ -0,0 +1,136 @@
+// Copyright 2024 WorkOS, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webhook
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+type Model interface {
+	GetID() int64
+	GetWebhookId() string
+	GetURL() string
+	GetSecret() string
+	GetEventTypes() *string
+	GetCreatedAt() time.Time
+	GetUpdatedAt() time.Time
+	GetDeletedAt() *time.Time
+	ToWebhookSpec() (*WebhookSpec, error)
+}
+
+type Webhook struct {
+	ID         int64      `mysql:"id"         postgres:"id"          sqlite:"id"`
+	WebhookId  string     `mysql:"webhookId"  postgres:"webhook_id"  sqlite:"webhookId"`
+	URL        string     `mysql:"url"        postgres:"url"         sqlite:"url"`
+	Secret     string     `mysql:"secret"     postgres:"secret"      sqlite:"secret"`
+	EventTypes *string    `mysql:"eventTypes" postgres:"event_types" sqlite:"eventTypes"`
+	CreatedAt  time.Time  `mysql:"createdAt"  postgres:"created_at"  sqlite:"createdAt"`
+	UpdatedAt  time.Time  `mysql:"updatedAt"  postgres:"updated_at"  sqlite:"updatedAt"`
+	DeletedAt  *time.Time `mysql:"deletedAt"  postgres:"deleted_at"  sqlite:"deletedAt"`
+}
+
+func (webhook Webhook) GetID() int64 {
+	return webhook.ID
+}
+
+func (webhook Webhook) GetWebhookId() string {
+	return webhook.WebhookId
+}
+
+func (webhook Webhook) GetURL() string {
+	return webhook.URL
+}
+
+func (webhook Webhook) GetSecret() string {
+	return webhook.Secret
+}
+
+func (webhook Webhook) GetEventTypes() *string {
+	return webhook.EventTypes
+}
+
+func (webhook Webhook) GetCreatedAt() time.Time {
+	return webhook.CreatedAt
+}
+
+func (webhook Webhook) GetUpdatedAt() time.Time {
+	return webhook.UpdatedAt
+}
+
+func (webhook Webhook) GetDeletedAt() *time.Time {
+	return webhook.DeletedAt
+}
+
+func (webhook Webhook) ToWebhookSpec() (*WebhookSpec, error) {
+	var eventTypes []string
+	if webhook.EventTypes != nil {
+		err := json.Unmarshal([]byte(*webhook.EventTypes), &eventTypes)
+		if err != nil {
+			return nil, errors.Wrapf(err, "error unmarshaling event types for webhook %s", webhook.WebhookId)
+		}
+	}
+
+	return &WebhookSpec{
+		ID:         webhook.ID,
+		WebhookId:  webhook.WebhookId,
+		URL:        webhook.URL,
+		EventTypes: eventTypes,
+		CreatedAt:  webhook.CreatedAt,
+	}, nil
+}
+
+// A pending delivery of a change to a webhook.
+type Delivery struct {
+	ID            int64     `mysql:"id"            postgres:"id"              sqlite:"id"`
+	WebhookId     int64     `mysql:"webhookId"     postgres:"webhook_id"      sqlite:"webhookId"`
+	ChangeId      int64     `mysql:"changeId"      postgres:"change_id"       sqlite:"changeId"`
+	Type          string    `mysql:"type"          postgres:"type"            sqlite:"type"`
+	Payload       string    `mysql:"payload"       postgres:"payload"         sqlite:"payload"`
+	Attempts      int       `mysql:"attempts"      postgres:"attempts"        sqlite:"attempts"`
+	NextAttemptAt time.Time `mysql:"nextAttemptAt" postgres:"next_attempt_at" sqlite:"nextAttemptAt"`
+	LastError     *string   `mysql:"lastError"     postgres:"last_error"      sqlite:"lastError"`
+	CreatedAt     time.Time `mysql:"createdAt"     postgres:"created_at"      sqlite:"createdAt"`
+
+	// The webhook's URL and secret, joined in when listing due deliveries
+	URL    string `mysql:"url"    postgres:"url"    sqlite:"url"`
+	Secret string `mysql:"secret" postgres:"secret" sqlite:"secret"`
+}
+
+// A delivery that failed maxAttempts times. It can be replayed once the
+// webhook's endpoint is fixed.
+type DeadLetter struct {
+	ID        int64     `mysql:"id"        postgres:"id"         sqlite:"id"`
+	WebhookId int64     `mysql:"webhookId" postgres:"webhook_id" sqlite:"webhookId"`
+	ChangeId  int64     `mysql:"changeId"  postgres:"change_id"  sqlite:"changeId"`
+	Type      string    `mysql:"type"      postgres:"type"       sqlite:"type"`
+	Payload   string    `mysql:"payload"   postgres:"payload"    sqlite:"payload"`
+	Attempts  int       `mysql:"attempts"  postgres:"attempts"   sqlite:"attempts"`
+	LastError *string   `mysql:"lastError" postgres:"last_error" sqlite:"lastError"`
+	CreatedAt time.Time `mysql:"createdAt" postgres:"created_at" sqlite:"createdAt"`
+}
+
+func (deadLetter DeadLetter) ToDeadLetterSpec() DeadLetterSpec {
+	return DeadLetterSpec{
+		ID:        deadLetter.ID,
+		Type:      deadLetter.Type,
+		Payload:   json.RawMessage(deadLetter.Payload),
+		Attempts:  deadLetter.Attempts,
+		LastError: deadLetter.LastError,
+		CreatedAt: deadLetter.CreatedAt,
+	}
+}
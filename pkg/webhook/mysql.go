@@ -0,0 +1,375 @@
+// Copyright 2024 WorkOS, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webhook
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/warrant-dev/warrant/pkg/database"
+	"github.com/warrant-dev/warrant/pkg/service"
+)
+
+type MySQLRepository struct {
+	database.SQLRepository
+}
+
+func NewMySQLRepository(db *database.MySQL) *MySQLRepository {
+	return &MySQLRepository{
+		database.NewSQLRepository(&db.SQL),
+	}
+}
+
+func (repo MySQLRepository) Create(ctx context.Context, model Model) (int64, error) {
+	result, err := repo.DB.ExecContext(
+		ctx,
+		`
+			INSERT INTO webhook (
+				webhookId,
+				url,
+				secret,
+				eventTypes
+			) VALUES (?, ?, ?, ?)
+		`,
+		model.GetWebhookId(),
+		model.GetURL(),
+		model.GetSecret(),
+		model.GetEventTypes(),
+	)
+	if err != nil {
+		return -1, errors.Wrap(err, "error creating webhook")
+	}
+
+	newWebhookId, err := result.LastInsertId()
+	if err != nil {
+		return -1, errors.Wrap(err, "error creating webhook")
+	}
+
+	return newWebhookId, nil
+}
+
+func (repo MySQLRepository) GetById(ctx context.Context, id int64) (Model, error) {
+	var webhook Webhook
+	err := repo.DB.GetContext(
+		ctx,
+		&webhook,
+		`
+			SELECT id, webhookId, url, secret, eventTypes, createdAt, updatedAt, deletedAt
+			FROM webhook
+			WHERE
+				id = ? AND
+				deletedAt IS NULL
+		`,
+		id,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, service.NewRecordNotFoundError("Webhook", id)
+		}
+		return nil, errors.Wrapf(err, "error getting webhook %d", id)
+	}
+
+	return &webhook, nil
+}
+
+func (repo MySQLRepository) GetByWebhookId(ctx context.Context, webhookId string) (Model, error) {
+	var webhook Webhook
+	err := repo.DB.GetContext(
+		ctx,
+		&webhook,
+		`
+			SELECT id, webhookId, url, secret, eventTypes, createdAt, updatedAt, deletedAt
+			FROM webhook
+			WHERE
+				webhookId = ? AND
+				deletedAt IS NULL
+		`,
+		webhookId,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, service.NewRecordNotFoundError("Webhook", webhookId)
+		}
+		return nil, errors.Wrapf(err, "error getting webhook %s", webhookId)
+	}
+
+	return &webhook, nil
+}
+
+func (repo MySQLRepository) List(ctx context.Context) ([]Model, error) {
+	models := make([]Model, 0)
+	webhooks := make([]Webhook, 0)
+	err := repo.DB.SelectContext(
+		ctx,
+		&webhooks,
+		`
+			SELECT id, webhookId, url, secret, eventTypes, createdAt, updatedAt, deletedAt
+			FROM webhook
+			WHERE
+				deletedAt IS NULL
+			ORDER BY id ASC
+		`,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models, nil
+		}
+		return nil, errors.Wrap(err, "error listing webhooks")
+	}
+
+	for i := range webhooks {
+		models = append(models, &webhooks[i])
+	}
+
+	return models, nil
+}
+
+func (repo MySQLRepository) DeleteByWebhookId(ctx context.Context, webhookId string) error {
+	_, err := repo.DB.ExecContext(
+		ctx,
+		`
+			UPDATE webhook
+			SET
+				updatedAt = CURRENT_TIMESTAMP(6),
+				deletedAt = CURRENT_TIMESTAMP(6)
+			WHERE
+				webhookId = ? AND
+				deletedAt IS NULL
+		`,
+		webhookId,
+	)
+	if err != nil {
+		return errors.Wrapf(err, "error deleting webhook %s", webhookId)
+	}
+
+	return nil
+}
+
+func (repo MySQLRepository) CreateDelivery(ctx context.Context, delivery Delivery) (int64, error) {
+	result, err := repo.DB.ExecContext(
+		ctx,
+		`
+			INSERT INTO webhookDelivery (
+				webhookId,
+				changeId,
+				type,
+				payload,
+				nextAttemptAt
+			) VALUES (?, ?, ?, ?, ?)
+		`,
+		delivery.WebhookId,
+		delivery.ChangeId,
+		delivery.Type,
+		delivery.Payload,
+		delivery.NextAttemptAt,
+	)
+	if err != nil {
+		return -1, errors.Wrap(err, "error creating webhook delivery")
+	}
+
+	newDeliveryId, err := result.LastInsertId()
+	if err != nil {
+		return -1, errors.Wrap(err, "error creating webhook delivery")
+	}
+
+	return newDeliveryId, nil
+}
+
+func (repo MySQLRepository) ListDueDeliveries(ctx context.Context, now time.Time, limit int) ([]Delivery, error) {
+	deliveries := make([]Delivery, 0)
+	err := repo.DB.SelectContext(
+		database.CtxWithWriterOverride(ctx),
+		&deliveries,
+		`
+			SELECT d.id, d.webhookId, d.changeId, d.type, d.payload, d.attempts, d.nextAttemptAt, d.lastError, d.createdAt, w.url, w.secret
+			FROM webhookDelivery d
+			INNER JOIN webhook w ON w.id = d.webhookId
+			WHERE
+				d.nextAttemptAt <= ? AND
+				w.deletedAt IS NULL
+			ORDER BY d.nextAttemptAt ASC, d.id ASC
+			LIMIT ?
+		`,
+		now,
+		limit,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return deliveries, nil
+		}
+		return nil, errors.Wrap(err, "error listing webhook deliveries")
+	}
+
+	return deliveries, nil
+}
+
+func (repo MySQLRepository) ClaimDelivery(ctx context.Context, id int64, attempts int, leaseUntil time.Time) (bool, error) {
+	result, err := repo.DB.ExecContext(
+		ctx,
+		`
+			UPDATE webhookDelivery
+			SET
+				attempts = attempts + 1,
+				nextAttemptAt = ?
+			WHERE
+				id = ? AND
+				attempts = ?
+		`,
+		leaseUntil,
+		id,
+		attempts,
+	)
+	if err != nil {
+		return false, errors.Wrapf(err, "error claiming webhook delivery %d", id)
+	}
+
+	numClaimed, err := result.RowsAffected()
+	if err != nil {
+		return false, errors.Wrapf(err, "error claiming webhook delivery %d", id)
+	}
+
+	return numClaimed == 1, nil
+}
+
+func (repo MySQLRepository) RescheduleDelivery(ctx context.Context, id int64, nextAttemptAt time.Time, lastError string) error {
+	_, err := repo.DB.ExecContext(
+		ctx,
+		`
+			UPDATE webhookDelivery
+			SET
+				nextAttemptAt = ?,
+				lastError = ?
+			WHERE
+				id = ?
+		`,
+		nextAttemptAt,
+		lastError,
+		id,
+	)
+	if err != nil {
+		return errors.Wrapf(err, "error rescheduling webhook delivery %d", id)
+	}
+
+	return nil
+}
+
+func (repo MySQLRepository) DeleteDelivery(ctx context.Context, id int64) error {
+	_, err := repo.DB.ExecContext(
+		ctx,
+		`
+			DELETE FROM webhookDelivery
+			WHERE
+				id = ?
+		`,
+		id,
+	)
+	if err != nil {
+		return errors.Wrapf(err, "error deleting webhook delivery %d", id)
+	}
+
+	return nil
+}
+
+func (repo MySQLRepository) DeleteDeliveriesByWebhookId(ctx context.Context, webhookId int64) error {
+	_, err := repo.DB.ExecContext(
+		ctx,
+		`
+			DELETE FROM webhookDelivery
+			WHERE
+				webhookId = ?
+		`,
+		webhookId,
+	)
+	if err != nil {
+		return errors.Wrapf(err, "error deleting deliveries for webhook %d", webhookId)
+	}
+
+	return nil
+}
+
+func (repo MySQLRepository) CreateDeadLetter(ctx context.Context, deadLetter DeadLetter) (int64, error) {
+	result, err := repo.DB.ExecContext(
+		ctx,
+		`
+			INSERT INTO webhookDeadLetter (
+				webhookId,
+				changeId,
+				type,
+				payload,
+				attempts,
+				lastError
+			) VALUES (?, ?, ?, ?, ?, ?)
+		`,
+		deadLetter.WebhookId,
+		deadLetter.ChangeId,
+		deadLetter.Type,
+		deadLetter.Payload,
+		deadLetter.Attempts,
+		deadLetter.LastError,
+	)
+	if err != nil {
+		return -1, errors.Wrap(err, "error creating webhook dead letter")
+	}
+
+	newDeadLetterId, err := result.LastInsertId()
+	if err != nil {
+		return -1, errors.Wrap(err, "error creating webhook dead letter")
+	}
+
+	return newDeadLetterId, nil
+}
+
+func (repo MySQLRepository) ListDeadLetters(ctx context.Context, webhookId int64) ([]DeadLetter, error) {
+	deadLetters := make([]DeadLetter, 0)
+	err := repo.DB.SelectContext(
+		ctx,
+		&deadLetters,
+		`
+			SELECT id, webhookId, changeId, type, payload, attempts, lastError, createdAt
+			FROM webhookDeadLetter
+			WHERE
+				webhookId = ?
+			ORDER BY id ASC
+		`,
+		webhookId,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return deadLetters, nil
+		}
+		return nil, errors.Wrapf(err, "error listing dead letters for webhook %d", webhookId)
+	}
+
+	return deadLetters, nil
+}
+
+func (repo MySQLRepository) DeleteDeadLettersByWebhookId(ctx context.Context, webhookId int64) error {
+	_, err := repo.DB.ExecContext(
+		ctx,
+		`
+			DELETE FROM webhookDeadLetter
+			WHERE
+				webhookId = ?
+		`,
+		webhookId,
+	)
+	if err != nil {
+		return errors.Wrapf(err, "error deleting dead letters for webhook %d", webhookId)
+	}
+
+	return nil
+}
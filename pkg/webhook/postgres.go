@@ -0,0 +1,369 @@
+// Copyright 2024 WorkOS, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webhook
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/warrant-dev/warrant/pkg/database"
+	"github.com/warrant-dev/warrant/pkg/service"
+)
+
+type PostgresRepository struct {
+	database.SQLRepository
+}
+
+func NewPostgresRepository(db *database.Postgres) *PostgresRepository {
+	return &PostgresRepository{
+		database.NewSQLRepository(&db.SQL),
+	}
+}
+
+func (repo PostgresRepository) Create(ctx context.Context, model Model) (int64, error) {
+	var newWebhookId int64
+	err := repo.DB.GetContext(
+		database.CtxWithWriterOverride(ctx),
+		&newWebhookId,
+		`
+			INSERT INTO webhook (
+				webhook_id,
+				url,
+				secret,
+				event_types
+			) VALUES (?, ?, ?, ?)
+			RETURNING id
+		`,
+		model.GetWebhookId(),
+		model.GetURL(),
+		model.GetSecret(),
+		model.GetEventTypes(),
+	)
+	if err != nil {
+		return -1, errors.Wrap(err, "error creating webhook")
+	}
+
+	return newWebhookId, nil
+}
+
+func (repo PostgresRepository) GetById(ctx context.Context, id int64) (Model, error) {
+	var webhook Webhook
+	err := repo.DB.GetContext(
+		ctx,
+		&webhook,
+		`
+			SELECT id, webhook_id, url, secret, event_types, created_at, updated_at, deleted_at
+			FROM webhook
+			WHERE
+				id = ? AND
+				deleted_at IS NULL
+		`,
+		id,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, service.NewRecordNotFoundError("Webhook", id)
+		}
+		return nil, errors.Wrapf(err, "error getting webhook %d", id)
+	}
+
+	return &webhook, nil
+}
+
+func (repo PostgresRepository) GetByWebhookId(ctx context.Context, webhookId string) (Model, error) {
+	var webhook Webhook
+	err := repo.DB.GetContext(
+		ctx,
+		&webhook,
+		`
+			SELECT id, webhook_id, url, secret, event_types, created_at, updated_at, deleted_at
+			FROM webhook
+			WHERE
+				webhook_id = ? AND
+				deleted_at IS NULL
+		`,
+		webhookId,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, service.NewRecordNotFoundError("Webhook", webhookId)
+		}
+		return nil, errors.Wrapf(err, "error getting webhook %s", webhookId)
+	}
+
+	return &webhook, nil
+}
+
+func (repo PostgresRepository) List(ctx context.Context) ([]Model, error) {
+	models := make([]Model, 0)
+	webhooks := make([]Webhook, 0)
+	err := repo.DB.SelectContext(
+		ctx,
+		&webhooks,
+		`
+			SELECT id, webhook_id, url, secret, event_types, created_at, updated_at, deleted_at
+			FROM webhook
+			WHERE
+				deleted_at IS NULL
+			ORDER BY id ASC
+		`,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models, nil
+		}
+		return nil, errors.Wrap(err, "error listing webhooks")
+	}
+
+	for i := range webhooks {
+		models = append(models, &webhooks[i])
+	}
+
+	return models, nil
+}
+
+func (repo PostgresRepository) DeleteByWebhookId(ctx context.Context, webhookId string) error {
+	_, err := repo.DB.ExecContext(
+		ctx,
+		`
+			UPDATE webhook
+			SET
+				updated_at = CURRENT_TIMESTAMP(6),
+				deleted_at = CURRENT_TIMESTAMP(6)
+			WHERE
+				webhook_id = ? AND
+				deleted_at IS NULL
+		`,
+		webhookId,
+	)
+	if err != nil {
+		return errors.Wrapf(err, "error deleting webhook %s", webhookId)
+	}
+
+	return nil
+}
+
+func (repo PostgresRepository) CreateDelivery(ctx context.Context, delivery Delivery) (int64, error) {
+	var newDeliveryId int64
+	err := repo.DB.GetContext(
+		database.CtxWithWriterOverride(ctx),
+		&newDeliveryId,
+		`
+			INSERT INTO webhook_delivery (
+				webhook_id,
+				change_id,
+				type,
+				payload,
+				next_attempt_at
+			) VALUES (?, ?, ?, ?, ?)
+			RETURNING id
+		`,
+		delivery.WebhookId,
+		delivery.ChangeId,
+		delivery.Type,
+		delivery.Payload,
+		delivery.NextAttemptAt,
+	)
+	if err != nil {
+		return -1, errors.Wrap(err, "error creating webhook delivery")
+	}
+
+	return newDeliveryId, nil
+}
+
+func (repo PostgresRepository) ListDueDeliveries(ctx context.Context, now time.Time, limit int) ([]Delivery, error) {
+	deliveries := make([]Delivery, 0)
+	err := repo.DB.SelectContext(
+		database.CtxWithWriterOverride(ctx),
+		&deliveries,
+		`
+			SELECT d.id, d.webhook_id, d.change_id, d.type, d.payload, d.attempts, d.next_attempt_at, d.last_error, d.created_at, w.url, w.secret
+			FROM webhook_delivery d
+			INNER JOIN webhook w ON w.id = d.webhook_id
+			WHERE
+				d.next_attempt_at <= ? AND
+				w.deleted_at IS NULL
+			ORDER BY d.next_attempt_at ASC, d.id ASC
+			LIMIT ?
+		`,
+		now,
+		limit,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return deliveries, nil
+		}
+		return nil, errors.Wrap(err, "error listing webhook deliveries")
+	}
+
+	return deliveries, nil
+}
+
+func (repo PostgresRepository) ClaimDelivery(ctx context.Context, id int64, attempts int, leaseUntil time.Time) (bool, error) {
+	result, err := repo.DB.ExecContext(
+		ctx,
+		`
+			UPDATE webhook_delivery
+			SET
+				attempts = attempts + 1,
+				next_attempt_at = ?
+			WHERE
+				id = ? AND
+				attempts = ?
+		`,
+		leaseUntil,
+		id,
+		attempts,
+	)
+	if err != nil {
+		return false, errors.Wrapf(err, "error claiming webhook delivery %d", id)
+	}
+
+	numClaimed, err := result.RowsAffected()
+	if err != nil {
+		return false, errors.Wrapf(err, "error claiming webhook delivery %d", id)
+	}
+
+	return numClaimed == 1, nil
+}
+
+func (repo PostgresRepository) RescheduleDelivery(ctx context.Context, id int64, nextAttemptAt time.Time, lastError string) error {
+	_, err := repo.DB.ExecContext(
+		ctx,
+		`
+			UPDATE webhook_delivery
+			SET
+				next_attempt_at = ?,
+				last_error = ?
+			WHERE
+				id = ?
+		`,
+		nextAttemptAt,
+		lastError,
+		id,
+	)
+	if err != nil {
+		return errors.Wrapf(err, "error rescheduling webhook delivery %d", id)
+	}
+
+	return nil
+}
+
+func (repo PostgresRepository) DeleteDelivery(ctx context.Context, id int64) error {
+	_, err := repo.DB.ExecContext(
+		ctx,
+		`
+			DELETE FROM webhook_delivery
+			WHERE
+				id = ?
+		`,
+		id,
+	)
+	if err != nil {
+		return errors.Wrapf(err, "error deleting webhook delivery %d", id)
+	}
+
+	return nil
+}
+
+func (repo PostgresRepository) DeleteDeliveriesByWebhookId(ctx context.Context, webhookId int64) error {
+	_, err := repo.DB.ExecContext(
+		ctx,
+		`
+			DELETE FROM webhook_delivery
+			WHERE
+				webhook_id = ?
+		`,
+		webhookId,
+	)
+	if err != nil {
+		return errors.Wrapf(err, "error deleting deliveries for webhook %d", webhookId)
+	}
+
+	return nil
+}
+
+func (repo PostgresRepository) CreateDeadLetter(ctx context.Context, deadLetter DeadLetter) (int64, error) {
+	var newDeadLetterId int64
+	err := repo.DB.GetContext(
+		database.CtxWithWriterOverride(ctx),
+		&newDeadLetterId,
+		`
+			INSERT INTO webhook_dead_letter (
+				webhook_id,
+				change_id,
+				type,
+				payload,
+				attempts,
+				last_error
+			) VALUES (?, ?, ?, ?, ?, ?)
+			RETURNING id
+		`,
+		deadLetter.WebhookId,
+		deadLetter.ChangeId,
+		deadLetter.Type,
+		deadLetter.Payload,
+		deadLetter.Attempts,
+		deadLetter.LastError,
+	)
+	if err != nil {
+		return -1, errors.Wrap(err, "error creating webhook dead letter")
+	}
+
+	return newDeadLetterId, nil
+}
+
+func (repo PostgresRepository) ListDeadLetters(ctx context.Context, webhookId int64) ([]DeadLetter, error) {
+	deadLetters := make([]DeadLetter, 0)
+	err := repo.DB.SelectContext(
+		ctx,
+		&deadLetters,
+		`
+			SELECT id, webhook_id, change_id, type, payload, attempts, last_error, created_at
+			FROM webhook_dead_letter
+			WHERE
+				webhook_id = ?
+			ORDER BY id ASC
+		`,
+		webhookId,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return deadLetters, nil
+		}
+		return nil, errors.Wrapf(err, "error listing dead letters for webhook %d", webhookId)
+	}
+
+	return deadLetters, nil
+}
+
+func (repo PostgresRepository) DeleteDeadLettersByWebhookId(ctx context.Context, webhookId int64) error {
+	_, err := repo.DB.ExecContext(
+		ctx,
+		`
+			DELETE FROM webhook_dead_letter
+			WHERE
+				webhook_id = ?
+		`,
+		webhookId,
+	)
+	if err != nil {
+		return errors.Wrapf(err, "error deleting dead letters for webhook %d", webhookId)
+	}
+
+	return nil
+}
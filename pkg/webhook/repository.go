@@ -0,0 +1,69 @@
+// Copyright 2024 WorkOS, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webhook
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/warrant-dev/warrant/pkg/database"
+)
+
+type WebhookRepository interface {
+	Create(ctx context.Context, webhook Model) (int64, error)
+	GetById(ctx context.Context, id int64) (Model, error)
+	GetByWebhookId(ctx context.Context, webhookId string) (Model, error)
+	List(ctx context.Context) ([]Model, error)
+	DeleteByWebhookId(ctx context.Context, webhookId string) error
+	CreateDelivery(ctx context.Context, delivery Delivery) (int64, error)
+	ListDueDeliveries(ctx context.Context, now time.Time, limit int) ([]Delivery, error)
+	ClaimDelivery(ctx context.Context, id int64, attempts int, leaseUntil time.Time) (bool, error)
+	RescheduleDelivery(ctx context.Context, id int64, nextAttemptAt time.Time, lastError string) error
+	DeleteDelivery(ctx context.Context, id int64) error
+	DeleteDeliveriesByWebhookId(ctx context.Context, webhookId int64) error
+	CreateDeadLetter(ctx context.Context, deadLetter DeadLetter) (int64, error)
+	ListDeadLetters(ctx context.Context, webhookId int64) ([]DeadLetter, error)
+	DeleteDeadLettersByWebhookId(ctx context.Context, webhookId int64) error
+}
+
+func NewRepository(db database.Database) (WebhookRepository, error) {
+	switch db.Type() {
+	case database.TypeMySQL:
+		mysql, ok := db.(*database.MySQL)
+		if !ok {
+			return nil, errors.New(fmt.Sprintf("invalid %s database config", database.TypeMySQL))
+		}
+
+		return NewMySQLRepository(mysql), nil
+	case database.TypePostgres:
+		postgres, ok := db.(*database.Postgres)
+		if !ok {
+			return nil, errors.New(fmt.Sprintf("invalid %s database config", database.TypePostgres))
+		}
+
+		return NewPostgresRepository(postgres), nil
+	case database.TypeSQLite:
+		sqlite, ok := db.(*database.SQLite)
+		if !ok {
+			return nil, errors.New(fmt.Sprintf("invalid %s database config", database.TypeSQLite))
+		}
+
+		return NewSQLiteRepository(sqlite), nil
+	default:
+		return nil, errors.New(fmt.Sprintf("unsupported database type %s specified", db.Type()))
+	}
+}
@@ -0,0 +1,314 @@
+// Copyright 2024 WorkOS, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webhook
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"github.com/warrant-dev/warrant/pkg/changelog"
+	"github.com/warrant-dev/warrant/pkg/config"
+	"github.com/warrant-dev/warrant/pkg/database"
+	"github.com/warrant-dev/warrant/pkg/service"
+)
+
+const secretPrefix = "whsec_"
+
+// The max number of transactions Enqueue keeps listed webhooks for.
+const maxTxWebhooks = 64
+
+type WebhookService struct {
+	service.BaseService
+	repository WebhookRepository
+	config     *config.WebhooksConfig
+	client     *http.Client
+	txWebhooks *txWebhooks
+}
+
+func NewService(env service.Env, repository WebhookRepository, cfg *config.WebhooksConfig) *WebhookService {
+	return &WebhookService{
+		BaseService: service.NewBaseService(env),
+		repository:  repository,
+		config:      cfg,
+		client: &http.Client{
+			Timeout: cfg.Timeout,
+		},
+		txWebhooks: newTxWebhooks(),
+	}
+}
+
+func (svc WebhookService) Create(ctx context.Context, spec CreateWebhookSpec) (*WebhookSpec, error) {
+	webhookId, err := uuid.NewV7()
+	if err != nil {
+		return nil, errors.New("unable to generate random UUID for webhook")
+	}
+
+	secret := spec.Secret
+	if secret == "" {
+		secret, err = generateSecret()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var createdWebhook Model
+	var createdWebhookSpec *WebhookSpec
+	err = svc.Env().DB().WithinTransaction(ctx, func(txCtx context.Context) error {
+		webhook, err := spec.ToWebhook(webhookId.String(), secret)
+		if err != nil {
+			return err
+		}
+
+		newWebhookId, err := svc.repository.Create(txCtx, webhook)
+		if err != nil {
+			return err
+		}
+
+		createdWebhook, err = svc.repository.GetById(txCtx, newWebhookId)
+		if err != nil {
+			return err
+		}
+
+		createdWebhookSpec, err = createdWebhook.ToWebhookSpec()
+		if err != nil {
+			return err
+		}
+
+		return svc.RecordChange(txCtx, ChangeTypeWebhookCreated, nil, createdWebhookSpec)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	createdWebhookSpec.Secret = createdWebhook.GetSecret()
+	return createdWebhookSpec, nil
+}
+
+func (svc WebhookService) GetByWebhookId(ctx context.Context, webhookId string) (*WebhookSpec, error) {
+	webhook, err := svc.repository.GetByWebhookId(ctx, webhookId)
+	if err != nil {
+		return nil, err
+	}
+
+	return webhook.ToWebhookSpec()
+}
+
+func (svc WebhookService) List(ctx context.Context) ([]WebhookSpec, error) {
+	webhooks, err := svc.repository.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	webhookSpecs := make([]WebhookSpec, 0, len(webhooks))
+	for _, webhook := range webhooks {
+		webhookSpec, err := webhook.ToWebhookSpec()
+		if err != nil {
+			return nil, err
+		}
+
+		webhookSpecs = append(webhookSpecs, *webhookSpec)
+	}
+
+	return webhookSpecs, nil
+}
+
+// DeleteByWebhookId deletes the webhook along with its pending deliveries and dead letters.
+func (svc WebhookService) DeleteByWebhookId(ctx context.Context, webhookId string) error {
+	return svc.Env().DB().WithinTransaction(ctx, func(txCtx context.Context) error {
+		webhook, err := svc.repository.GetByWebhookId(txCtx, webhookId)
+		if err != nil {
+			return err
+		}
+
+		err = svc.repository.DeleteDeliveriesByWebhookId(txCtx, webhook.GetID())
+		if err != nil {
+			return err
+		}
+
+		err = svc.repository.DeleteDeadLettersByWebhookId(txCtx, webhook.GetID())
+		if err != nil {
+			return err
+		}
+
+		err = svc.repository.DeleteByWebhookId(txCtx, webhookId)
+		if err != nil {
+			return err
+		}
+
+		webhookSpec, err := webhook.ToWebhookSpec()
+		if err != nil {
+			return err
+		}
+
+		return svc.RecordChange(txCtx, ChangeTypeWebhookDeleted, webhookSpec, nil)
+	})
+}
+
+func (svc WebhookService) ListDeadLetters(ctx context.Context, webhookId string) ([]DeadLetterSpec, error) {
+	webhook, err := svc.repository.GetByWebhookId(ctx, webhookId)
+	if err != nil {
+		return nil, err
+	}
+
+	deadLetters, err := svc.repository.ListDeadLetters(ctx, webhook.GetID())
+	if err != nil {
+		return nil, err
+	}
+
+	deadLetterSpecs := make([]DeadLetterSpec, 0, len(deadLetters))
+	for _, deadLetter := range deadLetters {
+		deadLetterSpecs = append(deadLetterSpecs, deadLetter.ToDeadLetterSpec())
+	}
+
+	return deadLetterSpecs, nil
+}
+
+// Replay queues all of the webhook's dead letters to be delivered again and
+// returns the number of dead letters replayed.
+func (svc WebhookService) Replay(ctx context.Context, webhookId string) (*ReplayResultSpec, error) {
+	var numReplayed int64
+	err := svc.Env().DB().WithinTransaction(ctx, func(txCtx context.Context) error {
+		webhook, err := svc.repository.GetByWebhookId(txCtx, webhookId)
+		if err != nil {
+			return err
+		}
+
+		deadLetters, err := svc.repository.ListDeadLetters(txCtx, webhook.GetID())
+		if err != nil {
+			return err
+		}
+
+		now := time.Now().UTC()
+		for _, deadLetter := range deadLetters {
+			_, err = svc.repository.CreateDelivery(txCtx, Delivery{
+				WebhookId:     deadLetter.WebhookId,
+				ChangeId:      deadLetter.ChangeId,
+				Type:          deadLetter.Type,
+				Payload:       deadLetter.Payload,
+				NextAttemptAt: now,
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		numReplayed = int64(len(deadLetters))
+		return svc.repository.DeleteDeadLettersByWebhookId(txCtx, webhook.GetID())
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &ReplayResultSpec{
+		Count: numReplayed,
+	}, nil
+}
+
+// Enqueue queues a delivery of the change to each webhook subscribed to it.
+// It's registered as a ChangeListener so that deliveries are only queued if
+// the transaction making the change commits. Webhooks are only listed once
+// per transaction, no matter how many changes it records.
+func (svc WebhookService) Enqueue(ctx context.Context, changeSpec changelog.ChangeSpec) error {
+	wookieId, _ := database.WookieIdFromContext(ctx)
+	webhooks, ok := svc.txWebhooks.get(wookieId)
+	if !ok {
+		var err error
+		webhooks, err = svc.List(ctx)
+		if err != nil {
+			return err
+		}
+
+		svc.txWebhooks.set(wookieId, webhooks)
+	}
+
+	if len(webhooks) == 0 {
+		return nil
+	}
+
+	payload, err := json.Marshal(changeSpec)
+	if err != nil {
+		return errors.Wrapf(err, "error marshaling %s change", changeSpec.Type)
+	}
+
+	now := time.Now().UTC()
+	for _, webhookSpec := range webhooks {
+		if !webhookSpec.IsSubscribedTo(changeSpec.Type) {
+			continue
+		}
+
+		_, err = svc.repository.CreateDelivery(ctx, Delivery{
+			WebhookId:     webhookSpec.ID,
+			ChangeId:      changeSpec.ID,
+			Type:          changeSpec.Type,
+			Payload:       string(payload),
+			NextAttemptAt: now,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Webhooks listed by Enqueue, keyed by the wookie id of the transaction they
+// were listed in.
+type txWebhooks struct {
+	mutex    sync.Mutex
+	webhooks map[int64][]WebhookSpec
+}
+
+func newTxWebhooks() *txWebhooks {
+	return &txWebhooks{
+		webhooks: make(map[int64][]WebhookSpec),
+	}
+}
+
+func (t *txWebhooks) get(wookieId int64) ([]WebhookSpec, bool) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	webhooks, ok := t.webhooks[wookieId]
+	return webhooks, ok
+}
+
+func (t *txWebhooks) set(wookieId int64, webhooks []WebhookSpec) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	// Transactions that have ended are never looked up again, so any entry can go
+	if len(t.webhooks) >= maxTxWebhooks {
+		for id := range t.webhooks {
+			delete(t.webhooks, id)
+			break
+		}
+	}
+	t.webhooks[wookieId] = webhooks
+}
+
+func generateSecret() (string, error) {
+	b := make([]byte, 32)
+	_, err := rand.Read(b)
+	if err != nil {
+		return "", errors.Wrap(err, "error generating webhook secret")
+	}
+
+	return secretPrefix + hex.EncodeToString(b), nil
+}
@@ -0,0 +1,94 @@
+// Copyright 2024 WorkOS, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webhook
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/warrant-dev/warrant/pkg/service"
+)
+
+const (
+	ChangeTypeWebhookCreated = "webhook.created"
+	ChangeTypeWebhookDeleted = "webhook.deleted"
+)
+
+type WebhookSpec struct {
+	// NOTE: ID is required here for internal use.
+	// However, we don't return it to the client.
+	ID        int64  `json:"-"`
+	WebhookId string `json:"webhookId"`
+	URL       string `json:"url"`
+	// NOTE: Secret is only returned when the webhook is created.
+	Secret     string    `json:"secret,omitempty"`
+	EventTypes []string  `json:"eventTypes,omitempty"`
+	CreatedAt  time.Time `json:"createdAt"`
+}
+
+type CreateWebhookSpec struct {
+	URL        string   `json:"url"        validate:"required,http_url,max=2048"`
+	Secret     string   `json:"secret"     validate:"omitempty,min=16,max=128"`
+	EventTypes []string `json:"eventTypes" validate:"dive,oneof=warrant.created warrant.deleted object.created object.updated object.deleted objecttype.created objecttype.updated objecttype.deleted"`
+}
+
+func (spec CreateWebhookSpec) ToWebhook(webhookId string, secret string) (*Webhook, error) {
+	var eventTypes *string
+	if len(spec.EventTypes) > 0 {
+		e, err := json.Marshal(spec.EventTypes)
+		if err != nil {
+			return nil, service.NewInvalidParameterError("eventTypes", "invalid format")
+		}
+
+		eventTypesStr := string(e)
+		eventTypes = &eventTypesStr
+	}
+
+	return &Webhook{
+		WebhookId:  webhookId,
+		URL:        spec.URL,
+		Secret:     secret,
+		EventTypes: eventTypes,
+	}, nil
+}
+
+// Returns true if the webhook should receive events of the given type. A
+// webhook without event types receives all events.
+func (spec WebhookSpec) IsSubscribedTo(eventType string) bool {
+	if len(spec.EventTypes) == 0 {
+		return true
+	}
+
+	for _, subscribedType := range spec.EventTypes {
+		if subscribedType == eventType {
+			return true
+		}
+	}
+
+	return false
+}
+
+type DeadLetterSpec struct {
+	ID        int64           `json:"id"`
+	Type      string          `json:"type"`
+	Payload   json.RawMessage `json:"payload"`
+	Attempts  int             `json:"attempts"`
+	LastError *string         `json:"lastError,omitempty"`
+	CreatedAt time.Time       `json:"createdAt"`
+}
+
+type ReplayResultSpec struct {
+	Count int64 `json:"count"`
+}
@@ -17,16 +17,27 @@ package wookie
 import (
 	"context"
 	"net/http"
+
+	"github.com/rs/zerolog/log"
 )
 
 const HeaderName = "Warrant-Token"
 const Latest = "latest"
+const TokenVersion = 1
 
 type warrantTokenCtxKey struct{}
 
 func WarrantTokenMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		headerVal := r.Header.Get(HeaderName)
+		if headerVal != "" && headerVal != Latest {
+			_, err := FromString(headerVal)
+			if err != nil {
+				log.Ctx(r.Context()).Debug().Err(err).Msgf("wookie: ignoring invalid %s header", HeaderName)
+				next.ServeHTTP(w, r)
+				return
+			}
+		}
 		if headerVal != "" {
 			warrantTokenCtx := context.WithValue(r.Context(), warrantTokenCtxKey{}, headerVal)
 			next.ServeHTTP(w, r.WithContext(warrantTokenCtx))
@@ -50,3 +61,30 @@ func ContainsLatest(ctx context.Context) bool {
 func WithLatest(parent context.Context) context.Context {
 	return context.WithValue(parent, warrantTokenCtxKey{}, Latest)
 }
+
+// Returns the (non-'latest') token contained in ctx, if present.
+func GetTokenFromContext(ctx context.Context) (*Token, bool) {
+	val, ok := ctx.Value(warrantTokenCtxKey{}).(string)
+	if !ok || val == Latest {
+		return nil, false
+	}
+
+	token, err := FromString(val)
+	if err != nil {
+		return nil, false
+	}
+
+	return token, true
+}
+
+// Return a context with Warrant-Token set to the given token.
+func WithToken(parent context.Context, token Token) context.Context {
+	return context.WithValue(parent, warrantTokenCtxKey{}, token.String())
+}
+
+// Set the given token (if non-nil) as the Warrant-Token header on the response.
+func AddAsResponseHeader(w http.ResponseWriter, token *Token) {
+	if token != nil {
+		w.Header().Set(HeaderName, token.String())
+	}
+}
@@ -17,6 +17,7 @@ package wookie_test
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/warrant-dev/warrant/pkg/wookie"
 )
@@ -33,3 +34,30 @@ func TestBasicSerialization(t *testing.T) {
 		t.Fatalf("expected ctx to not contain 'latest' wookie")
 	}
 }
+
+func TestTokenInContext(t *testing.T) {
+	t.Parallel()
+	token := wookie.Token{
+		ID:        42,
+		Version:   wookie.TokenVersion,
+		Timestamp: time.UnixMicro(1700000000000000),
+	}
+
+	ctx := wookie.WithToken(context.Background(), token)
+	if wookie.ContainsLatest(ctx) {
+		t.Fatalf("expected ctx to not contain 'latest' wookie")
+	}
+
+	ctxToken, ok := wookie.GetTokenFromContext(ctx)
+	if !ok {
+		t.Fatalf("expected ctx to contain token")
+	}
+	if ctxToken.ID != token.ID || ctxToken.Version != token.Version || !ctxToken.Timestamp.Equal(token.Timestamp) {
+		t.Fatalf("expected token to be %v, but it was %v", token, *ctxToken)
+	}
+
+	_, ok = wookie.GetTokenFromContext(wookie.WithLatest(context.Background()))
+	if ok {
+		t.Fatalf("expected 'latest' ctx to not contain token")
+	}
+}